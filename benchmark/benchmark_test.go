@@ -0,0 +1,140 @@
+// © 2019-present nextmv.io inc
+
+package benchmark_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nextmv-io/nextroute/benchmark"
+	"github.com/nextmv-io/nextroute/factory"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+const solomonInstance = `C101
+
+VEHICLE
+NUMBER     CAPACITY
+  2         200
+
+CUSTOMER
+CUST NO.  XCOORD.   YCOORD.    DEMAND   READY TIME  DUE DATE   SERVICE   TIME
+
+    0      40         50          0          0       1236          0
+    1      45         68         10        912        967         90
+    2      45         70         30        825        870         90
+    3      42         66         10         65        146         90
+`
+
+const cvrplibInstance = `NAME : A-n4-k2
+COMMENT : (Test, No of trucks: 2, Optimal value: 0)
+TYPE : CVRP
+DIMENSION : 4
+EDGE_WEIGHT_TYPE : EUC_2D
+CAPACITY : 100
+NODE_COORD_SECTION
+ 1 0 0
+ 2 3 4
+ 3 6 8
+ 4 0 1
+DEMAND_SECTION
+1 0
+2 10
+3 20
+4 30
+DEPOT_SECTION
+ 1
+ -1
+EOF
+`
+
+const liLimInstance = `2	200	1
+0	40	50	0	0	1236	0	0	0
+1	45	68	-10	912	967	90	2	0
+2	45	70	10	825	870	90	0	1
+`
+
+func route(ids ...string) []schema.PlannedStopOutput {
+	stops := make([]schema.PlannedStopOutput, len(ids))
+	for i, id := range ids {
+		stops[i] = schema.PlannedStopOutput{Stop: schema.StopOutput{ID: id}}
+	}
+	return stops
+}
+
+func TestSolomon(t *testing.T) {
+	instance, err := benchmark.ParseSolomon(strings.NewReader(solomonInstance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instance.Name != "C101" || len(instance.Input.Stops) != 3 || len(instance.Input.Vehicles) != 2 {
+		t.Fatalf("unexpected instance %v with %v stops and %v vehicles",
+			instance.Name, len(instance.Input.Stops), len(instance.Input.Vehicles))
+	}
+	if _, err := factory.NewModel(instance.Input, factory.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	err = instance.WriteSolution(&b, schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{ID: "v1", Route: route("v1-start", "3", "2", "1", "v1-end")},
+			{ID: "v2", Route: route("v2-start", "v2-end")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "Instance name : C101\n") ||
+		!strings.HasSuffix(b.String(), "Solution\nRoute 1 : 3 2 1\n") {
+		t.Errorf("unexpected solution file:\n%s", b.String())
+	}
+}
+
+func TestCVRPLIB(t *testing.T) {
+	instance, err := benchmark.ParseCVRPLIB(strings.NewReader(cvrplibInstance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instance.Input.Stops) != 3 || len(instance.Input.Vehicles) != 2 {
+		t.Fatalf("expected 3 stops and 2 vehicles, got %v and %v",
+			len(instance.Input.Stops), len(instance.Input.Vehicles))
+	}
+	if _, err := factory.NewModel(instance.Input, factory.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	err = instance.WriteSolution(&b, schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{ID: "v1", Route: route("v1-start", "1", "2", "v1-end")},
+			{ID: "v2", Route: route("v2-start", "3", "v2-end")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 5 + 5 + 10 for the first route and 1 + 1 for the second.
+	want := "Route #1: 1 2\nRoute #2: 3\nCost 22\n"
+	if b.String() != want {
+		t.Errorf("expected solution file:\n%s\ngot:\n%s", want, b.String())
+	}
+}
+
+func TestLiLim(t *testing.T) {
+	instance, err := benchmark.ParseLiLim(strings.NewReader(liLimInstance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instance.Input.Stops) != 2 || instance.Input.Stops[1].Precedes != "1" {
+		t.Fatalf("expected pickup 2 to precede delivery 1, got %v", instance.Input.Stops)
+	}
+	if _, err := factory.NewModel(instance.Input, factory.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = benchmark.ParseLiLim(strings.NewReader("2 200 1\n0 1 2 3\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error on line 2, got %v", err)
+	}
+}
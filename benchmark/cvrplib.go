@@ -0,0 +1,141 @@
+// © 2019-present nextmv.io inc
+
+package benchmark
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// vehiclesInName matches the number of vehicles in the name of a CVRPLIB
+// instance, for example `A-n32-k5`, or in its comment, for example
+// `No of trucks: 5`.
+var vehiclesInName = regexp.MustCompile(`(?i)(?:-k|trucks:\s*)(\d+)`)
+
+// ReadCVRPLIB reads a CVRPLIB CVRP instance from a file, see
+// [ParseCVRPLIB].
+func ReadCVRPLIB(path string) (Instance, error) {
+	return readFile(path, ParseCVRPLIB)
+}
+
+// ParseCVRPLIB parses a CVRPLIB CVRP instance in the TSPLIB format with
+// EDGE_WEIGHT_TYPE EUC_2D. The specification gives the NAME, CAPACITY and
+// optionally VEHICLES, followed by the NODE_COORD_SECTION, DEMAND_SECTION and
+// DEPOT_SECTION. The number of vehicles is taken from VEHICLES, the `-k`
+// suffix of the name or the number of trucks in the comment, in that order,
+// and is the number of customers if none is given.
+//
+// Distances and durations are the Euclidean distances between nodes rounded
+// to the nearest integer. The customers are numbered consecutively from 1 in
+// the order of the nodes without the depot, as in CVRPLIB solutions.
+func ParseCVRPLIB(r io.Reader) (Instance, error) {
+	p := newParser(r)
+	specification := map[string]string{}
+	section := ""
+	coordinates := map[int][2]float64{}
+	demands := map[int]float64{}
+	order := make([]int, 0)
+	depot := -1
+	for p.next() {
+		upper := strings.ToUpper(p.text)
+		if upper == "EOF" {
+			break
+		}
+		if strings.HasSuffix(upper, "_SECTION") {
+			section = upper
+			continue
+		}
+		if key, value, ok := strings.Cut(p.text, ":"); ok && section == "" {
+			specification[strings.ToUpper(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			continue
+		}
+
+		numbers, ok := p.numbers()
+		if !ok {
+			return Instance{}, p.errorf("expected numbers in %s, got `%s`", section, p.text)
+		}
+		switch section {
+		case "NODE_COORD_SECTION":
+			if len(numbers) != 3 {
+				return Instance{}, p.errorf("expected node, x and y, got `%s`", p.text)
+			}
+			index := int(numbers[0])
+			coordinates[index] = [2]float64{numbers[1], numbers[2]}
+			order = append(order, index)
+		case "DEMAND_SECTION":
+			if len(numbers) != 2 {
+				return Instance{}, p.errorf("expected node and demand, got `%s`", p.text)
+			}
+			demands[int(numbers[0])] = numbers[1]
+		case "DEPOT_SECTION":
+			if numbers[0] >= 0 && depot < 0 {
+				depot = int(numbers[0])
+			}
+		default:
+			return Instance{}, p.errorf("unsupported section %s", section)
+		}
+	}
+	if err := p.err(); err != nil {
+		return Instance{}, err
+	}
+
+	if edgeWeightType := specification["EDGE_WEIGHT_TYPE"]; edgeWeightType != "EUC_2D" {
+		return Instance{}, p.errorf("unsupported EDGE_WEIGHT_TYPE `%s`, only EUC_2D is supported", edgeWeightType)
+	}
+	capacity, err := strconv.ParseFloat(specification["CAPACITY"], 64)
+	if err != nil {
+		return Instance{}, p.errorf("invalid CAPACITY `%s`", specification["CAPACITY"])
+	}
+	if depot < 0 {
+		if len(order) == 0 {
+			return Instance{}, p.errorf("no nodes defined")
+		}
+		depot = order[0]
+	}
+	if _, ok := coordinates[depot]; !ok {
+		return Instance{}, p.errorf("depot %d has no coordinates", depot)
+	}
+
+	customers := make([]node, 0, len(order))
+	for _, index := range order {
+		if index == depot {
+			continue
+		}
+		customers = append(customers, node{
+			id:     strconv.Itoa(len(customers) + 1),
+			x:      coordinates[index][0],
+			y:      coordinates[index][1],
+			demand: demands[index],
+		})
+	}
+	if len(customers) == 0 {
+		return Instance{}, p.errorf("no customers defined")
+	}
+
+	vehicles := len(customers)
+	if value, ok := specification["VEHICLES"]; ok {
+		if v, err := strconv.Atoi(value); err == nil && v > 0 {
+			vehicles = v
+		}
+	} else if match := vehiclesInName.FindStringSubmatch(
+		specification["NAME"] + " " + specification["COMMENT"],
+	); match != nil {
+		if v, err := strconv.Atoi(match[1]); err == nil && v > 0 {
+			vehicles = v
+		}
+	}
+
+	return newInstance(
+		specification["NAME"],
+		CVRPLIB,
+		node{id: "depot", x: coordinates[depot][0], y: coordinates[depot][1]},
+		customers,
+		vehicles,
+		capacity,
+		1,
+		nint,
+		false,
+	), nil
+}
@@ -0,0 +1,22 @@
+// © 2019-present nextmv.io inc
+
+/*
+Package benchmark reads standard vehicle routing benchmark instances and
+writes solutions in the standard solution file formats, to compare nextroute
+against the literature and other engines and to use the official checkers.
+
+The following instance formats are supported:
+  - Solomon and Gehring-Homberger VRPTW instances, see [ParseSolomon].
+  - CVRPLIB (TSPLIB style) CVRP instances, see [ParseCVRPLIB].
+  - Li & Lim PDPTW instances, see [ParseLiLim].
+
+Each instance is converted to a [schema.Input] with Euclidean distance and
+duration matrices. The stops are identified by their customer number in the
+instance. Times in the instance are interpreted as minutes after [Epoch]
+and durations, including the duration matrix, are in seconds accordingly.
+Locations are planar locations with the coordinates of the instance, travel
+is based on the matrices.
+
+A solution of an instance is written with [Instance.WriteSolution].
+*/
+package benchmark
@@ -0,0 +1,246 @@
+// © 2019-present nextmv.io inc
+
+package benchmark
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// Epoch is the time the times of an instance are relative to, one unit of
+// time in an instance is one minute. Minutes are used because nextroute
+// requires time windows to start and end on a minute boundary.
+var Epoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Format is the format of a benchmark instance.
+type Format string
+
+const (
+	// Solomon is the format of Solomon and Gehring-Homberger VRPTW instances.
+	Solomon Format = "solomon"
+	// CVRPLIB is the TSPLIB style format of CVRPLIB CVRP instances.
+	CVRPLIB Format = "cvrplib"
+	// LiLim is the format of Li & Lim PDPTW instances.
+	LiLim Format = "lilim"
+)
+
+// Instance is a benchmark instance converted to an input.
+type Instance struct {
+	// Name is the name of the instance.
+	Name string
+	// Format is the format the instance was read from.
+	Format Format
+	// Input is the input of the instance.
+	Input schema.Input
+
+	nodes    map[string]node
+	depot    node
+	distance func(from, to node) float64
+}
+
+// node is the depot or a customer of an instance.
+type node struct {
+	id      string
+	x       float64
+	y       float64
+	demand  float64
+	ready   float64
+	due     float64
+	service float64
+	// delivery is the id of the delivery of a pickup of a pickup and
+	// delivery pair.
+	delivery string
+}
+
+// euclidean is the Euclidean distance between two nodes.
+func euclidean(from, to node) float64 {
+	return math.Hypot(from.x-to.x, from.y-to.y)
+}
+
+// nint is the Euclidean distance rounded to the nearest integer as defined
+// by EUC_2D in TSPLIB.
+func nint(from, to node) float64 {
+	return math.Floor(euclidean(from, to) + 0.5)
+}
+
+// newInstance creates an instance with a vehicle per count starting and
+// ending at the depot and a stop per customer. The matrices hold the distance
+// between the nodes, the durations are the distance divided by the speed in
+// units of time.
+func newInstance(
+	name string,
+	format Format,
+	depot node,
+	customers []node,
+	vehicles int,
+	capacity float64,
+	speed float64,
+	distance func(from, to node) float64,
+	timeWindows bool,
+) Instance {
+	instance := Instance{
+		Name:     name,
+		Format:   format,
+		nodes:    make(map[string]node, len(customers)),
+		depot:    depot,
+		distance: distance,
+	}
+
+	input := schema.Input{
+		Stops:    make([]schema.Stop, len(customers)),
+		Vehicles: make([]schema.Vehicle, vehicles),
+	}
+	for i, customer := range customers {
+		instance.nodes[customer.id] = customer
+		quantity := -customer.demand
+		duration := int(math.Round(units(customer.service).Seconds()))
+		stop := schema.Stop{
+			ID:       customer.id,
+			Location: customer.location(),
+			Quantity: quantity,
+			Duration: &duration,
+		}
+		if timeWindows {
+			stop.StartTimeWindow = []any{
+				Epoch.Add(units(customer.ready)).Format(time.RFC3339),
+				Epoch.Add(units(customer.due)).Format(time.RFC3339),
+			}
+		}
+		if customer.delivery != "" {
+			stop.Precedes = customer.delivery
+		}
+		input.Stops[i] = stop
+	}
+
+	depotLocation := depot.location()
+	for i := range input.Vehicles {
+		vehicle := schema.Vehicle{
+			ID:            fmt.Sprintf("v%d", i+1),
+			StartLocation: &depotLocation,
+			EndLocation:   &depotLocation,
+			Capacity:      capacity,
+		}
+		if timeWindows {
+			start := Epoch.Add(units(depot.ready))
+			end := Epoch.Add(units(depot.due))
+			vehicle.StartTime = &start
+			vehicle.EndTime = &end
+		}
+		input.Vehicles[i] = vehicle
+	}
+
+	// The matrices are ordered by stops followed by the start and end of each
+	// vehicle.
+	matrixNodes := make([]node, 0, len(customers)+2*vehicles)
+	matrixNodes = append(matrixNodes, customers...)
+	for i := 0; i < vehicles; i++ {
+		matrixNodes = append(matrixNodes, depot, depot)
+	}
+	distanceMatrix := make([][]float64, len(matrixNodes))
+	durationMatrix := make([][]float64, len(matrixNodes))
+	for i, from := range matrixNodes {
+		distanceMatrix[i] = make([]float64, len(matrixNodes))
+		durationMatrix[i] = make([]float64, len(matrixNodes))
+		for j, to := range matrixNodes {
+			distanceMatrix[i][j] = distance(from, to)
+			durationMatrix[i][j] = units(distanceMatrix[i][j] / speed).Seconds()
+		}
+	}
+	input.DistanceMatrix = &distanceMatrix
+	input.DurationMatrix = durationMatrix
+
+	instance.Input = input
+	return instance
+}
+
+// units returns the duration of units of time of an instance.
+func units(units float64) time.Duration {
+	return time.Duration(units * float64(time.Minute))
+}
+
+// location returns the coordinates of the node as a planar location.
+func (n node) location() schema.Location {
+	x, y := n.x, n.y
+	return schema.Location{X: &x, Y: &y}
+}
+
+// WriteSolution writes the routes of the solution in the solution file format
+// of the instance format. Vehicles without customers are not written, the
+// routes are numbered consecutively.
+//
+// For CVRPLIB each route is written as `Route #1: 2 5 3` followed by the
+// [Instance.Cost] as `Cost 784`. For Solomon and Li & Lim the SINTEF format
+// is used, a header with the instance name followed by `Solution` and each
+// route written as `Route 1 : 2 5 3`.
+func (instance Instance) WriteSolution(w io.Writer, solution schema.SolutionOutput) error {
+	var b strings.Builder
+	routes := instance.routes(solution)
+	switch instance.Format {
+	case CVRPLIB:
+		for i, route := range routes {
+			fmt.Fprintf(&b, "Route #%d: %s\n", i+1, routeIDs(route))
+		}
+		fmt.Fprintf(&b, "Cost %v\n", instance.Cost(solution))
+	case Solomon, LiLim:
+		fmt.Fprintf(&b, "Instance name : %s\n", instance.Name)
+		fmt.Fprintf(&b, "Authors       : nextroute\n")
+		fmt.Fprintf(&b, "Date          : %s\n", time.Now().Format("02-01-2006"))
+		fmt.Fprintf(&b, "Reference     : -\n")
+		fmt.Fprintf(&b, "Solution\n")
+		for i, route := range routes {
+			fmt.Fprintf(&b, "Route %d : %s\n", i+1, routeIDs(route))
+		}
+	default:
+		return fmt.Errorf("unknown benchmark format `%v`", instance.Format)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Cost returns the total distance of the routes of the solution, from the
+// depot along the customers back to the depot, computed from the coordinates
+// of the instance.
+func (instance Instance) Cost(solution schema.SolutionOutput) float64 {
+	cost := 0.0
+	for _, route := range instance.routes(solution) {
+		previous := instance.depot
+		for _, customer := range route {
+			cost += instance.distance(previous, customer)
+			previous = customer
+		}
+		cost += instance.distance(previous, instance.depot)
+	}
+	return cost
+}
+
+// routes returns the customers of each vehicle of the solution which visits
+// at least one customer.
+func (instance Instance) routes(solution schema.SolutionOutput) [][]node {
+	routes := make([][]node, 0, len(solution.Vehicles))
+	for _, vehicle := range solution.Vehicles {
+		route := make([]node, 0, len(vehicle.Route))
+		for _, stop := range vehicle.Route {
+			if customer, ok := instance.nodes[stop.Stop.ID]; ok {
+				route = append(route, customer)
+			}
+		}
+		if len(route) > 0 {
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+func routeIDs(route []node) string {
+	ids := make([]string, len(route))
+	for i, customer := range route {
+		ids[i] = customer.id
+	}
+	return strings.Join(ids, " ")
+}
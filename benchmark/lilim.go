@@ -0,0 +1,84 @@
+// © 2019-present nextmv.io inc
+
+package benchmark
+
+import (
+	"io"
+)
+
+// ReadLiLim reads a Li & Lim PDPTW instance from a file, see [ParseLiLim].
+// The name of the instance is the name of the file.
+func ReadLiLim(path string) (Instance, error) {
+	return readFile(path, ParseLiLim)
+}
+
+// ParseLiLim parses a Li & Lim PDPTW instance. The first row gives the
+// number of vehicles, their capacity and speed. It is followed by a row per
+// task with the columns task number, x and y coordinates, demand, earliest
+// time, latest time, service time, pickup task and delivery task. The first
+// task is the depot. A pickup has a positive demand and gives its delivery
+// task, a delivery has a negative demand and gives its pickup task.
+//
+// Distances are the Euclidean distances between tasks, durations are the
+// distances divided by the speed. Each pickup precedes its delivery.
+func ParseLiLim(r io.Reader) (Instance, error) {
+	p := newParser(r)
+	if !p.next() {
+		if err := p.err(); err != nil {
+			return Instance{}, err
+		}
+		return Instance{}, p.errorf("empty instance")
+	}
+	header, ok := p.numbers()
+	if !ok || len(header) < 2 {
+		return Instance{}, p.errorf("expected number of vehicles, capacity and speed, got `%s`", p.text)
+	}
+	vehicles := int(header[0])
+	capacity := header[1]
+	speed := 1.0
+	if len(header) > 2 && header[2] > 0 {
+		speed = header[2]
+	}
+
+	nodes := make([]node, 0)
+	for p.next() {
+		numbers, ok := p.numbers()
+		if !ok || len(numbers) != 9 {
+			return Instance{}, p.errorf("expected 9 numeric columns for task, got `%s`", p.text)
+		}
+		n := node{
+			id:      id(numbers[0]),
+			x:       numbers[1],
+			y:       numbers[2],
+			demand:  numbers[3],
+			ready:   numbers[4],
+			due:     numbers[5],
+			service: numbers[6],
+		}
+		if numbers[7] == 0 && numbers[8] != 0 {
+			n.delivery = id(numbers[8])
+		}
+		nodes = append(nodes, n)
+	}
+	if err := p.err(); err != nil {
+		return Instance{}, err
+	}
+	if vehicles <= 0 {
+		return Instance{}, p.errorf("no vehicles defined")
+	}
+	if len(nodes) < 2 {
+		return Instance{}, p.errorf("expected depot and at least one task, got %d rows", len(nodes))
+	}
+
+	return newInstance(
+		"",
+		LiLim,
+		nodes[0],
+		nodes[1:],
+		vehicles,
+		capacity,
+		speed,
+		euclidean,
+		true,
+	), nil
+}
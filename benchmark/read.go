@@ -0,0 +1,99 @@
+// © 2019-present nextmv.io inc
+
+package benchmark
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+)
+
+// parser reads the lines of an instance and reports errors with the line
+// number.
+type parser struct {
+	scanner *bufio.Scanner
+	line    int
+	text    string
+}
+
+func newParser(r io.Reader) *parser {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &parser{scanner: scanner}
+}
+
+// next advances to the next non-empty line, returns false at the end.
+func (p *parser) next() bool {
+	for p.scanner.Scan() {
+		p.line++
+		p.text = strings.TrimSpace(p.scanner.Text())
+		if p.text != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) err() error {
+	if err := p.scanner.Err(); err != nil {
+		return nmerror.NewInputDataError(err)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return nmerror.NewInputDataError(
+		fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...)),
+	)
+}
+
+// numbers parses the fields of the line as numbers, returns false if a field
+// is not a number.
+func (p *parser) numbers() ([]float64, bool) {
+	fields := strings.Fields(p.text)
+	numbers := make([]float64, len(fields))
+	for i, field := range fields {
+		number, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, false
+		}
+		numbers[i] = number
+	}
+	return numbers, true
+}
+
+// id formats the number of a node as id.
+func id(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// instanceName returns the name of the instance file without extension.
+func instanceName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+func readFile(
+	path string,
+	parse func(io.Reader) (Instance, error),
+) (Instance, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Instance{}, err
+	}
+	defer file.Close()
+
+	instance, err := parse(file)
+	if err != nil {
+		return Instance{}, err
+	}
+	if instance.Name == "" {
+		instance.Name = instanceName(path)
+	}
+	return instance, nil
+}
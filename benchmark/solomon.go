@@ -0,0 +1,91 @@
+// © 2019-present nextmv.io inc
+
+package benchmark
+
+import (
+	"io"
+	"strings"
+)
+
+// ReadSolomon reads a Solomon or Gehring-Homberger VRPTW instance from a
+// file, see [ParseSolomon].
+func ReadSolomon(path string) (Instance, error) {
+	return readFile(path, ParseSolomon)
+}
+
+// ParseSolomon parses a Solomon or Gehring-Homberger VRPTW instance. The
+// instance starts with its name, followed by the `VEHICLE` section giving the
+// number of vehicles and their capacity and the `CUSTOMER` section giving a
+// row per customer with the columns customer number, x and y coordinates,
+// demand, ready time, due date and service time. The first customer is the
+// depot.
+//
+// Distances and durations are the Euclidean distances between customers.
+func ParseSolomon(r io.Reader) (Instance, error) {
+	p := newParser(r)
+	name := ""
+	vehicles := 0
+	capacity := 0.0
+	section := ""
+	nodes := make([]node, 0)
+	for p.next() {
+		if name == "" {
+			name = p.text
+			continue
+		}
+		upper := strings.ToUpper(p.text)
+		if upper == "VEHICLE" || upper == "CUSTOMER" {
+			section = upper
+			continue
+		}
+		numbers, ok := p.numbers()
+		if !ok {
+			// Column headers.
+			continue
+		}
+		switch section {
+		case "VEHICLE":
+			if len(numbers) != 2 {
+				return Instance{}, p.errorf("expected number of vehicles and capacity, got `%s`", p.text)
+			}
+			vehicles = int(numbers[0])
+			capacity = numbers[1]
+		case "CUSTOMER":
+			if len(numbers) != 7 {
+				return Instance{}, p.errorf("expected 7 columns for customer, got `%s`", p.text)
+			}
+			nodes = append(nodes, node{
+				id:      id(numbers[0]),
+				x:       numbers[1],
+				y:       numbers[2],
+				demand:  numbers[3],
+				ready:   numbers[4],
+				due:     numbers[5],
+				service: numbers[6],
+			})
+		default:
+			return Instance{}, p.errorf("unexpected line `%s` before VEHICLE section", p.text)
+		}
+	}
+	if err := p.err(); err != nil {
+		return Instance{}, err
+	}
+	if vehicles <= 0 {
+		return Instance{}, p.errorf("no vehicles defined")
+	}
+	if len(nodes) < 2 {
+		return Instance{}, p.errorf("expected depot and at least one customer, got %d rows", len(nodes))
+	}
+
+	return newInstance(
+		name,
+		Solomon,
+		nodes[0],
+		nodes[1:],
+		vehicles,
+		capacity,
+		1,
+		euclidean,
+		true,
+	), nil
+}
@@ -4,6 +4,7 @@ package check
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/nextmv-io/nextroute"
 	"github.com/nextmv-io/nextroute/factory"
@@ -12,7 +13,9 @@ import (
 
 // Format formats a solution in a basic format using factory.ToSolutionOutput
 // to format each solution and also allows to check the solutions and add the
-// check to the output of each solution.
+// check to the output of each solution. Returns an error if the format
+// type is unknown, the unplanned stops of a solution can not be explained or
+// the solution can not be formatted as GeoJSON.
 func Format(
 	ctx context.Context,
 	options any,
@@ -20,12 +23,33 @@ func Format(
 	progressioner nextroute.Progressioner,
 	solutions ...nextroute.Solution,
 ) (runSchema.Output, error) {
-	return nextroute.Format(
+	if err := nextroute.ToFormatOptions(options).Validate(); err != nil {
+		return runSchema.Output{}, err
+	}
+
+	var formatErr error
+	output := nextroute.Format(
 		ctx,
 		options,
 		progressioner,
 		func(solution nextroute.Solution) any {
 			solutionOutput := factory.ToSolutionOutput(solution)
+			if len(solutions) > 1 {
+				solutionOutput.Pool = factory.ToSolutionPoolOutput(
+					solution,
+					solutions,
+				)
+			}
+			if nextroute.ToFormatOptions(options).Explain.Unplanned && formatErr == nil {
+				explanations, err := nextroute.ExplainUnplanned(ctx, solution)
+				if err != nil {
+					formatErr = fmt.Errorf("explaining unplanned stops: %w", err)
+				} else {
+					solutionOutput.UnplannedExplanations = factory.ToUnplannedExplanationOutputs(
+						explanations,
+					)
+				}
+			}
 			if checkOptions.Duration > 0 &&
 				ToVerbosity(checkOptions.Verbosity) != Off {
 				solutionCheckOutput, err := SolutionCheck(
@@ -36,8 +60,19 @@ func Format(
 					solutionOutput.Check = &solutionCheckOutput
 				}
 			}
+			if nextroute.ToFormatOptions(options).Type == nextroute.FormatTypeGeoJSON {
+				collection, err := factory.ToFeatureCollection(solutionOutput)
+				if err != nil && formatErr == nil {
+					formatErr = err
+				}
+				return collection
+			}
 			return solutionOutput
 		},
 		solutions...,
-	), nil
+	)
+	if formatErr != nil {
+		return runSchema.Output{}, formatErr
+	}
+	return output, nil
 }
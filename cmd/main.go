@@ -4,18 +4,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
 
 	"github.com/nextmv-io/nextroute"
 	"github.com/nextmv-io/nextroute/check"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
 	"github.com/nextmv-io/nextroute/factory"
 	"github.com/nextmv-io/nextroute/schema"
 	"github.com/nextmv-io/sdk/run"
 	runSchema "github.com/nextmv-io/sdk/run/schema"
+	"github.com/nextmv-io/sdk/run/statistics"
 )
 
 func main() {
@@ -24,19 +31,150 @@ func main() {
 		fmt.Println(nextroute.Version())
 		return
 	}
+	// If the first argument is 'diff', compare two solutions and exit.
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if err := diff(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	// Continue with runner based execution.
-	runner := run.CLI(solver)
+	runner := run.CLI(
+		solver,
+		run.IOProduce[run.CLIRunnerConfig, schema.Input, options, runSchema.Output](
+			ioProducer,
+		),
+	)
 	err := runner.Run(context.Background())
 	if err != nil {
+		// List all the errors found validating the input, each with the path
+		// of the invalid value, so that they can be fixed in one go.
+		var validationErrors nmerror.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			b, marshalErr := json.MarshalIndent(validationErrors, "", "  ")
+			if marshalErr == nil {
+				log.Fatalf(
+					"input data error: %v validation errors:\n%s",
+					len(validationErrors),
+					b,
+				)
+			}
+		}
 		log.Fatal(err)
 	}
 }
 
+// diff compares the two solutions, or run outputs, at the paths given as
+// arguments and writes the differences as JSON or text, see
+// factory.DiffSolutions.
+func diff(args []string, writer io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s diff [flags] from.json to.json\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "json", "output format, json or text")
+	threshold := flags.Duration(
+		"arrival-threshold",
+		0,
+		"report stops whose arrival time shifted by more than this duration",
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("diff requires the paths of two solutions")
+	}
+	if *format != "json" && *format != "text" {
+		return fmt.Errorf("unknown diff format `%s`, expected json or text", *format)
+	}
+
+	from, err := factory.ReadSolutionOutput(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := factory.ReadSolutionOutput(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	solutionDiff := factory.DiffSolutions(
+		from,
+		to,
+		factory.DiffOptions{ArrivalThreshold: *threshold},
+	)
+	if *format == "text" {
+		_, err = io.WriteString(writer, solutionDiff.String())
+		return err
+	}
+	b, err := json.MarshalIndent(solutionDiff, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, string(b))
+	return err
+}
+
+// ioProducer reads the input from the CSV files in the directory if the input
+// path is a directory, see factory.ReadCSVInput. Otherwise, the input is read
+// as JSON from the input path or stdin.
+func ioProducer(ctx context.Context, cfg run.CLIRunnerConfig) (run.IOData, error) {
+	info, err := os.Stat(cfg.Runner.Input.Path)
+	if cfg.Runner.Input.Path == "" || err != nil || !info.IsDir() {
+		return run.CliIOProducer(ctx, cfg)
+	}
+
+	input, err := factory.ReadCSVInput(cfg.Runner.Input.Path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var writer io.Writer = os.Stdout
+	if cfg.Runner.Output.Path != "" {
+		w, err := os.Create(cfg.Runner.Output.Path)
+		if err != nil {
+			return nil, err
+		}
+		writer = w
+	}
+	return run.NewIOData(bytes.NewReader(b), nil, writer)
+}
+
 type options struct {
 	Model  factory.Options                `json:"model,omitempty"`
 	Solve  nextroute.ParallelSolveOptions `json:"solve,omitempty"`
 	Format nextroute.FormatOptions        `json:"format,omitempty"`
 	Check  check.Options                  `json:"check,omitempty"`
+	// WarmStart is the previous solution the solve continues from.
+	WarmStart warmStartOptions `json:"warm_start,omitempty"`
+	// Evaluate is the solution to evaluate instead of solving.
+	Evaluate evaluateOptions `json:"evaluate,omitempty"`
+	// SVG is the SVG rendering of the best solution.
+	SVG svgOptions `json:"svg,omitempty"`
+	// Scenarios are the modifications of the input to solve and compare.
+	Scenarios scenariosOptions `json:"scenarios,omitempty"`
+}
+
+type warmStartOptions struct {
+	Path string `json:"path" usage:"path to a solution or run output to warm start the solve from"`
+}
+
+type evaluateOptions struct {
+	Path string `json:"path" usage:"path to a solution or run output to evaluate instead of solving"`
+}
+
+type scenariosOptions struct {
+	Path string `json:"path" usage:"path to a JSON list of scenarios to solve and compare with the input instead of solving the input only"`
+}
+
+type svgOptions struct {
+	Path        string `json:"path" usage:"path to write an SVG rendering of the best solution to, usually next to the output"`
+	TimeWindows bool   `json:"time_windows" usage:"annotate the stops of the SVG rendering with their arrival and time windows"`
 }
 
 func solver(
@@ -44,6 +182,16 @@ func solver(
 	input schema.Input,
 	options options,
 ) (runSchema.Output, error) {
+	if err := options.Format.Validate(); err != nil {
+		return runSchema.Output{}, err
+	}
+	if options.Evaluate.Path != "" {
+		return evaluate(ctx, input, options)
+	}
+	if options.Scenarios.Path != "" {
+		return solveScenarios(ctx, input, options)
+	}
+
 	model, err := factory.NewModel(input, options.Model)
 	if err != nil {
 		return runSchema.Output{}, err
@@ -54,7 +202,26 @@ func solver(
 		return runSchema.Output{}, err
 	}
 
-	solutions, err := solver.Solve(ctx, options.Solve)
+	startSolutions := make(nextroute.Solutions, 0, 1)
+	var warmStartViolations []schema.ViolationOutput
+	if options.WarmStart.Path != "" {
+		warmStart, err := factory.ReadSolutionOutput(options.WarmStart.Path)
+		if err != nil {
+			return runSchema.Output{}, err
+		}
+		solution, violations, err := factory.NewWarmStartSolution(
+			input,
+			model,
+			warmStart,
+		)
+		if err != nil {
+			return runSchema.Output{}, err
+		}
+		warmStartViolations = violations
+		startSolutions = append(startSolutions, solution)
+	}
+
+	solutions, err := solver.Solve(ctx, options.Solve, startSolutions...)
 	if err != nil {
 		return runSchema.Output{}, err
 	}
@@ -64,17 +231,149 @@ func solver(
 		return runSchema.Output{}, err
 	}
 
+	// Report the pool of diverse solutions, ranked best first, if more than
+	// the best solution has been kept.
+	reported := nextroute.Solutions{last}
+	if pool := solver.SolutionPool().Solutions(); len(pool) > 1 {
+		reported = pool
+	}
+
 	output, err := check.Format(
 		ctx,
 		options,
 		options.Check,
 		solver,
-		last,
+		reported...,
+	)
+	if err != nil {
+		return runSchema.Output{}, err
+	}
+	custom := factory.DefaultCustomResultStatistics(last)
+	custom.WarmStartViolations = warmStartViolations
+	output.Statistics.Result.Custom = custom
+
+	if err := writeSVG(last, options.SVG); err != nil {
+		return runSchema.Output{}, err
+	}
+
+	return output, nil
+}
+
+// writeSVG writes the SVG rendering of the solution if a path is given.
+func writeSVG(solution nextroute.Solution, options svgOptions) error {
+	if options.Path == "" {
+		return nil
+	}
+	svg := factory.SolutionToSVG(
+		solution,
+		factory.SVGOptions{TimeWindows: options.TimeWindows},
+	)
+	if err := os.WriteFile(options.Path, []byte(svg), 0o644); err != nil {
+		return fmt.Errorf("writing svg: %w", err)
+	}
+	return nil
+}
+
+// evaluate outputs the solution given by the routes of the solution to
+// evaluate, scored as submitted, listing every constraint each stop and
+// vehicle violates. Stops which can not be part of any solution are dropped
+// before scoring, which the evaluation of the output states.
+func evaluate(
+	ctx context.Context,
+	input schema.Input,
+	options options,
+) (runSchema.Output, error) {
+	// The violations are part of the solution output, which GeoJSON can not
+	// hold.
+	if nextroute.ToFormatOptions(options).Type == nextroute.FormatTypeGeoJSON {
+		return runSchema.Output{}, errors.New(
+			"evaluating routes requires the json format, geojson is not supported",
+		)
+	}
+
+	routes, err := factory.ReadSolutionOutput(options.Evaluate.Path)
+	if err != nil {
+		return runSchema.Output{}, err
+	}
+
+	solution, violations, err := factory.Evaluate(input, options.Model, routes)
+	if err != nil {
+		return runSchema.Output{}, err
+	}
+
+	output, err := check.Format(ctx, options, options.Check, nil, solution)
+	if err != nil {
+		return runSchema.Output{}, err
+	}
+	if solutionOutput, ok := output.Solutions[0].(schema.SolutionOutput); ok {
+		evaluation := factory.ToEvaluationOutput(violations)
+		solutionOutput.Evaluation = &evaluation
+		solutionOutput.Violations = violations
+		output.Solutions[0] = solutionOutput
+	}
+
+	value := statistics.Float64(solution.Score())
+	output.Statistics.Result = &statistics.Result{
+		Value:  &value,
+		Custom: factory.DefaultCustomResultStatistics(solution),
+	}
+
+	return output, nil
+}
+
+// solveScenarios outputs the solution of the input followed by the solution
+// of each scenario, each compared with the solution of the input.
+func solveScenarios(
+	ctx context.Context,
+	input schema.Input,
+	options options,
+) (runSchema.Output, error) {
+	// The comparison is part of the solution output, which GeoJSON can not
+	// hold.
+	if nextroute.ToFormatOptions(options).Type == nextroute.FormatTypeGeoJSON {
+		return runSchema.Output{}, errors.New(
+			"solving scenarios requires the json format, geojson is not supported",
+		)
+	}
+
+	scenarios, err := factory.ReadScenarios(options.Scenarios.Path)
+	if err != nil {
+		return runSchema.Output{}, err
+	}
+
+	scenarioSolutions, err := factory.SolveScenarios(
+		ctx,
+		input,
+		options.Model,
+		options.Solve,
+		scenarios,
 	)
 	if err != nil {
 		return runSchema.Output{}, err
 	}
-	output.Statistics.Result.Custom = factory.DefaultCustomResultStatistics(last)
+
+	solutions := make(nextroute.Solutions, len(scenarioSolutions))
+	for idx, scenarioSolution := range scenarioSolutions {
+		solutions[idx] = scenarioSolution.Solution
+	}
+
+	output, err := check.Format(ctx, options, options.Check, nil, solutions...)
+	if err != nil {
+		return runSchema.Output{}, err
+	}
+	comparison := factory.CompareScenarios(scenarioSolutions)
+	for idx := range output.Solutions {
+		solutionOutput := output.Solutions[idx].(schema.SolutionOutput)
+		solutionOutput.Pool = nil
+		solutionOutput.Scenario = &comparison[idx]
+		output.Solutions[idx] = solutionOutput
+	}
+
+	value := statistics.Float64(solutions[0].Score())
+	output.Statistics.Result = &statistics.Result{
+		Value:  &value,
+		Custom: factory.DefaultCustomResultStatistics(solutions[0]),
+	}
 
 	return output, nil
 }
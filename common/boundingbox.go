@@ -39,7 +39,8 @@ func NewBoundingBox(locations Locations) BoundingBox {
 	maxLongitude := locations[0].Longitude()
 
 	for idx := 1; idx < len(locations); idx++ {
-		if !locations[idx].IsValid() {
+		if !locations[idx].IsValid() ||
+			locations[idx].IsPlanar() != locations[0].IsPlanar() {
 			return NewInvalidBoundingBox()
 		}
 		latitude := locations[idx].Latitude()
@@ -57,8 +58,8 @@ func NewBoundingBox(locations Locations) BoundingBox {
 			maxLongitude = longitude
 		}
 	}
-	maxLocation, _ := NewLocation(maxLongitude, maxLatitude)
-	minLocation, _ := NewLocation(minLongitude, minLatitude)
+	maxLocation, _ := locations[0].WithCoordinates(maxLongitude, maxLatitude)
+	minLocation, _ := locations[0].WithCoordinates(minLongitude, minLatitude)
 	return boundingBox{
 		maximum: maxLocation,
 		minimum: minLocation,
@@ -74,9 +75,12 @@ func (b boundingBox) Width() Distance {
 	if !b.IsValid() {
 		return NewDistance(0.0, Meters)
 	}
-	leftUpper, _ := NewLocation(b.minimum.Longitude(), b.minimum.Latitude())
-	rightUpper, _ := NewLocation(b.maximum.Longitude(), b.minimum.Latitude())
-	width, _ := Haversine(leftUpper, rightUpper)
+	leftUpper := b.minimum
+	rightUpper, _ := b.minimum.WithCoordinates(
+		b.maximum.Longitude(),
+		b.minimum.Latitude(),
+	)
+	width, _ := StraightLine(leftUpper, rightUpper)
 	return width
 }
 
@@ -84,9 +88,12 @@ func (b boundingBox) Height() Distance {
 	if !b.IsValid() {
 		return NewDistance(0.0, Meters)
 	}
-	leftUpper, _ := NewLocation(b.minimum.Longitude(), b.minimum.Latitude())
-	leftLower, _ := NewLocation(b.minimum.Longitude(), b.maximum.Latitude())
-	height, _ := Haversine(leftUpper, leftLower)
+	leftUpper := b.minimum
+	leftLower, _ := b.minimum.WithCoordinates(
+		b.minimum.Longitude(),
+		b.maximum.Latitude(),
+	)
+	height, _ := StraightLine(leftUpper, leftLower)
 	return height
 }
 
@@ -5,6 +5,7 @@ package errors
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Error is the base interface for all errors returned by nextroute functions.
@@ -22,6 +23,11 @@ func (e InputDataError) Error() string {
 	return e.error.Error()
 }
 
+// Unwrap returns the wrapped error.
+func (e InputDataError) Unwrap() error {
+	return e.error
+}
+
 // NewInputDataError creates a new InputDataError.
 func NewInputDataError(err error) Error {
 	return InputDataError{
@@ -62,3 +68,37 @@ func NewArgumentMismatchError(err error) Error {
 		error: fmt.Errorf("input data error: %w", err),
 	}
 }
+
+// ValidationError describes a single invalid value of the input.
+type ValidationError struct {
+	// Path is the JSON path of the invalid value in the input, for example
+	// `stops[123].start_time_window[0]`.
+	Path string `json:"path"`
+	// Code identifies the kind of error, for example `negative_value`.
+	Code string `json:"code"`
+	// Message describes the error.
+	Message string `json:"message"`
+}
+
+// Error returns the error message prefixed with the path.
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is a list of all the errors found validating the input.
+type ValidationErrors []ValidationError
+
+// Error returns the messages of all the errors.
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%v validation errors: %s", len(e), strings.Join(messages, "; "))
+}
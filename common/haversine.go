@@ -9,7 +9,8 @@ import (
 
 // Haversine calculates the distance between two locations using the
 // Haversine formula. Haversine is a good approximation for short
-// distances (up to a few hundred kilometers).
+// distances (up to a few hundred kilometers). An error is returned if a
+// location is invalid or planar.
 func Haversine(from, to Location) (Distance, error) {
 	if !from.IsValid() || !to.IsValid() {
 		return Distance{},
@@ -24,6 +25,15 @@ func Haversine(from, to Location) (Distance, error) {
 				to.IsValid(),
 			)
 	}
+	if from.IsPlanar() || to.IsPlanar() {
+		return Distance{},
+			fmt.Errorf(
+				"from %v or to %v is planar, haversine requires geographical"+
+					" locations",
+				from,
+				to,
+			)
+	}
 
 	x1 := degreesToRadian(from.Longitude())
 	y1 := degreesToRadian(from.Latitude())
@@ -25,6 +25,28 @@ func NewLocation(longitude float64, latitude float64) (Location, error) {
 	}, nil
 }
 
+// NewPlanarLocation creates a new Location on a plane, the coordinates are
+// in meters. An error is returned if a coordinate is not a finite number.
+// Planar locations are used for non-geographical coordinates, for example
+// the positions in a warehouse. The longitude of a planar location is the x
+// coordinate and the latitude is the y coordinate.
+func NewPlanarLocation(x float64, y float64) (Location, error) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return NewInvalidLocation(),
+			fmt.Errorf("x %f must be a finite number", x)
+	}
+	if math.IsNaN(y) || math.IsInf(y, 0) {
+		return NewInvalidLocation(),
+			fmt.Errorf("y %f must be a finite number", y)
+	}
+	return Location{
+		longitude: x,
+		latitude:  y,
+		valid:     true,
+		planar:    true,
+	}, nil
+}
+
 // NewInvalidLocation creates a new invalid Location. Longitude and latitude
 // are not important.
 func NewInvalidLocation() Location {
@@ -54,7 +76,8 @@ func (l Locations) Unique() Locations {
 }
 
 // Centroid returns the centroid of the locations. If locations is empty, the
-// centroid will be an invalid location.
+// centroid will be an invalid location. An error is returned if planar and
+// geographical locations are mixed.
 func (l Locations) Centroid() (Location, error) {
 	if len(l) == 0 {
 		return NewInvalidLocation(), nil
@@ -62,28 +85,41 @@ func (l Locations) Centroid() (Location, error) {
 	lat := 0.0
 	lon := 0.0
 	for _, location := range l {
+		if location.valid && l[0].valid && location.planar != l[0].planar {
+			return NewInvalidLocation(),
+				fmt.Errorf("planar and geographical locations can not be mixed")
+		}
 		// invalid locations are encoded as NaN, which will propagate
 		// so we can avoid a check here.
 		lat += location.Latitude()
 		lon += location.Longitude()
 	}
 	n := float64(len(l))
-	loc, err := NewLocation(lon/n, lat/n)
+	loc, err := l[0].WithCoordinates(lon/n, lat/n)
 	if err != nil {
 		return NewInvalidLocation(), err
 	}
 	return loc, nil
 }
 
-// Location represents a location on earth.
+// Location represents a location on earth or, if it is planar, a position on
+// a plane.
 type Location struct {
 	longitude float64
 	latitude  float64
 	valid     bool
+	planar    bool
 }
 
 // String returns a string representation of the location.
 func (l Location) String() string {
+	if l.planar {
+		return fmt.Sprintf(
+			"{x: %v,y: %v}",
+			l.longitude,
+			l.latitude,
+		)
+	}
 	return fmt.Sprintf(
 		"{lat: %v,lon: %v}",
 		l.latitude,
@@ -91,23 +127,59 @@ func (l Location) String() string {
 	)
 }
 
-// Longitude returns the longitude of the location.
+// Longitude returns the longitude of the location, the x coordinate if the
+// location is planar.
 func (l Location) Longitude() float64 {
 	return l.longitude
 }
 
-// Latitude returns the latitude of the location.
+// Latitude returns the latitude of the location, the y coordinate if the
+// location is planar.
 func (l Location) Latitude() float64 {
 	return l.latitude
 }
 
+// X returns the x coordinate of a planar location, the longitude if the
+// location is geographical.
+func (l Location) X() float64 {
+	return l.longitude
+}
+
+// Y returns the y coordinate of a planar location, the latitude if the
+// location is geographical.
+func (l Location) Y() float64 {
+	return l.latitude
+}
+
+// IsPlanar returns true if the location is a position on a plane instead of
+// a location on earth.
+func (l Location) IsPlanar() bool {
+	return l.planar
+}
+
+// WithCoordinates returns a new location in the same coordinate system as the
+// invoking location with the given coordinates, for a planar location the
+// longitude is the x coordinate and the latitude is the y coordinate.
+func (l Location) WithCoordinates(
+	longitude float64,
+	latitude float64,
+) (Location, error) {
+	if l.planar {
+		return NewPlanarLocation(longitude, latitude)
+	}
+	return NewLocation(longitude, latitude)
+}
+
 // Equals returns true if the invoking location is equal to the other location.
 func (l Location) Equals(other Location) bool {
-	return l.longitude == other.Longitude() && l.latitude == other.Latitude()
+	return l.longitude == other.Longitude() &&
+		l.latitude == other.Latitude() &&
+		l.planar == other.IsPlanar()
 }
 
 // IsValid returns true if the location is valid. A location is valid if
-// the bounds of the longitude and latitude are correct.
+// the bounds of the longitude and latitude are correct or if it is a planar
+// location with finite coordinates.
 func (l Location) IsValid() bool {
 	return l.valid
 }
@@ -0,0 +1,66 @@
+// © 2019-present nextmv.io inc
+
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// Euclidean calculates the straight line distance between two planar
+// locations. The coordinates of planar locations are in meters.
+func Euclidean(from, to Location) (Distance, error) {
+	if err := validatePlanar(from, to); err != nil {
+		return Distance{}, err
+	}
+	return NewDistance(
+		math.Hypot(from.X()-to.X(), from.Y()-to.Y()),
+		Meters,
+	), nil
+}
+
+// Manhattan calculates the distance between two planar locations when moving
+// only parallel to the axes, for example along the aisles of a warehouse. The
+// coordinates of planar locations are in meters.
+func Manhattan(from, to Location) (Distance, error) {
+	if err := validatePlanar(from, to); err != nil {
+		return Distance{}, err
+	}
+	return NewDistance(
+		math.Abs(from.X()-to.X())+math.Abs(from.Y()-to.Y()),
+		Meters,
+	), nil
+}
+
+// StraightLine calculates the straight line distance between two locations.
+// For geographical locations this is the [Haversine] distance and for planar
+// locations the [Euclidean] distance. An error is returned if a location is
+// invalid or if one location is planar and the other is not.
+func StraightLine(from, to Location) (Distance, error) {
+	if from.IsPlanar() || to.IsPlanar() {
+		return Euclidean(from, to)
+	}
+	return Haversine(from, to)
+}
+
+func validatePlanar(from, to Location) error {
+	if !from.IsValid() || !to.IsValid() {
+		return fmt.Errorf(
+			"from %v (valid = %t) or to %v (valid = %t) are invalid",
+			from,
+			from.IsValid(),
+			to,
+			to.IsValid(),
+		)
+	}
+	if !from.IsPlanar() || !to.IsPlanar() {
+		return fmt.Errorf(
+			"from %v (planar = %t) or to %v (planar = %t) is not planar",
+			from,
+			from.IsPlanar(),
+			to,
+			to.IsPlanar(),
+		)
+	}
+	return nil
+}
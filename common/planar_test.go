@@ -0,0 +1,65 @@
+// © 2019-present nextmv.io inc
+
+package common_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nextmv-io/nextroute/common"
+)
+
+func TestPlanarDistances(t *testing.T) {
+	from, err := common.NewPlanarLocation(1000, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := common.NewPlanarLocation(1003, 2004)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.IsValid() || !from.IsPlanar() {
+		t.Errorf("expected valid planar location, got %v", from)
+	}
+
+	euclidean, err := common.Euclidean(from, to)
+	if err != nil || euclidean.Value(common.Meters) != 5 {
+		t.Errorf("expected euclidean distance 5, got %v, %v", euclidean.Value(common.Meters), err)
+	}
+	manhattan, err := common.Manhattan(from, to)
+	if err != nil || manhattan.Value(common.Meters) != 7 {
+		t.Errorf("expected manhattan distance 7, got %v, %v", manhattan.Value(common.Meters), err)
+	}
+	straightLine, err := common.StraightLine(from, to)
+	if err != nil || straightLine.Value(common.Meters) != 5 {
+		t.Errorf("expected straight line distance 5, got %v, %v", straightLine.Value(common.Meters), err)
+	}
+
+	geographical, _ := common.NewLocation(7.1, 50.1)
+	if _, err := common.Haversine(from, to); err == nil {
+		t.Error("expected haversine of planar locations to fail")
+	}
+	if _, err := common.StraightLine(from, geographical); err == nil {
+		t.Error("expected straight line between planar and geographical location to fail")
+	}
+	if _, err := common.NewPlanarLocation(math.NaN(), 0); err == nil {
+		t.Error("expected NaN coordinate to fail")
+	}
+	if from.Equals(geographical) {
+		t.Error("expected planar and geographical locations to differ")
+	}
+
+	centroid, err := common.Locations{from, to}.Centroid()
+	if err != nil || !centroid.IsPlanar() || centroid.X() != 1001.5 || centroid.Y() != 2002 {
+		t.Errorf("expected planar centroid {1001.5, 2002}, got %v, %v", centroid, err)
+	}
+	if _, err := (common.Locations{from, geographical}).Centroid(); err == nil {
+		t.Error("expected centroid of mixed locations to fail")
+	}
+
+	box := common.NewBoundingBox(common.Locations{from, to})
+	if !box.IsValid() || box.Width().Value(common.Meters) != 3 || box.Height().Value(common.Meters) != 4 {
+		t.Errorf("expected bounding box of 3 by 4, got %v by %v",
+			box.Width().Value(common.Meters), box.Height().Value(common.Meters))
+	}
+}
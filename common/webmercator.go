@@ -0,0 +1,41 @@
+// © 2019-present nextmv.io inc
+
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// maxMercatorLatitude is the latitude at which the Web Mercator projection
+// becomes a square, latitudes beyond it are clamped.
+const maxMercatorLatitude = 85.05112878
+
+// WebMercator projects a geographical location onto a plane using the
+// spherical Web Mercator projection. The coordinates are in meters, x grows
+// towards the east and y towards the north. Latitudes beyond ±85.05112878
+// degrees are clamped. An error is returned if the location is invalid or
+// planar.
+func WebMercator(location Location) (x, y float64, err error) {
+	if !location.IsValid() {
+		return 0, 0, fmt.Errorf(
+			"location (lon: %f, lat: %f) is invalid",
+			location.Longitude(),
+			location.Latitude(),
+		)
+	}
+	if location.IsPlanar() {
+		return 0, 0, fmt.Errorf(
+			"location %v is planar, web mercator requires a geographical"+
+				" location",
+			location,
+		)
+	}
+	latitude := math.Max(
+		-maxMercatorLatitude,
+		math.Min(maxMercatorLatitude, location.Latitude()),
+	)
+	x = radius * degreesToRadian(location.Longitude())
+	y = radius * math.Log(math.Tan(math.Pi/4+degreesToRadian(latitude)/2))
+	return x, y, nil
+}
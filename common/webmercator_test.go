@@ -0,0 +1,41 @@
+// © 2019-present nextmv.io inc
+
+package common_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nextmv-io/nextroute/common"
+)
+
+func TestWebMercator(t *testing.T) {
+	origin, _ := common.NewLocation(0, 0)
+	x, y, err := common.WebMercator(origin)
+	if err != nil || x != 0 || math.Abs(y) > 1e-9 {
+		t.Errorf("expected origin at (0, 0), got (%v, %v), %v", x, y, err)
+	}
+
+	east, _ := common.NewLocation(180, 0)
+	x, _, err = common.WebMercator(east)
+	if err != nil || math.Abs(x-math.Pi*6371000) > 1e-6 {
+		t.Errorf("expected x of half the circumference, got %v, %v", x, err)
+	}
+
+	// Latitudes beyond the square of the projection are clamped.
+	north, _ := common.NewLocation(0, 85.05112878)
+	pole, _ := common.NewLocation(0, 89.9)
+	_, yNorth, _ := common.WebMercator(north)
+	_, yPole, err := common.WebMercator(pole)
+	if err != nil || yPole != yNorth || math.Abs(yNorth-math.Pi*6371000) > 1 {
+		t.Errorf("expected clamped y %v, got %v, %v", yNorth, yPole, err)
+	}
+
+	planar, _ := common.NewPlanarLocation(1, 2)
+	if _, _, err := common.WebMercator(planar); err == nil {
+		t.Error("expected web mercator of a planar location to fail")
+	}
+	if _, _, err := common.WebMercator(common.NewInvalidLocation()); err == nil {
+		t.Error("expected web mercator of an invalid location to fail")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 
 	"github.com/nextmv-io/nextroute"
-	"github.com/nextmv-io/nextroute/common"
 	nmerror "github.com/nextmv-io/nextroute/common/errors"
 	"github.com/nextmv-io/nextroute/schema"
 )
@@ -47,10 +46,7 @@ func addAlternates(
 					vehicle.ID,
 				))
 			}
-			location, err := common.NewLocation(
-				alternateInputStop.stop.Location.Lon,
-				alternateInputStop.stop.Location.Lat,
-			)
+			location, err := toLocation(alternateInputStop.stop.Location)
 			if err != nil {
 				return nil, err
 			}
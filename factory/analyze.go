@@ -0,0 +1,437 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nextmv-io/nextroute/common"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// Reasons for which the analysis of the input reports a stop as unplannable.
+const (
+	// UnplannableAttributes is reported if no vehicle has one of the
+	// compatibility attributes of the stop.
+	UnplannableAttributes = "attributes"
+	// UnplannableCapacity is reported if the quantity of the stop exceeds the
+	// capacity of every vehicle for a resource.
+	UnplannableCapacity = "capacity"
+	// UnplannableTimeWindow is reported if no vehicle can reach the stop in
+	// one of its start time windows, or before the end of its shift.
+	UnplannableTimeWindow = "time_window"
+	// UnplannableVehicles is reported if each vehicle is excluded for a
+	// different reason.
+	UnplannableVehicles = "vehicles"
+	// UnplannablePrecedenceCycle is reported if the stop is part of a cycle of
+	// precedence relationships.
+	UnplannablePrecedenceCycle = "precedence_cycle"
+	// UnplannableLinked is reported if the stop must be planned together with
+	// an unplannable stop, due to precedence or groups.
+	UnplannableLinked = "linked"
+)
+
+// Analyze analyzes the input before solving and returns the stops which can
+// provably not be planned, without searching for a solution, together with
+// the reason. The analysis only uses lower bounds, a stop which is not
+// reported may still turn out to be unplannable. Constraints disabled in the
+// options are not analyzed.
+func Analyze(
+	input schema.Input,
+	modelOptions Options,
+) ([]schema.UnplannableStopOutput, error) {
+	input = applyDefaults(input)
+	if err := validate(input, modelOptions); err != nil {
+		return nil, err
+	}
+	return analyze(input, modelOptions)
+}
+
+// analyzeInput runs the analysis if enabled in the options. If fail fast is
+// enabled, or the input has precedence cycles on which building the model
+// would fail, an error is returned listing the unplannable stops.
+func analyzeInput(
+	input schema.Input,
+	modelOptions Options,
+) ([]schema.UnplannableStopOutput, error) {
+	if !modelOptions.Validate.Enable.Analysis {
+		return nil, nil
+	}
+
+	unplannable, err := analyze(input, modelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	hasCycle := slices.ContainsFunc(
+		unplannable,
+		func(stop schema.UnplannableStopOutput) bool {
+			return stop.Reason == UnplannablePrecedenceCycle
+		},
+	)
+	if len(unplannable) > 0 && (modelOptions.Validate.Enable.AnalysisFailFast || hasCycle) {
+		return nil, nmerror.NewInputDataError(fmt.Errorf(
+			"analysis found %v unplannable stops: %v",
+			len(unplannable),
+			strings.Join(
+				common.Map(
+					unplannable,
+					func(stop schema.UnplannableStopOutput) string {
+						return fmt.Sprintf("stop `%s` %s", stop.StopID, stop.Message)
+					},
+				),
+				"; ",
+			),
+		))
+	}
+
+	return unplannable, nil
+}
+
+func analyze(
+	input schema.Input,
+	modelOptions Options,
+) ([]schema.UnplannableStopOutput, error) {
+	vehicleCapacities := make([]map[string]float64, len(input.Vehicles))
+	for v, vehicle := range input.Vehicles {
+		capacities, err := resources(vehicle, "Capacity", 1)
+		if err != nil {
+			return nil, err
+		}
+		vehicleCapacities[v] = capacities
+	}
+
+	unplannable := map[string]schema.UnplannableStopOutput{}
+	for _, stop := range input.Stops {
+		reasons := make([]string, 0, len(input.Vehicles))
+		messages := make([]string, 0, len(input.Vehicles))
+		for v, vehicle := range input.Vehicles {
+			reason, message, err := analyzeStopOnVehicle(
+				input,
+				modelOptions,
+				stop,
+				vehicle,
+				vehicleCapacities[v],
+			)
+			if err != nil {
+				return nil, err
+			}
+			if reason == "" {
+				break
+			}
+			reasons = append(reasons, reason)
+			messages = append(messages, fmt.Sprintf("vehicle `%s` %s", vehicle.ID, message))
+		}
+		if len(input.Vehicles) == 0 || len(reasons) < len(input.Vehicles) {
+			continue
+		}
+
+		reason := reasons[0]
+		message := messages[0]
+		if len(slices.Compact(slices.Clone(reasons))) > 1 {
+			reason = UnplannableVehicles
+		}
+		if len(messages) > 1 {
+			message = "can not be planned on any vehicle: " + strings.Join(messages, ", ")
+		}
+		unplannable[stop.ID] = schema.UnplannableStopOutput{
+			StopID:  stop.ID,
+			Reason:  reason,
+			Message: message,
+		}
+	}
+
+	if !modelOptions.Constraints.Disable.Precedence {
+		cycles, err := precedenceCycles(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, cycle := range cycles {
+			for _, id := range cycle {
+				unplannable[id] = schema.UnplannableStopOutput{
+					StopID: id,
+					Reason: UnplannablePrecedenceCycle,
+					Message: fmt.Sprintf(
+						"is part of the precedence cycle [%s]",
+						strings.Join(cycle, ", "),
+					),
+				}
+			}
+		}
+	}
+
+	if err := addLinkedUnplannable(input, modelOptions, unplannable); err != nil {
+		return nil, err
+	}
+
+	stops := make([]schema.UnplannableStopOutput, 0, len(unplannable))
+	for _, stop := range unplannable {
+		stops = append(stops, stop)
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].StopID < stops[j].StopID
+	})
+
+	return stops, nil
+}
+
+// analyzeStopOnVehicle returns the reason and a description why the stop can
+// not be planned on the vehicle, an empty reason if it may be planned.
+func analyzeStopOnVehicle(
+	input schema.Input,
+	modelOptions Options,
+	stop schema.Stop,
+	vehicle schema.Vehicle,
+	capacities map[string]float64,
+) (string, string, error) {
+	if !modelOptions.Constraints.Disable.Attributes &&
+		stop.CompatibilityAttributes != nil &&
+		len(*stop.CompatibilityAttributes) > 0 {
+		compatible := vehicle.CompatibilityAttributes != nil &&
+			slices.ContainsFunc(*stop.CompatibilityAttributes, func(attribute string) bool {
+				return slices.Contains(*vehicle.CompatibilityAttributes, attribute)
+			})
+		if !compatible {
+			return UnplannableAttributes,
+				fmt.Sprintf(
+					"has none of the compatibility attributes [%s]",
+					strings.Join(*stop.CompatibilityAttributes, ", "),
+				),
+				nil
+		}
+	}
+
+	if !modelOptions.Constraints.Disable.Capacity {
+		quantities, err := resources(stop, "Quantity", 1)
+		if err != nil {
+			return "", "", err
+		}
+		names := common.Keys(quantities)
+		slices.Sort(names)
+		for _, name := range names {
+			quantity := quantities[name]
+			if slices.Contains(modelOptions.Constraints.Disable.Capacities, name) {
+				continue
+			}
+			if capacity := capacities[name]; math.Abs(quantity) > capacity {
+				return UnplannableCapacity,
+					fmt.Sprintf(
+						"has capacity %v for resource `%s`, less than the quantity %v",
+						capacity,
+						name,
+						quantity,
+					),
+					nil
+			}
+		}
+	}
+
+	return analyzeTime(input, modelOptions, stop, vehicle)
+}
+
+// analyzeTime returns the time window reason if the vehicle can not start
+// serving the stop in one of its time windows, or only after the end of its
+// shift. The travel duration from the start of the vehicle is a lower bound,
+// it is only used if the travel durations are based on the speed of the
+// vehicle.
+func analyzeTime(
+	input schema.Input,
+	modelOptions Options,
+	stop schema.Stop,
+	vehicle schema.Vehicle,
+) (string, string, error) {
+	if vehicle.StartTime == nil || modelOptions.Constraints.Disable.VehicleStartTime {
+		return "", "", nil
+	}
+
+	arrival := *vehicle.StartTime
+	if input.DurationMatrix == nil &&
+		input.DurationMatrixFile == "" &&
+		input.DistanceMatrix == nil &&
+		input.DistanceMatrixFile == "" &&
+		vehicle.StartLocation != nil &&
+		vehicle.Speed != nil &&
+		*vehicle.Speed > 0 {
+		distance, err := StraightLineDistance(*vehicle.StartLocation, stop.Location)
+		if err != nil {
+			return "", "", err
+		}
+		seconds := distance.Value(common.Meters) / *vehicle.Speed
+		arrival = arrival.Add(time.Duration(seconds * float64(time.Second)))
+	}
+
+	start := arrival
+	if stop.StartTimeWindow != nil && !modelOptions.Constraints.Disable.StartTimeWindows {
+		windows, err := convertTimeWindow(stop.StartTimeWindow, stop.ID)
+		if err != nil {
+			return "", "", err
+		}
+		reachable := false
+		for _, window := range windows {
+			if window[1].Before(arrival) {
+				continue
+			}
+			windowStart := arrival
+			if window[0].After(arrival) {
+				windowStart = window[0]
+			}
+			if !reachable || windowStart.Before(start) {
+				start = windowStart
+			}
+			reachable = true
+		}
+		if !reachable {
+			return UnplannableTimeWindow,
+				fmt.Sprintf(
+					"arrives at %s at the earliest, after the end of all start time windows",
+					arrival.Format(time.RFC3339),
+				),
+				nil
+		}
+	}
+
+	if vehicle.EndTime != nil &&
+		!modelOptions.Constraints.Disable.VehicleEndTime &&
+		start.After(*vehicle.EndTime) {
+		return UnplannableTimeWindow,
+			fmt.Sprintf(
+				"can start at %s at the earliest, after the end of its shift at %s",
+				start.Format(time.RFC3339),
+				vehicle.EndTime.Format(time.RFC3339),
+			),
+			nil
+	}
+
+	return "", "", nil
+}
+
+// precedenceCycles returns the cycles of the precedence relationships between
+// the stops, each cycle as the IDs of the stops in order of the input.
+func precedenceCycles(input schema.Input) ([][]string, error) {
+	successors := map[string][]string{}
+	for _, stop := range input.Stops {
+		sequences, err := getSequences(stop)
+		if err != nil {
+			return nil, err
+		}
+		for _, sequence := range sequences {
+			successors[sequence.predecessor] = append(
+				successors[sequence.predecessor],
+				sequence.successor,
+			)
+		}
+	}
+
+	// Tarjan's algorithm for strongly connected components, a component of
+	// more than one stop is a cycle.
+	index := map[string]int{}
+	lowLink := map[string]int{}
+	onStack := map[string]bool{}
+	stack := make([]string, 0)
+	components := make([][]string, 0)
+	var connect func(id string)
+	connect = func(id string) {
+		index[id] = len(index)
+		lowLink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+		for _, successor := range successors[id] {
+			if _, visited := index[successor]; !visited {
+				connect(successor)
+				lowLink[id] = min(lowLink[id], lowLink[successor])
+			} else if onStack[successor] {
+				lowLink[id] = min(lowLink[id], index[successor])
+			}
+		}
+		if lowLink[id] != index[id] {
+			return
+		}
+		component := make([]string, 0)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		if len(component) > 1 {
+			components = append(components, component)
+		}
+	}
+
+	order := map[string]int{}
+	for idx, stop := range input.Stops {
+		order[stop.ID] = idx
+		if _, visited := index[stop.ID]; !visited {
+			connect(stop.ID)
+		}
+	}
+	for _, component := range components {
+		sort.SliceStable(component, func(i, j int) bool {
+			return order[component[i]] < order[component[j]]
+		})
+	}
+
+	return components, nil
+}
+
+// addLinkedUnplannable adds the stops which must be planned together with an
+// unplannable stop, through precedence relationships or groups, to the
+// unplannable stops.
+func addLinkedUnplannable(
+	input schema.Input,
+	modelOptions Options,
+	unplannable map[string]schema.UnplannableStopOutput,
+) error {
+	links := map[string][]string{}
+	link := func(a, b string) {
+		links[a] = append(links[a], b)
+		links[b] = append(links[b], a)
+	}
+	if !modelOptions.Constraints.Disable.Precedence {
+		for _, stop := range input.Stops {
+			sequences, err := getSequences(stop)
+			if err != nil {
+				return err
+			}
+			for _, sequence := range sequences {
+				link(sequence.predecessor, sequence.successor)
+			}
+		}
+	}
+	if !modelOptions.Constraints.Disable.Groups && input.StopGroups != nil {
+		for _, group := range *input.StopGroups {
+			for i := 1; i < len(group); i++ {
+				link(group[0], group[i])
+			}
+		}
+	}
+
+	queue := common.Keys(unplannable)
+	slices.Sort(queue)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, linked := range links[id] {
+			if _, ok := unplannable[linked]; ok {
+				continue
+			}
+			unplannable[linked] = schema.UnplannableStopOutput{
+				StopID:  linked,
+				Reason:  UnplannableLinked,
+				Message: fmt.Sprintf("must be planned together with unplannable stop `%s`", id),
+			}
+			queue = append(queue, linked)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestAnalyze(t *testing.T) {
+	speed := 10.0
+	start := schema.Location{Lon: 7.6, Lat: 52.0}
+	startTime := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(4 * time.Hour)
+	fridge := []string{"fridge"}
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{
+				ID:       "late",
+				Location: schema.Location{Lon: 7.9, Lat: 52.0},
+				StartTimeWindow: []any{
+					startTime.Format(time.RFC3339),
+					startTime.Add(5 * time.Minute).Format(time.RFC3339),
+				},
+			},
+			{ID: "heavy", Location: schema.Location{Lon: 7.61, Lat: 52.0}, Quantity: -20},
+			{ID: "attr", Location: schema.Location{Lon: 7.62, Lat: 52.0}, CompatibilityAttributes: &fridge},
+			{ID: "pickup", Location: schema.Location{Lon: 7.62, Lat: 52.01}, Precedes: "attr"},
+			{ID: "a", Location: schema.Location{Lon: 7.63, Lat: 52.01}, Precedes: "b"},
+			{ID: "b", Location: schema.Location{Lon: 7.64, Lat: 52.01}, Precedes: "a"},
+			{ID: "ok", Location: schema.Location{Lon: 7.63, Lat: 52.0}},
+		},
+		Vehicles: []schema.Vehicle{
+			{
+				ID:            "v",
+				StartLocation: &start,
+				Speed:         &speed,
+				Capacity:      10,
+				StartTime:     &startTime,
+				EndTime:       &endTime,
+			},
+		},
+	}
+
+	unplannable, err := Analyze(input, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"a":      UnplannablePrecedenceCycle,
+		"attr":   UnplannableAttributes,
+		"b":      UnplannablePrecedenceCycle,
+		"heavy":  UnplannableCapacity,
+		"late":   UnplannableTimeWindow,
+		"pickup": UnplannableLinked,
+	}
+	if len(unplannable) != len(want) {
+		t.Fatalf("expected %v unplannable stops, got %v", len(want), unplannable)
+	}
+	for _, stop := range unplannable {
+		if want[stop.StopID] != stop.Reason {
+			t.Errorf(
+				"expected stop %v to be unplannable for reason %v, got %v",
+				stop.StopID,
+				want[stop.StopID],
+				stop.Reason,
+			)
+		}
+	}
+
+	options := Options{}
+	options.Constraints.Disable.Attributes = true
+	options.Constraints.Disable.Capacity = true
+	options.Constraints.Disable.Precedence = true
+	options.Constraints.Disable.StartTimeWindows = true
+	unplannable, err = Analyze(input, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unplannable) != 0 {
+		t.Errorf("expected no unplannable stops with constraints disabled, got %v", unplannable)
+	}
+}
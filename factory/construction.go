@@ -150,7 +150,7 @@ func NewStartSolution(
 
 	boundingBox := common.NewBoundingBox(
 		common.Map(input.Stops, func(stop schema.Stop) common.Location {
-			l, _ := common.NewLocation(stop.Location.Lon, stop.Location.Lat)
+			l, _ := toLocation(stop.Location)
 			return l
 		}),
 	)
@@ -235,16 +235,17 @@ func NewStartSolution(
 			close(experimentResults)
 
 			bestSize := -1.0
-			bestScore := math.MaxFloat64
+			var bestSideSolution nextroute.Solution
 
 			for result := range experimentResults {
 				if bestSolution == nil ||
-					result.solution.Score() < bestSolution.Score() {
+					nextroute.IsBetterSolution(result.solution, bestSolution) {
 					bestSolution = result.solution
 				}
-				if result.solution.Score() < bestScore {
+				if bestSideSolution == nil ||
+					nextroute.IsBetterSolution(result.solution, bestSideSolution) {
 					bestSize = result.side
-					bestScore = result.solution.Score()
+					bestSideSolution = result.solution
 				}
 			}
 			if bestSize == minSide || bestSize == maxSide {
@@ -389,11 +390,11 @@ func (s *sortStopClustersOnDistanceFromCentroidImpl) Sort(
 	centroid := CentroidLocation(input.Stops)
 	var err error
 	sort.Slice(clusters, func(i, j int) bool {
-		distanceI, e := HaversineDistance(clusters[i].Centroid(), centroid)
+		distanceI, e := StraightLineDistance(clusters[i].Centroid(), centroid)
 		if e != nil {
 			err = e
 		}
-		distanceJ, e := HaversineDistance(clusters[j].Centroid(), centroid)
+		distanceJ, e := StraightLineDistance(clusters[j].Centroid(), centroid)
 		if e != nil {
 			err = e
 		}
@@ -487,7 +488,7 @@ func (f *filterSortStopClusterAreaImpl) Filter(
 
 	if f.side < math.MaxFloat64 {
 		boundingBox := common.NewBoundingBox(common.Map(combinedStops, func(stop schema.Stop) common.Location {
-			loc, _ := common.NewLocation(stop.Location.Lon, stop.Location.Lat)
+			loc, _ := toLocation(stop.Location)
 			return loc
 		}))
 
@@ -566,10 +567,12 @@ func NewClusterSolution(
 	// we ignore duration matrix for now and need haversine distance
 	// anyway as we look for the centroid of the clusters
 	copyInput.DurationMatrix = nil
+	copyInput.DurationMatrixFile = ""
 
 	// we ignore distance matrix for now and need haversine distance
 	// anyway as we use the centroid of the clusters
 	copyInput.DistanceMatrix = nil
+	copyInput.DistanceMatrixFile = ""
 
 	speed := stopClusterOptions.Speed
 	for idx, vehicle := range copyInput.Vehicles {
@@ -734,8 +737,21 @@ func HaversineDistance(from, to schema.Location) (common.Distance, error) {
 	return distance, nil
 }
 
-// CentroidLocation returns the centroid of the given stops.
+// CentroidLocation returns the centroid of the given stops. The centroid of
+// stops with planar locations is a planar location.
 func CentroidLocation(stops []schema.Stop) schema.Location {
+	if len(stops) > 0 && stops[0].Location.IsPlanar() {
+		x := 0.0
+		y := 0.0
+		for _, stop := range stops {
+			point := stop.Location.ToPoint()
+			x += point[0]
+			y += point[1]
+		}
+		x /= float64(len(stops))
+		y /= float64(len(stops))
+		return schema.Location{X: &x, Y: &y}
+	}
 	lat := 0.0
 	lng := 0.0
 	for _, stop := range stops {
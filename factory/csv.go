@@ -0,0 +1,565 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// Names of the CSV files read by [ReadCSVInput] from a directory.
+const (
+	// CSVStopsFile holds one stop per row.
+	CSVStopsFile = "stops.csv"
+	// CSVVehiclesFile holds one vehicle per row.
+	CSVVehiclesFile = "vehicles.csv"
+	// CSVDistanceMatrixFile optionally holds the distance matrix.
+	CSVDistanceMatrixFile = "distance_matrix.csv"
+	// CSVDurationMatrixFile optionally holds the duration matrix.
+	CSVDurationMatrixFile = "duration_matrix.csv"
+)
+
+// csvListSeparator separates the values of a list in a single cell, for
+// example the compatibility attributes or the start time windows.
+const csvListSeparator = ";"
+
+// ReadCSVInput reads the input from the CSV files in the directory. The
+// stops are read from [CSVStopsFile] and the vehicles from [CSVVehiclesFile],
+// the matrices are read from [CSVDistanceMatrixFile] and
+// [CSVDurationMatrixFile] if present. See [ParseCSVInput] for the format of
+// the files.
+func ReadCSVInput(dir string) (schema.Input, error) {
+	stops, err := os.Open(filepath.Join(dir, CSVStopsFile))
+	if err != nil {
+		return schema.Input{}, err
+	}
+	defer stops.Close()
+
+	vehicles, err := os.Open(filepath.Join(dir, CSVVehiclesFile))
+	if err != nil {
+		return schema.Input{}, err
+	}
+	defer vehicles.Close()
+
+	var distanceMatrix, durationMatrix io.Reader
+	if file, err := os.Open(filepath.Join(dir, CSVDistanceMatrixFile)); err == nil {
+		defer file.Close()
+		distanceMatrix = file
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return schema.Input{}, err
+	}
+	if file, err := os.Open(filepath.Join(dir, CSVDurationMatrixFile)); err == nil {
+		defer file.Close()
+		durationMatrix = file
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return schema.Input{}, err
+	}
+
+	return ParseCSVInput(stops, vehicles, distanceMatrix, durationMatrix)
+}
+
+// ParseCSVInput builds the input from CSV data. The matrices are optional and
+// can be nil.
+//
+// The first row of the stops and vehicles holds the names of the columns,
+// which are the JSON names of the fields of [schema.Stop] and
+// [schema.Vehicle]. Empty cells leave the field unset. Locations are given by
+// the columns `lon` and `lat` for stops and `start_lon`, `start_lat`,
+// `end_lon` and `end_lat` for vehicles, planar locations by the columns `x`
+// and `y`, `start_x`, `start_y`, `end_x` and `end_y`. Lists, such as
+// `compatibility_attributes`, `precedes` or `initial_stops`, are separated by
+// semicolons. A start time window is given as `start/end` in RFC3339, several
+// windows are separated by semicolons. Quantities, capacities and start levels
+// of multiple resources are given by a column per resource named
+// `quantity.<resource>`, `capacity.<resource>` or `start_level.<resource>`.
+// Stops with the same value in the `group` column form a stop group. Other
+// columns are added to the custom data. Column names are matched regardless of
+// case, resource names and the names of custom data columns are kept as
+// written.
+//
+// The matrices have no header, one row per stop followed by two rows per
+// vehicle, start and end, in the order of the stops and vehicles.
+//
+// All the errors found are returned with their file, row and column.
+func ParseCSVInput(
+	stops io.Reader,
+	vehicles io.Reader,
+	distanceMatrix io.Reader,
+	durationMatrix io.Reader,
+) (schema.Input, error) {
+	v := &validation{}
+	input := schema.Input{}
+
+	groups := map[string][]string{}
+	groupOrder := make([]string, 0)
+	readCSVRows(v, CSVStopsFile, stops, csvStopFields, func(row csvRow) {
+		stop := row.stop()
+		input.Stops = append(input.Stops, stop)
+		if group := row.value("group"); group != "" {
+			if _, ok := groups[group]; !ok {
+				groupOrder = append(groupOrder, group)
+			}
+			groups[group] = append(groups[group], stop.ID)
+		}
+	})
+	stopGroups := make([][]string, 0, len(groupOrder))
+	for _, group := range groupOrder {
+		if len(groups[group]) > 1 {
+			stopGroups = append(stopGroups, groups[group])
+		}
+	}
+	if len(stopGroups) > 0 {
+		input.StopGroups = &stopGroups
+	}
+
+	readCSVRows(v, CSVVehiclesFile, vehicles, csvVehicleFields, func(row csvRow) {
+		input.Vehicles = append(input.Vehicles, row.vehicle())
+	})
+
+	if distanceMatrix != nil {
+		matrix := readCSVMatrix(v, CSVDistanceMatrixFile, distanceMatrix)
+		input.DistanceMatrix = &matrix
+	}
+	if durationMatrix != nil {
+		input.DurationMatrix = readCSVMatrix(v, CSVDurationMatrixFile, durationMatrix)
+	}
+
+	if err := v.err(); err != nil {
+		return schema.Input{}, err
+	}
+	return input, nil
+}
+
+// readCSVRows calls the function for each row of the CSV data after the
+// header row, the columns are named as returned by [csvColumnName].
+func readCSVRows(
+	v *validation,
+	file string,
+	data io.Reader,
+	fields map[string]bool,
+	f func(csvRow),
+) {
+	reader := csv.NewReader(data)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		v.add(file, ValidationInvalidValue, "could not read header: %v", err)
+		return
+	}
+	for i, column := range header {
+		// Spreadsheets may start the file with a byte order mark.
+		header[i] = csvColumnName(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")), fields)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		if errors.Is(err, csv.ErrFieldCount) {
+			// The row is still read, the remaining rows are checked too.
+			line, _ := reader.FieldPos(0)
+			v.add(
+				fmt.Sprintf("%s:%d", file, line),
+				ValidationInvalidValue,
+				"row has %v columns, header has %v",
+				len(record),
+				len(header),
+			)
+			continue
+		}
+		if err != nil {
+			v.add(file, ValidationInvalidValue, "%v", err)
+			return
+		}
+		line, _ := reader.FieldPos(0)
+		row := csvRow{
+			v:      v,
+			file:   file,
+			line:   line,
+			values: make(map[string]string, len(header)),
+			header: header,
+		}
+		for i, column := range header {
+			row.values[column] = strings.TrimSpace(record[i])
+		}
+		f(row)
+	}
+}
+
+// csvColumnName returns the name of the column in the header. The names of
+// the fields are matched regardless of case, as is the field of a resource
+// column `<field>.<resource>`, the resource and the names of custom data
+// columns are kept as written.
+func csvColumnName(column string, fields map[string]bool) string {
+	field, resource, isResource := strings.Cut(column, ".")
+	if !fields[strings.ToLower(field)] {
+		return column
+	}
+	if isResource {
+		return strings.ToLower(field) + "." + resource
+	}
+	return strings.ToLower(column)
+}
+
+// readCSVMatrix reads a matrix of numbers without a header.
+func readCSVMatrix(v *validation, file string, data io.Reader) [][]float64 {
+	reader := csv.NewReader(data)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	matrix := make([][]float64, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return matrix
+		}
+		if err != nil {
+			v.add(file, ValidationInvalidValue, "%v", err)
+			return matrix
+		}
+		line, _ := reader.FieldPos(0)
+		row := make([]float64, len(record))
+		for i, cell := range record {
+			value, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+			if err != nil {
+				v.add(
+					fmt.Sprintf("%s:%d:%d", file, line, i+1),
+					ValidationInvalidValue,
+					"invalid number `%s`",
+					cell,
+				)
+			}
+			row[i] = value
+		}
+		matrix = append(matrix, row)
+	}
+}
+
+// csvRow is a row of a CSV file, the values are keyed by column name.
+type csvRow struct {
+	v      *validation
+	file   string
+	line   int
+	values map[string]string
+	header []string
+}
+
+func (r csvRow) add(column, format string, args ...any) {
+	r.v.add(
+		fmt.Sprintf("%s:%d:%s", r.file, r.line, column),
+		ValidationInvalidValue,
+		format,
+		args...,
+	)
+}
+
+func (r csvRow) value(column string) string {
+	return r.values[column]
+}
+
+func (r csvRow) intValue(column string) *int {
+	value := r.value(column)
+	if value == "" {
+		return nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		r.add(column, "invalid integer `%s`", value)
+		return nil
+	}
+	return &i
+}
+
+func (r csvRow) floatValue(column string) *float64 {
+	value := r.value(column)
+	if value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.add(column, "invalid number `%s`", value)
+		return nil
+	}
+	return &f
+}
+
+func (r csvRow) timeValue(column string) *time.Time {
+	value := r.value(column)
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		r.add(column, "invalid RFC3339 time `%s`", value)
+		return nil
+	}
+	return &t
+}
+
+func (r csvRow) list(column string) []string {
+	value := r.value(column)
+	if value == "" {
+		return nil
+	}
+	items := strings.Split(value, csvListSeparator)
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+func (r csvRow) listValue(column string) *[]string {
+	items := r.list(column)
+	if items == nil {
+		return nil
+	}
+	return &items
+}
+
+// location returns the location given by the longitude and latitude columns
+// or, for a planar location, by the x and y columns, nil if all are empty.
+// The names of the columns start with the prefix.
+func (r csvRow) location(prefix string) *schema.Location {
+	xColumn, yColumn := prefix+"x", prefix+"y"
+	if r.value(xColumn) != "" || r.value(yColumn) != "" {
+		x := r.floatValue(xColumn)
+		y := r.floatValue(yColumn)
+		switch {
+		case r.value(xColumn) == "":
+			r.add(xColumn, "x is missing")
+		case r.value(yColumn) == "":
+			r.add(yColumn, "y is missing")
+		case x != nil && y != nil:
+			return &schema.Location{X: x, Y: y}
+		}
+		return nil
+	}
+
+	lonColumn, latColumn := prefix+"lon", prefix+"lat"
+	if r.value(lonColumn) == "" && r.value(latColumn) == "" {
+		return nil
+	}
+	lon := r.floatValue(lonColumn)
+	lat := r.floatValue(latColumn)
+	switch {
+	case r.value(lonColumn) == "":
+		r.add(lonColumn, "longitude is missing")
+	case r.value(latColumn) == "":
+		r.add(latColumn, "latitude is missing")
+	case lon != nil && lat != nil:
+		return &schema.Location{Lon: *lon, Lat: *lat}
+	}
+	return nil
+}
+
+// precedence returns the ids of the stops in the column the same way they are
+// decoded from JSON, a single id as a string and several as a slice.
+func (r csvRow) precedence(column string) any {
+	ids := r.list(column)
+	switch len(ids) {
+	case 0:
+		return nil
+	case 1:
+		return ids[0]
+	}
+	precedence := make([]any, len(ids))
+	for i, id := range ids {
+		precedence[i] = id
+	}
+	return precedence
+}
+
+// timeWindows returns the start time windows in the column the same way they
+// are decoded from JSON.
+func (r csvRow) timeWindows(column string) any {
+	windows := r.list(column)
+	if windows == nil {
+		return nil
+	}
+	timeWindows := make([]any, 0, len(windows))
+	for _, window := range windows {
+		start, end, ok := strings.Cut(window, "/")
+		if !ok {
+			r.add(column, "time window `%s` is not of the form `start/end`", window)
+			continue
+		}
+		timeWindows = append(timeWindows, []any{
+			strings.TrimSpace(start),
+			strings.TrimSpace(end),
+		})
+	}
+	return timeWindows
+}
+
+// resources returns the value of the column for a single resource or a map
+// of the values of the columns `<column>.<resource>` for multiple resources,
+// the same way they are decoded from JSON.
+func (r csvRow) resources(column string) any {
+	values := map[string]any{}
+	for _, name := range r.header {
+		resource, ok := strings.CutPrefix(name, column+".")
+		if !ok {
+			continue
+		}
+		if value := r.floatValue(name); value != nil {
+			values[resource] = *value
+		}
+	}
+	single := r.floatValue(column)
+	if len(values) == 0 {
+		if single == nil {
+			return nil
+		}
+		return *single
+	}
+	if single != nil {
+		values["default"] = *single
+	}
+	return values
+}
+
+// customData returns the values of the columns which are not fields of the
+// entity, nil if there are none.
+func (r csvRow) customData(fields map[string]bool, resourceColumns ...string) any {
+	customData := map[string]any{}
+	for _, column := range r.header {
+		if fields[column] || r.value(column) == "" {
+			continue
+		}
+		isResource := false
+		for _, resourceColumn := range resourceColumns {
+			if strings.HasPrefix(column, resourceColumn+".") {
+				isResource = true
+			}
+		}
+		if !isResource {
+			customData[column] = r.value(column)
+		}
+	}
+	if len(customData) == 0 {
+		return nil
+	}
+	return customData
+}
+
+var csvStopFields = map[string]bool{
+	"id":                         true,
+	"lon":                        true,
+	"lat":                        true,
+	"x":                          true,
+	"y":                          true,
+	"group":                      true,
+	"duration":                   true,
+	"max_wait":                   true,
+	"unplanned_penalty":          true,
+	"early_arrival_time_penalty": true,
+	"late_arrival_time_penalty":  true,
+	"target_arrival_time":        true,
+	"start_time_window":          true,
+	"quantity":                   true,
+	"compatibility_attributes":   true,
+	"precedes":                   true,
+	"succeeds":                   true,
+}
+
+func (r csvRow) stop() schema.Stop {
+	stop := schema.Stop{
+		ID:                      r.value("id"),
+		Duration:                r.intValue("duration"),
+		MaxWait:                 r.intValue("max_wait"),
+		UnplannedPenalty:        r.intValue("unplanned_penalty"),
+		EarlyArrivalTimePenalty: r.floatValue("early_arrival_time_penalty"),
+		LateArrivalTimePenalty:  r.floatValue("late_arrival_time_penalty"),
+		TargetArrivalTime:       r.timeValue("target_arrival_time"),
+		StartTimeWindow:         r.timeWindows("start_time_window"),
+		Quantity:                r.resources("quantity"),
+		CompatibilityAttributes: r.listValue("compatibility_attributes"),
+		Precedes:                r.precedence("precedes"),
+		Succeeds:                r.precedence("succeeds"),
+		CustomData:              r.customData(csvStopFields, "quantity"),
+	}
+	if stop.ID == "" {
+		r.add("id", "no id set for stop")
+	}
+	if location := r.location(""); location != nil {
+		stop.Location = *location
+	} else if r.value("lon") == "" && r.value("lat") == "" &&
+		r.value("x") == "" && r.value("y") == "" {
+		r.add("lon", "stop `%s` has no location", stop.ID)
+	}
+	return stop
+}
+
+var csvVehicleFields = map[string]bool{
+	"id":                       true,
+	"start_lon":                true,
+	"start_lat":                true,
+	"end_lon":                  true,
+	"end_lat":                  true,
+	"start_x":                  true,
+	"start_y":                  true,
+	"end_x":                    true,
+	"end_y":                    true,
+	"speed":                    true,
+	"capacity":                 true,
+	"start_level":              true,
+	"start_time":               true,
+	"end_time":                 true,
+	"min_stops":                true,
+	"min_stops_penalty":        true,
+	"max_stops":                true,
+	"max_distance":             true,
+	"max_duration":             true,
+	"max_wait":                 true,
+	"activation_penalty":       true,
+	"stop_duration_multiplier": true,
+	"compatibility_attributes": true,
+	"alternate_stops":          true,
+	"initial_stops":            true,
+}
+
+func (r csvRow) vehicle() schema.Vehicle {
+	vehicle := schema.Vehicle{
+		ID:                      r.value("id"),
+		StartLocation:           r.location("start_"),
+		EndLocation:             r.location("end_"),
+		Speed:                   r.floatValue("speed"),
+		Capacity:                r.resources("capacity"),
+		StartLevel:              r.resources("start_level"),
+		StartTime:               r.timeValue("start_time"),
+		EndTime:                 r.timeValue("end_time"),
+		MinStops:                r.intValue("min_stops"),
+		MinStopsPenalty:         r.floatValue("min_stops_penalty"),
+		MaxStops:                r.intValue("max_stops"),
+		MaxDistance:             r.intValue("max_distance"),
+		MaxDuration:             r.intValue("max_duration"),
+		MaxWait:                 r.intValue("max_wait"),
+		ActivationPenalty:       r.intValue("activation_penalty"),
+		StopDurationMultiplier:  r.floatValue("stop_duration_multiplier"),
+		CompatibilityAttributes: r.listValue("compatibility_attributes"),
+		AlternateStops:          r.listValue("alternate_stops"),
+		CustomData:              r.customData(csvVehicleFields, "capacity", "start_level"),
+	}
+	if vehicle.ID == "" {
+		r.add("id", "no id set for vehicle")
+	}
+	if ids := r.list("initial_stops"); ids != nil {
+		initialStops := make([]schema.InitialStop, len(ids))
+		for i, id := range ids {
+			initialStops[i] = schema.InitialStop{ID: id}
+		}
+		vehicle.InitialStops = &initialStops
+	}
+	return vehicle
+}
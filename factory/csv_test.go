@@ -0,0 +1,103 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+)
+
+func TestParseCSVInput(t *testing.T) {
+	stops := strings.NewReader(
+		"ID,Lon,Lat,duration,quantity,Quantity.Volume,start_time_window,precedes,group,Customer\n" +
+			"s1,0.01,0,60,-1,-2,2023-01-01T09:00:00Z/2023-01-01T10:00:00Z;" +
+			"2023-01-01T11:00:00Z/2023-01-01T12:00:00Z,s2;s3,g1,acme\n" +
+			"s2,0.02,0,,,,,,g1,\n" +
+			"s3,0.03,0,,,,,,,\n",
+	)
+	vehicles := strings.NewReader(
+		"id,start_lon,start_lat,speed,capacity,CAPACITY.Volume,initial_stops\n" +
+			"v1,0,0,10,5,5,s1;s2\n",
+	)
+	durationMatrix := strings.NewReader("0,1\n1,0\n")
+
+	input, err := ParseCSVInput(stops, vehicles, nil, durationMatrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(input.Stops) != 3 || len(input.Vehicles) != 1 {
+		t.Fatalf("expected 3 stops and 1 vehicle, got %v and %v", len(input.Stops), len(input.Vehicles))
+	}
+	stop := input.Stops[0]
+	if stop.Duration == nil || *stop.Duration != 60 {
+		t.Errorf("expected duration 60, got %v", stop.Duration)
+	}
+	quantity, ok := stop.Quantity.(map[string]any)
+	if !ok || quantity["default"] != -1.0 || quantity["Volume"] != -2.0 {
+		t.Errorf("expected quantities for default and Volume, got %v", stop.Quantity)
+	}
+	windows, err := convertTimeWindow(stop.StartTimeWindow, stop.ID)
+	if err != nil || len(windows) != 2 {
+		t.Errorf("expected 2 time windows, got %v, %v", windows, err)
+	}
+	precedes, err := precedence(stop, "Precedes")
+	if err != nil || len(precedes) != 2 {
+		t.Errorf("expected 2 precedes, got %v, %v", precedes, err)
+	}
+	if customData, ok := stop.CustomData.(map[string]any); !ok || customData["Customer"] != "acme" {
+		t.Errorf("expected custom data, got %v", stop.CustomData)
+	}
+	if input.StopGroups == nil || len(*input.StopGroups) != 1 || len((*input.StopGroups)[0]) != 2 {
+		t.Errorf("expected a stop group of 2 stops, got %v", input.StopGroups)
+	}
+	vehicle := input.Vehicles[0]
+	if vehicle.StartLocation == nil || vehicle.InitialStops == nil || len(*vehicle.InitialStops) != 2 {
+		t.Errorf("expected start location and 2 initial stops, got %+v", vehicle)
+	}
+	if capacity, ok := vehicle.Capacity.(map[string]any); !ok || capacity["Volume"] != 5.0 {
+		t.Errorf("expected capacity for Volume, got %v", vehicle.Capacity)
+	}
+	if matrix, ok := input.DurationMatrix.([][]float64); !ok || len(matrix) != 2 {
+		t.Errorf("expected duration matrix, got %v", input.DurationMatrix)
+	}
+
+	input, err = ParseCSVInput(
+		strings.NewReader("id,x,y\ns1,0,12.5\n"),
+		strings.NewReader("id,speed,start_x,start_y\nv1,1,3,4\n"),
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location := input.Stops[0].Location; location.X == nil || *location.Y != 12.5 {
+		t.Errorf("expected planar stop location, got %v", location)
+	}
+	if location := input.Vehicles[0].StartLocation; location == nil || !location.IsPlanar() {
+		t.Errorf("expected planar start location, got %v", location)
+	}
+
+	_, err = ParseCSVInput(
+		strings.NewReader("id,lon,lat,duration\ns1,0,zero,1\n,0,0,x\n"),
+		strings.NewReader("id,speed\nv1,10\n"),
+		nil,
+		nil,
+	)
+	var validationErrors nmerror.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	want := []string{"stops.csv:2:lat", "stops.csv:3:duration", "stops.csv:3:id"}
+	if len(validationErrors) != len(want) {
+		t.Fatalf("expected %v errors, got %v", len(want), validationErrors)
+	}
+	for i, path := range want {
+		if validationErrors[i].Path != path {
+			t.Errorf("expected error at %v, got %v", path, validationErrors[i])
+		}
+	}
+}
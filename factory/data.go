@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/schema"
 )
 
 // modelData represents custom data at the Model level that can be used across
@@ -26,6 +27,11 @@ type modelData struct {
 	// Groups of stops that must be assigned to a vehicle as a group or not be
 	// assigned.
 	groups []group
+	// Stops which the analysis of the input proved can not be planned.
+	unplannable []schema.UnplannableStopOutput
+	// Objective name -> priority, parsed once from the options when the
+	// model is created.
+	objectivePriorities map[string]int
 }
 
 // vehicleTypeData represents custom data for a VehicleType that can be used
@@ -0,0 +1,193 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// DiffOptions configure the comparison of two solutions.
+type DiffOptions struct {
+	// ArrivalThreshold is the shift of the arrival time of a stop beyond
+	// which the shift is reported.
+	ArrivalThreshold time.Duration
+}
+
+// plannedStop is a stop as planned in a solution output.
+type plannedStop struct {
+	vehicleID string
+	arrival   *time.Time
+}
+
+// DiffSolutions compares two solutions, such as the plans of two days or the
+// solutions before and after a change of the options. Stops are matched by
+// ID, the start and end stops of the vehicles are ignored. Stops which exist
+// in only one of the solutions are not reported as moved, shifted, newly
+// planned or unplanned.
+func DiffSolutions(
+	from schema.SolutionOutput,
+	to schema.SolutionOutput,
+	options DiffOptions,
+) schema.SolutionDiffOutput {
+	fromStops := plannedStops(from)
+	toStops := plannedStops(to)
+
+	diff := schema.SolutionDiffOutput{
+		MovedStops:      make([]schema.MovedStopOutput, 0),
+		SequenceChanges: make([]schema.SequenceChangeOutput, 0),
+		ArrivalShifts:   make([]schema.ArrivalShiftOutput, 0),
+		NewlyUnplanned:  make([]string, 0),
+		NewlyPlanned:    make([]string, 0),
+		Objective: objectiveDelta(
+			from.Objective.Name,
+			from.Objective.Value,
+			to.Objective.Value,
+		),
+		ObjectiveTerms: objectiveTermDeltas(from.Objective, to.Objective),
+	}
+	if to.Objective.Name != "" {
+		diff.Objective.Name = to.Objective.Name
+	}
+
+	fromRoutes := make(map[string][]string, len(from.Vehicles))
+	for _, vehicle := range from.Vehicles {
+		fromRoutes[vehicle.ID] = routeStopIDs(vehicle)
+	}
+
+	for _, vehicle := range to.Vehicles {
+		route := routeStopIDs(vehicle)
+		for _, id := range route {
+			fromStop, ok := fromStops[id]
+			if !ok {
+				continue
+			}
+			toStop := toStops[id]
+			if fromStop.vehicleID != vehicle.ID {
+				diff.MovedStops = append(diff.MovedStops, schema.MovedStopOutput{
+					StopID:        id,
+					FromVehicleID: fromStop.vehicleID,
+					ToVehicleID:   vehicle.ID,
+				})
+			}
+			if fromStop.arrival == nil || toStop.arrival == nil {
+				continue
+			}
+			shift := toStop.arrival.Sub(*fromStop.arrival)
+			if shift > options.ArrivalThreshold || -shift > options.ArrivalThreshold {
+				diff.ArrivalShifts = append(diff.ArrivalShifts, schema.ArrivalShiftOutput{
+					StopID:    id,
+					VehicleID: vehicle.ID,
+					From:      *fromStop.arrival,
+					To:        *toStop.arrival,
+					Shift:     int(shift.Seconds()),
+				})
+			}
+		}
+
+		fromSequence := make([]string, 0, len(route))
+		for _, id := range fromRoutes[vehicle.ID] {
+			if toStop, ok := toStops[id]; ok && toStop.vehicleID == vehicle.ID {
+				fromSequence = append(fromSequence, id)
+			}
+		}
+		toSequence := make([]string, 0, len(route))
+		for _, id := range route {
+			if fromStop, ok := fromStops[id]; ok && fromStop.vehicleID == vehicle.ID {
+				toSequence = append(toSequence, id)
+			}
+		}
+		for idx := range toSequence {
+			if toSequence[idx] != fromSequence[idx] {
+				diff.SequenceChanges = append(diff.SequenceChanges, schema.SequenceChangeOutput{
+					VehicleID: vehicle.ID,
+					From:      fromSequence,
+					To:        toSequence,
+				})
+				break
+			}
+		}
+	}
+
+	fromUnplanned := make(map[string]bool, len(from.Unplanned))
+	for _, stop := range from.Unplanned {
+		fromUnplanned[stop.ID] = true
+	}
+	for _, stop := range to.Unplanned {
+		if _, ok := fromStops[stop.ID]; ok {
+			diff.NewlyUnplanned = append(diff.NewlyUnplanned, stop.ID)
+		}
+	}
+	for _, vehicle := range to.Vehicles {
+		for _, id := range routeStopIDs(vehicle) {
+			if fromUnplanned[id] {
+				diff.NewlyPlanned = append(diff.NewlyPlanned, id)
+			}
+		}
+	}
+
+	return diff
+}
+
+// plannedStops returns the planned stops of the solution by stop ID.
+func plannedStops(solution schema.SolutionOutput) map[string]plannedStop {
+	stops := map[string]plannedStop{}
+	for _, vehicle := range solution.Vehicles {
+		for position, stop := range vehicle.Route {
+			if isVehicleStartOrEnd(vehicle, position) {
+				continue
+			}
+			stops[stop.Stop.ID] = plannedStop{
+				vehicleID: vehicle.ID,
+				arrival:   stop.ArrivalTime,
+			}
+		}
+	}
+	return stops
+}
+
+// routeStopIDs returns the IDs of the stops on the route of the vehicle
+// without its start and end stops.
+func routeStopIDs(vehicle schema.VehicleOutput) []string {
+	ids := make([]string, 0, len(vehicle.Route))
+	for position, stop := range vehicle.Route {
+		if !isVehicleStartOrEnd(vehicle, position) {
+			ids = append(ids, stop.Stop.ID)
+		}
+	}
+	return ids
+}
+
+// objectiveTermDeltas returns the changes of the values of the objective
+// terms, matched by name. A term of only one of the objectives has a value
+// of zero in the other.
+func objectiveTermDeltas(from, to schema.ObjectiveOutput) []schema.ObjectiveDeltaOutput {
+	fromValues := make(map[string]float64, len(from.Objectives))
+	for _, term := range from.Objectives {
+		fromValues[term.Name] = term.Value
+	}
+
+	deltas := make([]schema.ObjectiveDeltaOutput, 0, len(to.Objectives))
+	seen := make(map[string]bool, len(to.Objectives))
+	for _, term := range to.Objectives {
+		seen[term.Name] = true
+		deltas = append(deltas, objectiveDelta(term.Name, fromValues[term.Name], term.Value))
+	}
+	for _, term := range from.Objectives {
+		if !seen[term.Name] {
+			deltas = append(deltas, objectiveDelta(term.Name, term.Value, 0))
+		}
+	}
+
+	return deltas
+}
+
+func objectiveDelta(name string, from, to float64) schema.ObjectiveDeltaOutput {
+	return schema.ObjectiveDeltaOutput{
+		Name:  name,
+		From:  from,
+		To:    to,
+		Delta: to - from,
+	}
+}
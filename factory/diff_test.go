@@ -0,0 +1,113 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestDiffSolutions(t *testing.T) {
+	start := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+	route := func(vehicleID string, stops ...string) schema.VehicleOutput {
+		planned := []schema.PlannedStopOutput{
+			{Stop: schema.StopOutput{ID: vehicleID + "-start"}},
+		}
+		for idx, id := range stops {
+			arrival := start.Add(time.Duration(idx+1) * 10 * time.Minute)
+			planned = append(planned, schema.PlannedStopOutput{
+				Stop:        schema.StopOutput{ID: id},
+				ArrivalTime: &arrival,
+			})
+		}
+		planned = append(planned, schema.PlannedStopOutput{
+			Stop: schema.StopOutput{ID: vehicleID + "-end"},
+		})
+		return schema.VehicleOutput{ID: vehicleID, Route: planned}
+	}
+
+	from := schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			route("v1", "s1", "s2", "s3"),
+			route("v2", "s4"),
+		},
+		Unplanned: []schema.StopOutput{{ID: "s5"}},
+		Objective: schema.ObjectiveOutput{
+			Name:  "1 * travel_duration",
+			Value: 100,
+			Objectives: []schema.ObjectiveOutput{
+				{Name: "travel_duration", Value: 100},
+			},
+		},
+	}
+	to := schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			route("v1", "s3", "s1", "s5"),
+			route("v2", "s4", "s2"),
+		},
+		Unplanned: []schema.StopOutput{},
+		Objective: schema.ObjectiveOutput{
+			Name:  "1 * travel_duration + 1 * unplanned_penalty",
+			Value: 90,
+			Objectives: []schema.ObjectiveOutput{
+				{Name: "travel_duration", Value: 90},
+			},
+		},
+	}
+
+	diff := DiffSolutions(from, to, DiffOptions{ArrivalThreshold: 5 * time.Minute})
+
+	if len(diff.MovedStops) != 1 || diff.MovedStops[0] != (schema.MovedStopOutput{
+		StopID:        "s2",
+		FromVehicleID: "v1",
+		ToVehicleID:   "v2",
+	}) {
+		t.Errorf("expected s2 moved from v1 to v2, got %v", diff.MovedStops)
+	}
+	if len(diff.SequenceChanges) != 1 ||
+		diff.SequenceChanges[0].VehicleID != "v1" ||
+		!slices.Equal(diff.SequenceChanges[0].From, []string{"s1", "s3"}) ||
+		!slices.Equal(diff.SequenceChanges[0].To, []string{"s3", "s1"}) {
+		t.Errorf("expected s1 and s3 swapped on v1, got %v", diff.SequenceChanges)
+	}
+	shifts := map[string]int{}
+	for _, shift := range diff.ArrivalShifts {
+		shifts[shift.StopID] = shift.Shift
+	}
+	// s1 and s3 swap positions, s2 moves to the second position of v2, which
+	// keeps its arrival time.
+	expected := map[string]int{"s1": 600, "s3": -1200}
+	if len(shifts) != len(expected) {
+		t.Errorf("expected arrival shifts %v, got %v", expected, shifts)
+	}
+	for id, shift := range expected {
+		if shifts[id] != shift {
+			t.Errorf("expected arrival shift %v of %s, got %v", shift, id, shifts[id])
+		}
+	}
+	if !slices.Equal(diff.NewlyPlanned, []string{"s5"}) || len(diff.NewlyUnplanned) != 0 {
+		t.Errorf(
+			"expected s5 newly planned, got planned %v, unplanned %v",
+			diff.NewlyPlanned,
+			diff.NewlyUnplanned,
+		)
+	}
+	if diff.Objective.Delta != -10 {
+		t.Errorf("expected objective delta of -10, got %v", diff.Objective.Delta)
+	}
+	if len(diff.ObjectiveTerms) != 1 || diff.ObjectiveTerms[0].Delta != -10 {
+		t.Errorf("expected travel duration delta of -10, got %v", diff.ObjectiveTerms)
+	}
+
+	reverse := DiffSolutions(to, from, DiffOptions{ArrivalThreshold: time.Hour})
+	if !slices.Equal(reverse.NewlyUnplanned, []string{"s5"}) || len(reverse.ArrivalShifts) != 0 {
+		t.Errorf(
+			"expected s5 newly unplanned and no shifts, got unplanned %v, shifts %v",
+			reverse.NewlyUnplanned,
+			reverse.ArrivalShifts,
+		)
+	}
+}
@@ -0,0 +1,81 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// Evaluate creates the model of the input and constructs the solution given
+// by the routes, without solving. The routes of the vehicles are mapped onto
+// the stops of the model by ID and planned as submitted, see
+// [nextroute.NewSubmittedSolution], so the arrival times and the score of the
+// solution are those of the submitted plan. Instead of failing on infeasible
+// routes, every constraint is checked on every stop and vehicle and a
+// violation is returned for each stop or vehicle and each constraint it
+// violates. Stops which can not be part of any solution, such as unknown
+// stops, are dropped and returned as dropped violations, see
+// [ToEvaluationOutput].
+func Evaluate(
+	input schema.Input,
+	options Options,
+	routes schema.SolutionOutput,
+) (nextroute.Solution, []schema.ViolationOutput, error) {
+	model, err := NewModel(input, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	solutionRoutes, violations, err := toSolutionRoutes(input, model, routes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	solution, routeViolations, infeasibilities, err := nextroute.NewSubmittedSolution(
+		model,
+		solutionRoutes,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	violations = append(violations, toViolations(infeasibilities)...)
+	return solution, append(violations, toRouteViolations(routeViolations)...), nil
+}
+
+// toRouteViolations returns a violation for each violation of the submitted
+// routes.
+func toRouteViolations(
+	routeViolations nextroute.RouteViolations,
+) []schema.ViolationOutput {
+	violations := make([]schema.ViolationOutput, len(routeViolations))
+	for idx, routeViolation := range routeViolations {
+		violations[idx] = schema.ViolationOutput{
+			Constraint: routeViolation.ConstraintName(),
+			Reason:     routeViolation.Reason,
+		}
+		if routeViolation.Stop != nil {
+			violations[idx].StopID = routeViolation.Stop.ID()
+		}
+		if routeViolation.Vehicle != nil {
+			violations[idx].VehicleID = routeViolation.Vehicle.ID()
+		}
+	}
+	return violations
+}
+
+// ToEvaluationOutput describes how the routes of [Evaluate] were scored given
+// the violations it returned.
+func ToEvaluationOutput(violations []schema.ViolationOutput) schema.EvaluationOutput {
+	evaluation := schema.EvaluationOutput{}
+	for _, violation := range violations {
+		if violation.Dropped {
+			evaluation.DroppedStops++
+		} else if violation.Constraint != "" {
+			evaluation.ConstraintViolations++
+		}
+	}
+	evaluation.AsSubmitted = evaluation.DroppedStops == 0
+	return evaluation
+}
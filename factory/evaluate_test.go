@@ -0,0 +1,110 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestEvaluate(t *testing.T) {
+	speed := 10.0
+	start := schema.Location{Lon: 7.6, Lat: 52.0}
+	startTime := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+	maxStops := 1
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{
+				ID:       "late",
+				Location: schema.Location{Lon: 7.7, Lat: 52.0},
+				Quantity: -2,
+				StartTimeWindow: []any{
+					startTime.Format(time.RFC3339),
+					startTime.Add(5 * time.Minute).Format(time.RFC3339),
+				},
+			},
+			{ID: "heavy", Location: schema.Location{Lon: 7.71, Lat: 52.0}, Quantity: -1},
+			{ID: "pickup", Location: schema.Location{Lon: 7.6, Lat: 52.01}, Precedes: "delivery"},
+			{ID: "delivery", Location: schema.Location{Lon: 7.6, Lat: 52.02}},
+		},
+		Vehicles: []schema.Vehicle{
+			{
+				ID:            "v1",
+				StartLocation: &start,
+				Speed:         &speed,
+				Capacity:      1,
+				StartTime:     &startTime,
+				MaxStops:      &maxStops,
+			},
+			{ID: "v2", StartLocation: &start, Speed: &speed, Capacity: 1, StartTime: &startTime},
+		},
+	}
+
+	routes := schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{
+				ID: "v1",
+				Route: []schema.PlannedStopOutput{
+					{Stop: schema.StopOutput{ID: "late"}},
+					{Stop: schema.StopOutput{ID: "heavy"}},
+					{Stop: schema.StopOutput{ID: "unknown"}},
+				},
+			},
+			{
+				ID: "v2",
+				Route: []schema.PlannedStopOutput{
+					{Stop: schema.StopOutput{ID: "delivery"}},
+					{Stop: schema.StopOutput{ID: "pickup"}},
+				},
+			},
+		},
+	}
+
+	solution, violations, err := Evaluate(input, Options{}, routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every stop is planned as submitted, including the infeasible ones.
+	if solution.PlannedPlanUnits().Size() != 3 {
+		t.Errorf(
+			"expected 3 planned plan units, got %v",
+			solution.PlannedPlanUnits().Size(),
+		)
+	}
+	late := solution.Vehicles()[0].SolutionStops()[1]
+	if late.ModelStop().ID() != "late" {
+		t.Fatalf("expected stop late first on v1, got %v", late.ModelStop().ID())
+	}
+	if !late.Arrival().After(startTime.Add(5 * time.Minute)) {
+		t.Errorf("expected the arrival at late after its window, got %v", late.Arrival())
+	}
+
+	want := map[schema.ViolationOutput]bool{
+		{StopID: "unknown", VehicleID: "v1", Reason: "does not exist", Dropped: true}:                        true,
+		{StopID: "late", VehicleID: "v1", Constraint: "capacity_default"}:                                    true,
+		{StopID: "late", VehicleID: "v1", Constraint: "late_start_penalty"}:                                  true,
+		{StopID: "heavy", VehicleID: "v1", Constraint: "capacity_default"}:                                   true,
+		{StopID: "late", VehicleID: "v1", Constraint: "maximum_stops"}:                                       true,
+		{StopID: "heavy", VehicleID: "v1", Constraint: "maximum_stops"}:                                      true,
+		{VehicleID: "v1", Constraint: "capacity_default"}:                                                    true,
+		{StopID: "delivery", VehicleID: "v2", Reason: "is visited in an order its plan unit does not allow"}: true,
+		{StopID: "pickup", VehicleID: "v2", Reason: "is visited in an order its plan unit does not allow"}:   true,
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("expected violations %v, got %v", want, violations)
+	}
+	for _, violation := range violations {
+		if !want[violation] {
+			t.Errorf("unexpected violation %v", violation)
+		}
+	}
+
+	evaluation := ToEvaluationOutput(violations)
+	wantEvaluation := schema.EvaluationOutput{DroppedStops: 1, ConstraintViolations: 6}
+	if evaluation != wantEvaluation {
+		t.Errorf("expected evaluation %v, got %v", wantEvaluation, evaluation)
+	}
+}
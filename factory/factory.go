@@ -22,11 +22,29 @@ func NewModel(
 	if err != nil {
 		return nil, err
 	}
+	unplannable, err := analyzeInput(input, modelOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	priorities, err := parseObjectivePriorities(modelOptions.Objectives.Priorities)
+	if err != nil {
+		return nil, err
+	}
 
 	model, err := nextroute.NewModel()
 	if err != nil {
 		return nil, err
 	}
+	if len(unplannable) > 0 || len(priorities) > 0 {
+		data, err := getModelData(model)
+		if err != nil {
+			return nil, err
+		}
+		data.unplannable = unplannable
+		data.objectivePriorities = priorities
+		model.SetData(data)
+	}
 
 	for _, modifier := range getModifiersFromOptions(modelOptions) {
 		if model, err = modifier(input, model, modelOptions); err != nil {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 	"strings"
 
@@ -16,22 +17,75 @@ import (
 )
 
 // Format formats a solution in a basic format using the [schema.Output] to
-// format a solution.
+// format a solution. Returns an error if the format type is unknown, the
+// unplanned stops of a solution can not be explained or the solution can not
+// be formatted as GeoJSON.
 func Format(
 	ctx context.Context,
 	options any,
 	progressioner nextroute.Progressioner,
 	solutions ...nextroute.Solution,
-) runSchema.Output {
-	return nextroute.Format(
+) (runSchema.Output, error) {
+	if err := nextroute.ToFormatOptions(options).Validate(); err != nil {
+		return runSchema.Output{}, err
+	}
+
+	var formatErr error
+	output := nextroute.Format(
 		ctx,
 		options,
 		progressioner,
 		func(solution nextroute.Solution) any {
-			return ToSolutionOutput(solution)
+			solutionOutput := ToSolutionOutput(solution)
+			if len(solutions) > 1 {
+				solutionOutput.Pool = ToSolutionPoolOutput(solution, solutions)
+			}
+			if nextroute.ToFormatOptions(options).Explain.Unplanned && formatErr == nil {
+				explanations, err := nextroute.ExplainUnplanned(ctx, solution)
+				if err != nil {
+					formatErr = fmt.Errorf("explaining unplanned stops: %w", err)
+				} else {
+					solutionOutput.UnplannedExplanations = ToUnplannedExplanationOutputs(explanations)
+				}
+			}
+			if nextroute.ToFormatOptions(options).Type == nextroute.FormatTypeGeoJSON {
+				collection, err := ToFeatureCollection(solutionOutput)
+				if err != nil && formatErr == nil {
+					formatErr = err
+				}
+				return collection
+			}
+			return solutionOutput
 		},
 		solutions...,
 	)
+	if formatErr != nil {
+		return runSchema.Output{}, formatErr
+	}
+	return output, nil
+}
+
+// ToSolutionPoolOutput returns the rank of the solution in the given ranked
+// solutions and its similarity to each of them. The similarity is one minus
+// the [nextroute.AssignmentDistance]. Returns nil if the solution is not part
+// of the ranked solutions.
+func ToSolutionPoolOutput(
+	solution nextroute.Solution,
+	rankedSolutions nextroute.Solutions,
+) *schema.SolutionPoolOutput {
+	rank := slices.Index(rankedSolutions, solution)
+	if rank < 0 {
+		return nil
+	}
+	return &schema.SolutionPoolOutput{
+		Rank: rank + 1,
+		Similarities: common.Map(
+			rankedSolutions,
+			func(other nextroute.Solution) float64 {
+				return 1.0 - nextroute.AssignmentDistance(solution, other)
+			},
+		),
+	}
 }
 
 // toSolutionOutputStops converts a solution plan unit to a slice of
@@ -56,6 +110,56 @@ func toSolutionOutputStops(solutionPlanUnit nextroute.SolutionPlanUnit) []schema
 	return []schema.StopOutput{}
 }
 
+// toModelStops returns the stops of a model plan unit which are reported as
+// unplanned stops, see toSolutionOutputStops.
+func toModelStops(modelPlanUnit nextroute.ModelPlanUnit) []nextroute.ModelStop {
+	switch v := modelPlanUnit.(type) {
+	case nextroute.ModelPlanStopsUnit:
+		return v.Stops()
+	case nextroute.ModelPlanUnitsUnit:
+		if v.PlanAll() {
+			return common.MapSlice(v.PlanUnits(), toModelStops)
+		}
+	}
+	return []nextroute.ModelStop{}
+}
+
+// ToUnplannedExplanationOutputs converts the explanations of the unplanned
+// plan units to an explanation of each unplanned stop, sorted by stop ID.
+func ToUnplannedExplanationOutputs(
+	explanations nextroute.UnplannedExplanations,
+) []schema.UnplannedExplanationOutput {
+	outputs := make([]schema.UnplannedExplanationOutput, 0, len(explanations))
+	for _, explanation := range explanations {
+		summary := explanation.String()
+		vehicles := common.Map(
+			explanation.Vehicles,
+			func(vehicle nextroute.VehicleUnplannedExplanation) schema.VehicleExplanationOutput {
+				output := schema.VehicleExplanationOutput{
+					VehicleID:   vehicle.Vehicle.ID(),
+					Constraints: vehicle.Constraints,
+				}
+				if vehicle.Feasible {
+					deltaObjective := vehicle.DeltaScore
+					output.DeltaObjective = &deltaObjective
+				}
+				return output
+			},
+		)
+		for _, stop := range toModelStops(explanation.PlanUnit) {
+			outputs = append(outputs, schema.UnplannedExplanationOutput{
+				StopID:   stop.ID(),
+				Summary:  summary,
+				Vehicles: vehicles,
+			})
+		}
+	}
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return outputs[i].StopID < outputs[j].StopID
+	})
+	return outputs
+}
+
 // ToSolutionOutput converts a solution to a [schema.SolutionOutput].
 func ToSolutionOutput(solution nextroute.Solution) schema.SolutionOutput {
 	unplannedStops := common.MapSlice(
@@ -66,7 +170,7 @@ func ToSolutionOutput(solution nextroute.Solution) schema.SolutionOutput {
 		return unplannedStops[i].ID < unplannedStops[j].ID
 	})
 
-	return schema.SolutionOutput{
+	solutionOutput := schema.SolutionOutput{
 		Unplanned: unplannedStops,
 		Vehicles: common.Map(
 			solution.Vehicles(),
@@ -74,6 +178,11 @@ func ToSolutionOutput(solution nextroute.Solution) schema.SolutionOutput {
 		),
 		Objective: toObjectiveOutput(solution),
 	}
+	if data, err := getModelData(solution.Model()); err == nil {
+		solutionOutput.Unplannable = data.unplannable
+	}
+
+	return solutionOutput
 }
 
 func toStopOutput(modelStop nextroute.ModelStop) schema.StopOutput {
@@ -81,12 +190,17 @@ func toStopOutput(modelStop nextroute.ModelStop) schema.StopOutput {
 	if inputStop, ok := modelStop.Data().(schema.Stop); ok {
 		customData = inputStop.CustomData
 	}
+	location := schema.Location{
+		Lon: modelStop.Location().Longitude(),
+		Lat: modelStop.Location().Latitude(),
+	}
+	if modelStop.Location().IsPlanar() {
+		x, y := modelStop.Location().X(), modelStop.Location().Y()
+		location = schema.Location{X: &x, Y: &y}
+	}
 	return schema.StopOutput{
-		ID: modelStop.ID(),
-		Location: schema.Location{
-			Lon: modelStop.Location().Longitude(),
-			Lat: modelStop.Location().Latitude(),
-		},
+		ID:         modelStop.ID(),
+		Location:   location,
 		CustomData: customData,
 	}
 }
@@ -228,21 +342,33 @@ func toVehicleOutput(vehicle nextroute.SolutionVehicle) schema.VehicleOutput {
 }
 
 func toObjectiveOutput(solution nextroute.Solution) schema.ObjectiveOutput {
-	return schema.ObjectiveOutput{
-		Name: fmt.Sprintf("%v", solution.Model().Objective()),
+	objective := solution.Model().Objective()
+	output := schema.ObjectiveOutput{
+		Name: fmt.Sprintf("%v", objective),
 		Objectives: common.Map(
-			solution.Model().Objective().Terms(),
+			objective.Terms(),
 			func(modelObjectiveTerm nextroute.ModelObjectiveTerm) schema.ObjectiveOutput {
 				return schema.ObjectiveOutput{
-					Name:   fmt.Sprintf("%v", modelObjectiveTerm.Objective()),
-					Factor: modelObjectiveTerm.Factor(),
-					Base:   solution.ObjectiveValue(modelObjectiveTerm.Objective()) / modelObjectiveTerm.Factor(),
-					Value:  solution.ObjectiveValue(modelObjectiveTerm.Objective()),
+					Name:     fmt.Sprintf("%v", modelObjectiveTerm.Objective()),
+					Factor:   modelObjectiveTerm.Factor(),
+					Base:     solution.ObjectiveValue(modelObjectiveTerm.Objective()) / modelObjectiveTerm.Factor(),
+					Value:    solution.ObjectiveValue(modelObjectiveTerm.Objective()),
+					Priority: modelObjectiveTerm.Priority(),
 				}
 			},
 		),
-		Value: solution.ObjectiveValue(solution.Model().Objective()),
+		Value: solution.ObjectiveValue(objective),
+	}
+	if objective.IsLexicographic() {
+		scores := solution.Scores()
+		for idx, priority := range objective.Priorities() {
+			output.Tiers = append(output.Tiers, schema.ObjectiveTierOutput{
+				Priority: priority,
+				Value:    scores[idx],
+			})
+		}
 	}
+	return output
 }
 
 // DefaultCustomResultStatistics creates default custom statistics for a given
@@ -0,0 +1,138 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"errors"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// Layers of the features of the GeoJSON output, set as the property `layer`
+// of each feature.
+const (
+	// GeoJSONRouteLayer holds a line string per vehicle route.
+	GeoJSONRouteLayer = "route"
+	// GeoJSONStopLayer holds a point per planned stop.
+	GeoJSONStopLayer = "stop"
+	// GeoJSONUnplannedLayer holds a point per unplanned stop.
+	GeoJSONUnplannedLayer = "unplanned"
+)
+
+// errPlanarGeoJSON is returned when converting a solution with planar
+// locations to GeoJSON.
+var errPlanarGeoJSON = errors.New(
+	"geojson format requires locations with longitude and latitude, " +
+		"planar locations are not supported",
+)
+
+// ToFeatureCollection converts a solution output to a GeoJSON feature
+// collection. Each vehicle route is a line string, each planned stop a point
+// with the arrival and departure times and each unplanned stop a point. The
+// property `layer` of a feature distinguishes routes, planned stops and
+// unplanned stops. Vehicles without a route are not part of the collection.
+// GeoJSON positions are longitude and latitude, an error is returned if the
+// solution has planar locations.
+func ToFeatureCollection(
+	solutionOutput schema.SolutionOutput,
+) (schema.FeatureCollection, error) {
+	for _, vehicle := range solutionOutput.Vehicles {
+		for _, stop := range vehicle.Route {
+			if stop.Stop.Location.IsPlanar() {
+				return schema.FeatureCollection{}, errPlanarGeoJSON
+			}
+		}
+	}
+	for _, stop := range solutionOutput.Unplanned {
+		if stop.Location.IsPlanar() {
+			return schema.FeatureCollection{}, errPlanarGeoJSON
+		}
+	}
+
+	features := make([]schema.Feature, 0)
+	for _, vehicle := range solutionOutput.Vehicles {
+		if len(vehicle.Route) < 2 {
+			continue
+		}
+		coordinates := make([][2]float64, len(vehicle.Route))
+		for i, stop := range vehicle.Route {
+			coordinates[i] = toPosition(stop.Stop.Location)
+		}
+		features = append(features, schema.Feature{
+			Type: schema.GeoJSONFeature,
+			Geometry: schema.Geometry{
+				Type:        schema.GeoJSONLineString,
+				Coordinates: coordinates,
+			},
+			Properties: map[string]any{
+				"layer":                  GeoJSONRouteLayer,
+				"vehicle_id":             vehicle.ID,
+				"route_duration":         vehicle.RouteDuration,
+				"route_travel_duration":  vehicle.RouteTravelDuration,
+				"route_travel_distance":  vehicle.RouteTravelDistance,
+				"route_stops_duration":   vehicle.RouteStopsDuration,
+				"route_waiting_duration": vehicle.RouteWaitingDuration,
+			},
+		})
+	}
+
+	for _, vehicle := range solutionOutput.Vehicles {
+		if len(vehicle.Route) < 2 {
+			continue
+		}
+		for sequence, stop := range vehicle.Route {
+			properties := map[string]any{
+				"layer":            GeoJSONStopLayer,
+				"stop_id":          stop.Stop.ID,
+				"vehicle_id":       vehicle.ID,
+				"sequence":         sequence,
+				"travel_duration":  stop.TravelDuration,
+				"travel_distance":  stop.TravelDistance,
+				"waiting_duration": stop.WaitingDuration,
+				"duration":         stop.Duration,
+			}
+			if stop.ArrivalTime != nil {
+				properties["arrival_time"] = stop.ArrivalTime
+			}
+			if stop.EndTime != nil {
+				properties["departure_time"] = stop.EndTime
+			}
+			if stop.Stop.CustomData != nil {
+				properties["custom_data"] = stop.Stop.CustomData
+			}
+			features = append(features, toPointFeature(stop.Stop.Location, properties))
+		}
+	}
+
+	for _, stop := range solutionOutput.Unplanned {
+		properties := map[string]any{
+			"layer":   GeoJSONUnplannedLayer,
+			"stop_id": stop.ID,
+		}
+		if stop.CustomData != nil {
+			properties["custom_data"] = stop.CustomData
+		}
+		features = append(features, toPointFeature(stop.Location, properties))
+	}
+
+	return schema.FeatureCollection{
+		Type:     schema.GeoJSONFeatureCollection,
+		Features: features,
+	}, nil
+}
+
+// toPosition returns the longitude and latitude of the location.
+func toPosition(location schema.Location) [2]float64 {
+	return [2]float64{location.Lon, location.Lat}
+}
+
+func toPointFeature(location schema.Location, properties map[string]any) schema.Feature {
+	return schema.Feature{
+		Type: schema.GeoJSONFeature,
+		Geometry: schema.Geometry{
+			Type:        schema.GeoJSONPoint,
+			Coordinates: toPosition(location),
+		},
+		Properties: properties,
+	}
+}
@@ -0,0 +1,76 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestToFeatureCollection(t *testing.T) {
+	arrival := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	departure := arrival.Add(5 * time.Minute)
+	stop := func(id string, lon float64) schema.StopOutput {
+		return schema.StopOutput{ID: id, Location: schema.Location{Lon: lon, Lat: 1}}
+	}
+	solutionOutput := schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{
+				ID: "v1",
+				Route: []schema.PlannedStopOutput{
+					{Stop: stop("v1-start", 0)},
+					{Stop: stop("s1", 1), ArrivalTime: &arrival, EndTime: &departure},
+				},
+			},
+			{ID: "v2"},
+		},
+		Unplanned: []schema.StopOutput{stop("s2", 2)},
+	}
+
+	collection, err := ToFeatureCollection(solutionOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collection.Type != schema.GeoJSONFeatureCollection {
+		t.Errorf("expected type %v, got %v", schema.GeoJSONFeatureCollection, collection.Type)
+	}
+
+	layers := map[string]int{}
+	for _, feature := range collection.Features {
+		layers[feature.Properties["layer"].(string)]++
+	}
+	if layers[GeoJSONRouteLayer] != 1 || layers[GeoJSONStopLayer] != 2 || layers[GeoJSONUnplannedLayer] != 1 {
+		t.Fatalf("expected 1 route, 2 stops and 1 unplanned stop, got %v", layers)
+	}
+
+	route := collection.Features[0]
+	if route.Geometry.Type != schema.GeoJSONLineString ||
+		len(route.Geometry.Coordinates.([][2]float64)) != 2 {
+		t.Errorf("expected line string with 2 positions, got %v", route.Geometry)
+	}
+	planned := collection.Features[2]
+	if planned.Properties["arrival_time"] != &arrival || planned.Properties["departure_time"] != &departure {
+		t.Errorf("expected arrival and departure times, got %v", planned.Properties)
+	}
+
+	// Planar coordinates are not longitude and latitude.
+	solutionOutput.Unplanned = []schema.StopOutput{{ID: "s2", Location: *planarLocation(1, 2)}}
+	if _, err := ToFeatureCollection(solutionOutput); err == nil {
+		t.Error("expected error for planar location")
+	}
+}
+
+func TestFormatUnknownType(t *testing.T) {
+	options := struct {
+		Format nextroute.FormatOptions
+	}{
+		Format: nextroute.FormatOptions{Type: "xml"},
+	}
+	if _, err := Format(context.Background(), options, nil); err == nil {
+		t.Error("expected error for unknown format type")
+	}
+}
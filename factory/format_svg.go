@@ -0,0 +1,332 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/common"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// SVGOptions configure the rendering of a solution as SVG.
+type SVGOptions struct {
+	// Width is the width of the image in pixels, 800 if not positive.
+	Width int
+	// Height is the height of the image in pixels, 600 if not positive.
+	Height int
+	// TimeWindows annotates each planned stop with its arrival time, its
+	// time windows if known and its early or late arrival duration.
+	TimeWindows bool
+}
+
+// svgColors are the colors of the vehicles, assigned in the order of the
+// vehicles of the solution.
+var svgColors = []string{
+	"#1f77b4",
+	"#ff7f0e",
+	"#2ca02c",
+	"#d62728",
+	"#9467bd",
+	"#8c564b",
+	"#e377c2",
+	"#bcbd22",
+	"#17becf",
+	"#7f7f7f",
+}
+
+const (
+	svgDefaultWidth  = 800
+	svgDefaultHeight = 600
+	svgMargin        = 24.0
+	svgUnplanned     = "#a0a0a0"
+)
+
+// ToSVG renders a solution output as a self-contained SVG image. Each vehicle
+// route is drawn as a line in the color of the vehicle, the start and end
+// stop of a vehicle as a square and the other planned stops as circles.
+// Unplanned stops are drawn as gray crosses. Geographical locations are
+// projected with [common.WebMercator], the coordinates of planar locations
+// are used as they are. The time windows of the stops are not part of a
+// solution output, use [SolutionToSVG] to annotate them.
+func ToSVG(solutionOutput schema.SolutionOutput, options SVGOptions) string {
+	return renderSVG(solutionOutput, nil, options)
+}
+
+// SolutionToSVG renders a solution as a self-contained SVG image, see
+// [ToSVG]. If time windows are annotated, the windows of the stops are taken
+// from the model.
+func SolutionToSVG(solution nextroute.Solution, options SVGOptions) string {
+	windows := make(map[string][][2]time.Time)
+	for _, stop := range solution.Model().Stops() {
+		if stopWindows := stop.Windows(); len(stopWindows) > 0 {
+			windows[stop.ID()] = stopWindows
+		}
+	}
+	return renderSVG(ToSolutionOutput(solution), windows, options)
+}
+
+// svgPoint is the projected position of a stop.
+type svgPoint struct {
+	x, y float64
+}
+
+func renderSVG(
+	solutionOutput schema.SolutionOutput,
+	windows map[string][][2]time.Time,
+	options SVGOptions,
+) string {
+	width := float64(options.Width)
+	if options.Width <= 0 {
+		width = svgDefaultWidth
+	}
+	height := float64(options.Height)
+	if options.Height <= 0 {
+		height = svgDefaultHeight
+	}
+
+	points := make(map[*schema.StopOutput]svgPoint)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	add := func(stop *schema.StopOutput) {
+		x, y, ok := project(stop.Location)
+		if !ok {
+			return
+		}
+		points[stop] = svgPoint{x: x, y: y}
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	for v := range solutionOutput.Vehicles {
+		for s := range solutionOutput.Vehicles[v].Route {
+			add(&solutionOutput.Vehicles[v].Route[s].Stop)
+		}
+	}
+	for s := range solutionOutput.Unplanned {
+		add(&solutionOutput.Unplanned[s])
+	}
+
+	// Fit the bounding box of the stops into the image keeping the aspect
+	// ratio, the y axis of the image points down.
+	scale := math.Min(
+		(width-2*svgMargin)/(maxX-minX),
+		(height-2*svgMargin)/(maxY-minY),
+	)
+	if math.IsInf(scale, 0) || math.IsNaN(scale) {
+		scale = 1
+	}
+	offsetX := (width - (maxX-minX)*scale) / 2
+	offsetY := (height - (maxY-minY)*scale) / 2
+	position := func(stop *schema.StopOutput) (float64, float64, bool) {
+		point, ok := points[stop]
+		if !ok {
+			return 0, 0, false
+		}
+		return offsetX + (point.x-minX)*scale,
+			height - offsetY - (point.y-minY)*scale,
+			true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" `+
+			`viewBox="0 0 %s %s" font-family="sans-serif" font-size="10">`+"\n",
+		svgNumber(width),
+		svgNumber(height),
+		svgNumber(width),
+		svgNumber(height),
+	)
+	b.WriteString(`<rect width="100%" height="100%" fill="white"/>` + "\n")
+
+	b.WriteString(`<g class="routes">` + "\n")
+	for v, vehicle := range solutionOutput.Vehicles {
+		coordinates := make([]string, 0, len(vehicle.Route))
+		for s := range vehicle.Route {
+			if x, y, ok := position(&solutionOutput.Vehicles[v].Route[s].Stop); ok {
+				coordinates = append(coordinates, svgNumber(x)+","+svgNumber(y))
+			}
+		}
+		if len(coordinates) < 2 {
+			continue
+		}
+		fmt.Fprintf(
+			&b,
+			`<polyline points="%s" fill="none" stroke="%s" stroke-width="2">`+
+				`<title>%s</title></polyline>`+"\n",
+			strings.Join(coordinates, " "),
+			svgColor(v),
+			html.EscapeString(vehicle.ID),
+		)
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString(`<g class="stops">` + "\n")
+	for v, vehicle := range solutionOutput.Vehicles {
+		for s, stop := range vehicle.Route {
+			x, y, ok := position(&solutionOutput.Vehicles[v].Route[s].Stop)
+			if !ok {
+				continue
+			}
+			title := html.EscapeString(stop.Stop.ID + " (" + vehicle.ID + ")")
+			if stop.ArrivalTime != nil {
+				title += " arrival " + stop.ArrivalTime.Format(time.RFC3339)
+			}
+			if isVehicleStartOrEnd(vehicle, s) {
+				fmt.Fprintf(
+					&b,
+					`<rect x="%s" y="%s" width="10" height="10" fill="%s" `+
+						`stroke="black"><title>%s</title></rect>`+"\n",
+					svgNumber(x-5),
+					svgNumber(y-5),
+					svgColor(v),
+					title,
+				)
+			} else {
+				fmt.Fprintf(
+					&b,
+					`<circle cx="%s" cy="%s" r="4" fill="%s" stroke="white">`+
+						`<title>%s</title></circle>`+"\n",
+					svgNumber(x),
+					svgNumber(y),
+					svgColor(v),
+					title,
+				)
+			}
+			if !options.TimeWindows {
+				continue
+			}
+			if label := timeWindowLabel(stop, windows[stop.Stop.ID]); label != "" {
+				fmt.Fprintf(
+					&b,
+					`<text x="%s" y="%s" font-size="8" fill="%s">%s</text>`+"\n",
+					svgNumber(x+6),
+					svgNumber(y-6),
+					labelColor(stop),
+					html.EscapeString(label),
+				)
+			}
+		}
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString(`<g class="unplanned">` + "\n")
+	for s, stop := range solutionOutput.Unplanned {
+		x, y, ok := position(&solutionOutput.Unplanned[s])
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(
+			&b,
+			`<path d="M%s %sL%s %sM%s %sL%s %s" stroke="%s" stroke-width="2">`+
+				`<title>%s (unplanned)</title></path>`+"\n",
+			svgNumber(x-4), svgNumber(y-4), svgNumber(x+4), svgNumber(y+4),
+			svgNumber(x-4), svgNumber(y+4), svgNumber(x+4), svgNumber(y-4),
+			svgUnplanned,
+			html.EscapeString(stop.ID),
+		)
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString(`<g class="legend">` + "\n")
+	line := 0
+	for v, vehicle := range solutionOutput.Vehicles {
+		if len(vehicle.Route) == 0 {
+			continue
+		}
+		stops := 0
+		for s := range vehicle.Route {
+			if !isVehicleStartOrEnd(vehicle, s) {
+				stops++
+			}
+		}
+		line++
+		fmt.Fprintf(
+			&b,
+			`<text x="8" y="%d" fill="%s">%s (%d stops)</text>`+"\n",
+			line*12+4,
+			svgColor(v),
+			html.EscapeString(vehicle.ID),
+			stops,
+		)
+	}
+	if len(solutionOutput.Unplanned) > 0 {
+		line++
+		fmt.Fprintf(
+			&b,
+			`<text x="8" y="%d" fill="%s">unplanned (%d stops)</text>`+"\n",
+			line*12+4,
+			svgUnplanned,
+			len(solutionOutput.Unplanned),
+		)
+	}
+	b.WriteString("</g>\n")
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// project returns the position of the location on the plane of the image,
+// false if the location is invalid.
+func project(location schema.Location) (float64, float64, bool) {
+	commonLocation, err := toLocation(location)
+	if err != nil || !commonLocation.IsValid() {
+		return 0, 0, false
+	}
+	if commonLocation.IsPlanar() {
+		return commonLocation.X(), commonLocation.Y(), true
+	}
+	x, y, err := common.WebMercator(commonLocation)
+	if err != nil {
+		return 0, 0, false
+	}
+	return x, y, true
+}
+
+// timeWindowLabel returns the arrival time of the stop followed by its time
+// windows and its early or late arrival duration.
+func timeWindowLabel(stop schema.PlannedStopOutput, windows [][2]time.Time) string {
+	if stop.ArrivalTime == nil {
+		return ""
+	}
+	location := stop.ArrivalTime.Location()
+	parts := []string{stop.ArrivalTime.Format("15:04")}
+	for _, window := range windows {
+		parts = append(parts, fmt.Sprintf(
+			"[%s-%s]",
+			window[0].In(location).Format("15:04"),
+			window[1].In(location).Format("15:04"),
+		))
+	}
+	if stop.EarlyArrivalDuration > 0 {
+		parts = append(parts, "early "+
+			(time.Duration(stop.EarlyArrivalDuration)*time.Second).String())
+	}
+	if stop.LateArrivalDuration > 0 {
+		parts = append(parts, "late "+
+			(time.Duration(stop.LateArrivalDuration)*time.Second).String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// labelColor highlights the label of a stop which is arrived at late.
+func labelColor(stop schema.PlannedStopOutput) string {
+	if stop.LateArrivalDuration > 0 {
+		return "#d62728"
+	}
+	return "#333333"
+}
+
+func svgColor(vehicle int) string {
+	return svgColors[vehicle%len(svgColors)]
+}
+
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
@@ -0,0 +1,59 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestToSVG(t *testing.T) {
+	arrival := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	stop := func(id string, lon, lat float64) schema.StopOutput {
+		return schema.StopOutput{ID: id, Location: schema.Location{Lon: lon, Lat: lat}}
+	}
+	solutionOutput := schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{
+				ID: "v1",
+				Route: []schema.PlannedStopOutput{
+					{Stop: stop("v1-start", 7.0, 50.0)},
+					{Stop: stop("s1", 7.1, 50.1), ArrivalTime: &arrival, LateArrivalDuration: 300},
+					{Stop: stop("v1-end", 7.0, 50.0)},
+				},
+			},
+			{ID: "v2"},
+		},
+		Unplanned: []schema.StopOutput{stop("s<2>", 7.2, 50.2)},
+	}
+
+	svg := ToSVG(solutionOutput, SVGOptions{TimeWindows: true})
+	if err := xml.Unmarshal([]byte(svg), new(any)); err != nil {
+		t.Fatalf("expected well formed SVG, got %v", err)
+	}
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="800.0" height="600.0"`,
+		`<polyline`,
+		`<rect x=`,
+		`<circle`,
+		`<title>s&lt;2&gt; (unplanned)</title>`,
+		`09:00 late 5m0s`,
+		`v1 (1 stops)`,
+		`unplanned (1 stops)`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("expected SVG to contain %q, got\n%s", want, svg)
+		}
+	}
+	if strings.Count(svg, "<polyline") != 1 || strings.Count(svg, "<rect x=") != 2 {
+		t.Errorf("expected 1 route and 2 depots, got\n%s", svg)
+	}
+
+	if svg := ToSVG(solutionOutput, SVGOptions{}); strings.Contains(svg, "late 5m0s") {
+		t.Errorf("expected no time window annotations, got\n%s", svg)
+	}
+}
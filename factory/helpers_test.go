@@ -0,0 +1,14 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import "github.com/nextmv-io/nextroute/schema"
+
+// plannedRoute returns the route visiting the stops with the IDs in order.
+func plannedRoute(ids ...string) []schema.PlannedStopOutput {
+	stops := make([]schema.PlannedStopOutput, len(ids))
+	for i, id := range ids {
+		stops[i] = schema.PlannedStopOutput{Stop: schema.StopOutput{ID: id}}
+	}
+	return stops
+}
@@ -0,0 +1,91 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"fmt"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/common"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+const (
+	// PlanarMeasureEuclidean measures the straight line distance between
+	// planar locations.
+	PlanarMeasureEuclidean = "euclidean"
+	// PlanarMeasureManhattan measures the distance between planar locations
+	// moving only parallel to the axes.
+	PlanarMeasureManhattan = "manhattan"
+)
+
+// toLocation converts the location of the input to a location of the model.
+// A location with x and y coordinates is planar, any other location is
+// geographical.
+func toLocation(location schema.Location) (common.Location, error) {
+	if !location.IsPlanar() {
+		return common.NewLocation(location.Lon, location.Lat)
+	}
+	if location.X == nil || location.Y == nil {
+		return common.NewInvalidLocation(),
+			fmt.Errorf("planar location requires both x and y")
+	}
+	if location.Lon != 0 || location.Lat != 0 {
+		return common.NewInvalidLocation(),
+			fmt.Errorf("location can not have both lon and lat and x and y")
+	}
+	return common.NewPlanarLocation(*location.X, *location.Y)
+}
+
+// isPlanar returns true if the locations of the input are planar. Validation
+// makes sure all locations of an input are either planar or geographical.
+func isPlanar(input schema.Input) bool {
+	for _, stop := range input.Stops {
+		if stop.Location.IsPlanar() {
+			return true
+		}
+	}
+	for _, vehicle := range input.Vehicles {
+		if vehicle.StartLocation != nil && vehicle.StartLocation.IsPlanar() ||
+			vehicle.EndLocation != nil && vehicle.EndLocation.IsPlanar() {
+			return true
+		}
+	}
+	return false
+}
+
+// planarDistanceExpression returns the distance expression of the given
+// planar measure.
+func planarDistanceExpression(measure string) (nextroute.DistanceExpression, error) {
+	switch measure {
+	case PlanarMeasureEuclidean, "":
+		return nextroute.NewEuclideanExpression(), nil
+	case PlanarMeasureManhattan:
+		return nextroute.NewManhattanExpression(), nil
+	default:
+		return nil, nmerror.NewInputDataError(fmt.Errorf(
+			"unknown planar measure `%s`, must be `%s` or `%s`",
+			measure,
+			PlanarMeasureEuclidean,
+			PlanarMeasureManhattan,
+		))
+	}
+}
+
+// StraightLineDistance returns the distance between two locations, the
+// haversine distance between geographical locations and the euclidean
+// distance between planar locations.
+func StraightLineDistance(from, to schema.Location) (common.Distance, error) {
+	source, err := toLocation(from)
+	if err != nil {
+		return common.Distance{}, err
+	}
+
+	target, err := toLocation(to)
+	if err != nil {
+		return common.Distance{}, err
+	}
+
+	return common.StraightLine(source, target)
+}
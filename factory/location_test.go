@@ -0,0 +1,91 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/common"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func planarLocation(x, y float64) *schema.Location {
+	return &schema.Location{X: &x, Y: &y}
+}
+
+func TestPlanarModel(t *testing.T) {
+	speed := 1.0
+	penalty := 100000
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "s1", Location: *planarLocation(0, 0), UnplannedPenalty: &penalty},
+			{ID: "s2", Location: *planarLocation(300, 400), UnplannedPenalty: &penalty},
+			{ID: "s3", Location: *planarLocation(10, 0), UnplannedPenalty: &penalty},
+		},
+		Vehicles: []schema.Vehicle{
+			{
+				ID:            "v1",
+				Speed:         &speed,
+				StartLocation: planarLocation(1000, 1000),
+				EndLocation:   planarLocation(1000, 1000),
+			},
+		},
+	}
+
+	for measure, want := range map[string]float64{
+		PlanarMeasureEuclidean: 500,
+		PlanarMeasureManhattan: 700,
+	} {
+		options := Options{}
+		options.Objectives.UnplannedPenalty = 1
+		options.Measures.Planar = measure
+		model, err := NewModel(input, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s1, _ := model.Stop(0)
+		s2, _ := model.Stop(1)
+		if !s1.Location().IsPlanar() {
+			t.Fatalf("expected planar location, got %v", s1.Location())
+		}
+		vehicleType := model.VehicleTypes()[0]
+		distance := vehicleType.Data().(vehicleTypeData).DistanceExpression
+		if got := distance.Value(vehicleType, s1, s2); got != want {
+			t.Errorf("expected %v distance %v, got %v", measure, want, got)
+		}
+
+		queries, err := nextroute.NewModelStopsDistanceQueries(model.Stops()[:3])
+		if err != nil {
+			t.Fatal(err)
+		}
+		nearest, err := queries.NearestStops(s1, 1)
+		if err != nil || len(nearest) != 1 || nearest[0].ID() != "s3" {
+			t.Errorf("expected s3 nearest to s1, got %v, %v", nearest, err)
+		}
+		within, err := queries.WithinDistanceStops(s1, common.NewDistance(20, common.Meters))
+		if err != nil || len(within) != 1 {
+			t.Errorf("expected 1 stop within 20 meters of s1, got %v, %v", within, err)
+		}
+
+		solution, err := nextroute.NewSweepSolution(context.Background(), model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(solution.UnPlannedPlanUnits().SolutionPlanUnits()) != 0 {
+			t.Errorf("expected sweep to plan all stops")
+		}
+	}
+
+	options := Options{}
+	options.Measures.Planar = "chebyshev"
+	if _, err := NewModel(input, options); err == nil {
+		t.Error("expected unknown planar measure to fail")
+	}
+
+	input.Stops[1].Location = schema.Location{Lon: 7.1, Lat: 50.1}
+	if _, err := NewModel(input, Options{}); err == nil {
+		t.Error("expected mixed planar and geographical locations to fail")
+	}
+}
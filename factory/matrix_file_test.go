@@ -0,0 +1,162 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/matrixfile"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// writeMatrixFile writes the matrix to the file with the name in the
+// directory and returns the name.
+func writeMatrixFile(t *testing.T, directory, name string, matrix [][]float64) string {
+	file, err := os.Create(filepath.Join(directory, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := matrixfile.Write(file, matrix); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestMatrixFiles(t *testing.T) {
+	// Two stops followed by the start and end of one vehicle.
+	matrix := [][]float64{
+		{0, 60, 0, 0},
+		{60, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	directory := t.TempDir()
+	durationFile := writeMatrixFile(t, directory, "duration.bin", matrix)
+	distanceFile := writeMatrixFile(t, directory, "distance.bin", matrix)
+	options := Options{}
+	options.MatrixFiles.Enable = true
+	options.MatrixFiles.Directory = directory
+
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "s1", Location: schema.Location{Lon: 7.1, Lat: 50.1}},
+			{ID: "s2", Location: schema.Location{Lon: 7.2, Lat: 50.1}},
+		},
+		Vehicles:           []schema.Vehicle{{ID: "v1"}},
+		DurationMatrixFile: durationFile,
+		DistanceMatrixFile: distanceFile,
+	}
+	model, err := NewModel(input, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s1, _ := model.Stop(0)
+	s2, _ := model.Stop(1)
+	vehicleType := model.VehicleTypes()[0]
+	if got := vehicleType.TravelDurationExpression().Value(vehicleType, s1, s2); got != 60 {
+		t.Errorf("expected travel duration 60, got %v", got)
+	}
+	distance := vehicleType.Data().(vehicleTypeData).DistanceExpression
+	if got := distance.Value(vehicleType, s1, s2); got != 60 {
+		t.Errorf("expected distance 60, got %v", got)
+	}
+
+	// Time-dependent matrices decoded from JSON reference files as well.
+	input.DurationMatrixFile = ""
+	err = json.Unmarshal([]byte(`{
+		"default_matrix_file": "`+durationFile+`",
+		"matrix_time_frames": [{
+			"start_time": "2023-01-01T08:00:00Z",
+			"end_time": "2023-01-01T09:00:00Z",
+			"matrix_file": "`+durationFile+`"
+		}]
+	}`), &input.DurationMatrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewModel(input, options); err != nil {
+		t.Fatal(err)
+	}
+
+	// The size of a matrix file is validated even if matrix validation is
+	// disabled.
+	input.DurationMatrix = nil
+	input.DurationMatrixFile = writeMatrixFile(t, directory, "small.bin", [][]float64{{0}})
+	input.DistanceMatrixFile = "missing.bin"
+	expectInvalidMatrices(t, input, options, 2)
+}
+
+func TestMatrixFilePaths(t *testing.T) {
+	// Two stops followed by the start and end of one vehicle.
+	matrix := [][]float64{
+		{0, 60, 0, 0},
+		{60, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	parent := t.TempDir()
+	directory := filepath.Join(parent, "matrices")
+	if err := os.Mkdir(directory, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeMatrixFile(t, parent, "outside.bin", matrix)
+
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "s1", Location: schema.Location{Lon: 7.1, Lat: 50.1}},
+			{ID: "s2", Location: schema.Location{Lon: 7.2, Lat: 50.1}},
+		},
+		Vehicles:           []schema.Vehicle{{ID: "v1"}},
+		DurationMatrixFile: writeMatrixFile(t, directory, "inside.bin", matrix),
+	}
+
+	// Matrix files are not read unless they are enabled.
+	expectInvalidMatrices(t, input, Options{}, 1)
+
+	options := Options{}
+	options.MatrixFiles.Enable = true
+	options.MatrixFiles.Directory = directory
+	if _, err := NewModel(input, options); err != nil {
+		t.Fatal(err)
+	}
+
+	// Absolute paths and paths leading out of the directory are only
+	// allowed if the options allow any path.
+	paths := []string{
+		filepath.Join(parent, "outside.bin"),
+		filepath.Join("..", "outside.bin"),
+	}
+	for _, path := range paths {
+		input.DurationMatrixFile = path
+		expectInvalidMatrices(t, input, options, 1)
+	}
+	options.MatrixFiles.AllowAnyPath = true
+	for _, path := range paths {
+		input.DurationMatrixFile = path
+		if _, err := NewModel(input, options); err != nil {
+			t.Errorf("expected matrix file %s to be allowed, got %v", path, err)
+		}
+	}
+}
+
+// expectInvalidMatrices expects creating the model of the input to fail with
+// the number of invalid matrix validation errors.
+func expectInvalidMatrices(t *testing.T, input schema.Input, options Options, count int) {
+	t.Helper()
+	_, err := NewModel(input, options)
+	var validationErrors nmerror.ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) != count {
+		t.Fatalf("expected %v validation errors, got %v", count, err)
+	}
+	for _, validationError := range validationErrors {
+		if validationError.Code != ValidationInvalidMatrix {
+			t.Errorf("expected invalid matrix, got %+v", validationError)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/nextmv-io/nextroute/common"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/matrixfile"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// hasMatrices returns true if the input has duration or distance matrices.
+func hasMatrices(input schema.Input) bool {
+	return input.DurationMatrix != nil || input.DurationMatrixFile != "" ||
+		input.DistanceMatrix != nil || input.DistanceMatrixFile != ""
+}
+
+// selectMatrices returns the input with its duration and distance matrices
+// reduced to the given rows and columns, row i of a returned matrix is row
+// indices[i] of the matrix of the input. Matrix files are read into inline
+// matrices of the reduced size, see [matrixFilePath]. The vehicle IDs of time-dependent matrices
+// are reduced to the vehicles of the input, a matrix without vehicles left is
+// dropped.
+func selectMatrices(input schema.Input, indices []int, options Options) (schema.Input, error) {
+	if input.DistanceMatrix != nil {
+		matrix, err := selectMatrix(*input.DistanceMatrix, indices)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DistanceMatrix = &matrix
+	}
+	if input.DistanceMatrixFile != "" {
+		matrix, err := selectMatrixFile(input.DistanceMatrixFile, indices, options)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DistanceMatrix = &matrix
+		input.DistanceMatrixFile = ""
+	}
+	if input.DurationMatrixFile != "" {
+		matrix, err := selectMatrixFile(input.DurationMatrixFile, indices, options)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DurationMatrix = matrix
+		input.DurationMatrixFile = ""
+	}
+
+	vehicleIDs := make(map[string]bool, len(input.Vehicles))
+	for _, vehicle := range input.Vehicles {
+		vehicleIDs[vehicle.ID] = true
+	}
+
+	switch matrix := input.DurationMatrix.(type) {
+	case [][]float64:
+		selected, err := selectMatrix(matrix, indices)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DurationMatrix = selected
+	case schema.TimeDependentMatrix:
+		selected, err := selectTimeDependentMatrix(matrix, indices, options)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DurationMatrix = selected
+	case map[string]any:
+		var timeDependentMatrix schema.TimeDependentMatrix
+		if err := convertJSON(matrix, &timeDependentMatrix); err != nil {
+			return schema.Input{}, err
+		}
+		selected, err := selectTimeDependentMatrix(timeDependentMatrix, indices, options)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DurationMatrix = selected
+	case []schema.TimeDependentMatrix:
+		selected, err := selectTimeDependentMatrices(matrix, indices, vehicleIDs, options)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DurationMatrix = selected
+	case []any:
+		if floatMatrix, ok := common.TryAssertFloat64Matrix(matrix); ok {
+			selected, err := selectMatrix(floatMatrix, indices)
+			if err != nil {
+				return schema.Input{}, err
+			}
+			input.DurationMatrix = selected
+			break
+		}
+		var timeDependentMatrices []schema.TimeDependentMatrix
+		if err := convertJSON(matrix, &timeDependentMatrices); err != nil {
+			return schema.Input{}, err
+		}
+		selected, err := selectTimeDependentMatrices(
+			timeDependentMatrices,
+			indices,
+			vehicleIDs,
+			options,
+		)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		input.DurationMatrix = selected
+	case nil:
+	default:
+		return schema.Input{}, nmerror.NewInputDataError(
+			fmt.Errorf("invalid duration matrix type: %T", matrix),
+		)
+	}
+
+	return input, nil
+}
+
+// selectTimeDependentMatrices returns the time-dependent matrices reduced to
+// the rows and columns, see [selectMatrices].
+func selectTimeDependentMatrices(
+	matrices []schema.TimeDependentMatrix,
+	indices []int,
+	vehicleIDs map[string]bool,
+	options Options,
+) ([]schema.TimeDependentMatrix, error) {
+	selected := make([]schema.TimeDependentMatrix, 0, len(matrices))
+	for _, matrix := range matrices {
+		matrix.VehicleIDs = slices.DeleteFunc(
+			slices.Clone(matrix.VehicleIDs),
+			func(id string) bool {
+				return !vehicleIDs[id]
+			},
+		)
+		if len(matrix.VehicleIDs) == 0 {
+			continue
+		}
+		selectedMatrix, err := selectTimeDependentMatrix(matrix, indices, options)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, selectedMatrix)
+	}
+	return selected, nil
+}
+
+// selectTimeDependentMatrix returns the time-dependent matrix reduced to the
+// rows and columns, see [selectMatrices].
+func selectTimeDependentMatrix(
+	matrix schema.TimeDependentMatrix,
+	indices []int,
+	options Options,
+) (schema.TimeDependentMatrix, error) {
+	var err error
+	if matrix.DefaultMatrixFile != "" {
+		matrix.DefaultMatrix, err = selectMatrixFile(matrix.DefaultMatrixFile, indices, options)
+		matrix.DefaultMatrixFile = ""
+	} else {
+		matrix.DefaultMatrix, err = selectMatrix(matrix.DefaultMatrix, indices)
+	}
+	if err != nil {
+		return schema.TimeDependentMatrix{}, err
+	}
+
+	timeFrames := make([]schema.MatrixTimeFrame, len(matrix.MatrixTimeFrames))
+	for idx, timeFrame := range matrix.MatrixTimeFrames {
+		switch {
+		case timeFrame.MatrixFile != "":
+			timeFrame.Matrix, err = selectMatrixFile(timeFrame.MatrixFile, indices, options)
+			timeFrame.MatrixFile = ""
+		case timeFrame.Matrix != nil:
+			timeFrame.Matrix, err = selectMatrix(timeFrame.Matrix, indices)
+		}
+		if err != nil {
+			return schema.TimeDependentMatrix{}, err
+		}
+		timeFrames[idx] = timeFrame
+	}
+	matrix.MatrixTimeFrames = timeFrames
+
+	return matrix, nil
+}
+
+// selectMatrix returns the matrix reduced to the rows and columns, see
+// [selectMatrices].
+func selectMatrix(matrix [][]float64, indices []int) ([][]float64, error) {
+	size := len(matrix)
+	for _, row := range matrix {
+		size = min(size, len(row))
+	}
+	return selectCosts(
+		size,
+		func(from, to int) float64 {
+			return matrix[from][to]
+		},
+		indices,
+	)
+}
+
+// selectMatrixFile reads the rows and columns of the matrix file into an
+// inline matrix, see [selectMatrices].
+func selectMatrixFile(file string, indices []int, options Options) ([][]float64, error) {
+	path, err := matrixFilePath(file, options)
+	if err != nil {
+		return nil, nmerror.NewInputDataError(err)
+	}
+	matrix, err := matrixfile.Open(path)
+	if err != nil {
+		return nil, nmerror.NewInputDataError(err)
+	}
+	defer matrix.Close()
+
+	return selectCosts(
+		min(matrix.Rows(), matrix.Columns()),
+		matrix.Cost,
+		indices,
+	)
+}
+
+// selectCosts returns the costs between the given rows and columns of a
+// matrix of the given size.
+func selectCosts(
+	size int,
+	cost func(from, to int) float64,
+	indices []int,
+) ([][]float64, error) {
+	for _, index := range indices {
+		if index >= size {
+			return nil, nmerror.NewInputDataError(fmt.Errorf(
+				"matrix of size %v has no row and column %v, the size must be "+
+					"the number of stops plus the number of vehicles times 2",
+				size,
+				index,
+			))
+		}
+	}
+
+	selected := make([][]float64, len(indices))
+	for i, from := range indices {
+		selected[i] = make([]float64, len(indices))
+		for j, to := range indices {
+			selected[i][j] = cost(from, to)
+		}
+	}
+	return selected, nil
+}
+
+// convertJSON converts the decoded JSON value to the target by encoding and
+// decoding it again.
+func convertJSON(value any, target any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return nmerror.NewInputDataError(err)
+	}
+	return nil
+}
@@ -38,6 +38,7 @@ type Options struct {
 		UnplannedPenalty         float64 `json:"unplanned_penalty" usage:"factor to weigh the unplanned objective" default:"1.0"`
 		Cluster                  float64 `json:"cluster" usage:"factor to weigh the cluster objective" default:"0.0"`
 		StopBalance              float64 `json:"stop_balance" usage:"factor to weigh the stop balance objective" default:"0.0"`
+		Priorities               string  `json:"priorities" usage:"lexicographic priorities of the objectives, provide pairs 'name=priority' separated by ';' using the names of the objective options, lower priorities are optimized first, objectives without a priority have priority 0" default:""`
 	} `json:"objectives"`
 	Properties struct {
 		Disable struct {
@@ -47,6 +48,14 @@ type Options struct {
 			InitialSolution         bool `json:"initial_solution" usage:"ignore the initial solution"`
 		} `json:"disable"`
 	} `json:"properties"`
+	Measures struct {
+		Planar string `json:"planar" usage:"measure of the distance between planar locations without a distance matrix, euclidean or manhattan" default:"euclidean"`
+	} `json:"measures"`
+	MatrixFiles struct {
+		Enable       bool   `json:"enable" usage:"allow the input to reference matrix files, read from the matrix files directory" default:"false"`
+		Directory    string `json:"directory" usage:"directory the matrix file paths of the input are relative to, the working directory if empty" default:""`
+		AllowAnyPath bool   `json:"allow_any_path" usage:"allow absolute matrix file paths and paths leading out of the matrix files directory" default:"false"`
+	} `json:"matrix_files"`
 	Validate struct {
 		Disable struct {
 			StartTime bool `json:"start_time" usage:"disable the start time validation" default:"false"`
@@ -55,6 +64,8 @@ type Options struct {
 		Enable struct {
 			Matrix                   bool `json:"matrix" usage:"enable matrix validation" default:"false"`
 			MatrixAsymmetryTolerance int  `json:"matrix_asymmetry_tolerance" usage:"percentage of acceptable matrix asymmetry, requires matrix validation enabled" default:"20"`
+			Analysis                 bool `json:"analysis" usage:"enable the analysis of stops which provably can not be planned before solving" default:"false"`
+			AnalysisFailFast         bool `json:"analysis_fail_fast" usage:"fail if the analysis finds stops which can not be planned instead of continuing, requires analysis enabled" default:"false"`
 		} `json:"enable"`
 	} `json:"validate"`
 }
@@ -31,9 +31,12 @@ func addActivationPenaltyObjective(
 		return model, nil
 	}
 
-	_, err := model.
-		Objective().
-		NewTerm(options.Objectives.VehicleActivationPenalty, nextroute.NewVehiclesObjective(activationPenalty))
+	_, err := newObjectiveTerm(
+		model,
+		"vehicle_activation_penalty",
+		options.Objectives.VehicleActivationPenalty,
+		nextroute.NewVehiclesObjective(activationPenalty),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -245,7 +245,7 @@ func addMaximumObjectives(
 			return nil, nil, nil, nil, err
 		}
 		maximum.(nextroute.Identifier).SetID("capacity_" + name)
-		_, err = model.Objective().NewTerm(capacityObjective.Factor, maximum)
+		_, err = newObjectiveTerm(model, "capacities", capacityObjective.Factor, maximum)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
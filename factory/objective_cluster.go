@@ -17,7 +17,7 @@ func addClusterObjective(
 	if err != nil {
 		return model, err
 	}
-	if _, err = model.Objective().NewTerm(options.Objectives.Cluster, cluster); err != nil {
+	if _, err = newObjectiveTerm(model, "cluster", options.Objectives.Cluster, cluster); err != nil {
 		return nil, err
 	}
 	return model, nil
@@ -58,7 +58,12 @@ func addEarlinessObjective(
 		return nil, err
 	}
 
-	_, err = model.Objective().NewTerm(options.Objectives.EarlyArrivalPenalty, earlinessObjective)
+	_, err = newObjectiveTerm(
+		model,
+		"early_arrival_penalty",
+		options.Objectives.EarlyArrivalPenalty,
+		earlinessObjective,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +195,12 @@ func addLatenessObjective(
 		return model, nil
 	}
 
-	_, err = model.Objective().NewTerm(options.Objectives.LateArrivalPenalty, latenessObjective)
+	_, err = newObjectiveTerm(
+		model,
+		"late_arrival_penalty",
+		options.Objectives.LateArrivalPenalty,
+		latenessObjective,
+	)
 	if err != nil {
 		return nil, err
 	}
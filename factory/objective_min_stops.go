@@ -39,12 +39,12 @@ func addMinStopsObjective(
 		return model, nil
 	}
 
-	_, err := model.
-		Objective().
-		NewTerm(
-			options.Objectives.MinStops,
-			nextroute.NewMinStopsObjective(minStops, minStopsPenalty),
-		)
+	_, err := newObjectiveTerm(
+		model,
+		"min_stops",
+		options.Objectives.MinStops,
+		nextroute.NewMinStopsObjective(minStops, minStopsPenalty),
+	)
 	if err != nil {
 		return nil, err
 	}
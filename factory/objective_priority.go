@@ -0,0 +1,90 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nextmv-io/nextroute"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+)
+
+// objectivePriorityNames are the names of the objectives that can be given a
+// priority, they match the JSON names of the objective options.
+var objectivePriorityNames = []string{
+	"capacities",
+	"cluster",
+	"early_arrival_penalty",
+	"late_arrival_penalty",
+	"min_stops",
+	"stop_balance",
+	"travel_duration",
+	"unplanned_penalty",
+	"vehicle_activation_penalty",
+	"vehicles_duration",
+}
+
+// parseObjectivePriorities parses the priorities of the objectives given as
+// pairs 'name=priority' separated by ';'.
+func parseObjectivePriorities(priorities string) (map[string]int, error) {
+	parsed := map[string]int{}
+	if priorities == "" {
+		return parsed, nil
+	}
+
+	for _, definition := range strings.Split(priorities, ";") {
+		tokens := strings.Split(definition, "=")
+		if len(tokens) != 2 {
+			return nil, nmerror.NewInputDataError(fmt.Errorf(
+				"objective priority '%s' is not a valid priority definition,"+
+					" should be in the form 'name=priority'",
+				definition,
+			))
+		}
+		name := strings.TrimSpace(tokens[0])
+		if !slices.Contains(objectivePriorityNames, name) {
+			return nil, nmerror.NewInputDataError(fmt.Errorf(
+				"objective priority '%s' refers to unknown objective '%s',"+
+					" valid objectives are [%s]",
+				definition,
+				name,
+				strings.Join(objectivePriorityNames, ", "),
+			))
+		}
+		if _, ok := parsed[name]; ok {
+			return nil, nmerror.NewInputDataError(fmt.Errorf(
+				"objective priority for '%s' is defined more than once",
+				name,
+			))
+		}
+		priority, err := strconv.Atoi(strings.TrimSpace(tokens[1]))
+		if err != nil {
+			return nil, nmerror.NewInputDataError(fmt.Errorf(
+				"objective priority '%s' for '%s' is not a valid integer, %w",
+				tokens[1],
+				name,
+				err,
+			))
+		}
+		parsed[name] = priority
+	}
+	return parsed, nil
+}
+
+// newObjectiveTerm adds the objective to the model at the priority configured
+// for the objective with the given name, see [NewModel].
+func newObjectiveTerm(
+	model nextroute.Model,
+	name string,
+	factor float64,
+	objective nextroute.ModelObjective,
+) (nextroute.ModelObjectiveTerm, error) {
+	data, err := getModelData(model)
+	if err != nil {
+		return nil, err
+	}
+	return model.Objective().NewPriorityTerm(data.objectivePriorities[name], factor, objective)
+}
@@ -14,7 +14,7 @@ func addStopBalanceObjective(
 	options Options,
 ) (nextroute.Model, error) {
 	balance := nextroute.NewStopBalanceObjective()
-	if _, err := model.Objective().NewTerm(options.Objectives.StopBalance, balance); err != nil {
+	if _, err := newObjectiveTerm(model, "stop_balance", options.Objectives.StopBalance, balance); err != nil {
 		return nil, err
 	}
 	return model, nil
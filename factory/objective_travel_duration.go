@@ -14,7 +14,7 @@ func addTravelDurationObjective(
 	options Options,
 ) (nextroute.Model, error) {
 	o := nextroute.NewTravelDurationObjective()
-	_, err := model.Objective().NewTerm(options.Objectives.TravelDuration, o)
+	_, err := newObjectiveTerm(model, "travel_duration", options.Objectives.TravelDuration, o)
 	if err != nil {
 		return nil, err
 	}
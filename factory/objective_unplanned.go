@@ -36,7 +36,12 @@ func addUnplannedObjective(
 	}
 
 	unplannedObjective := nextroute.NewUnPlannedObjective(unplannedPenalty)
-	_, err = model.Objective().NewTerm(options.Objectives.UnplannedPenalty, unplannedObjective)
+	_, err = newObjectiveTerm(
+		model,
+		"unplanned_penalty",
+		options.Objectives.UnplannedPenalty,
+		unplannedObjective,
+	)
 	if err != nil {
 		return nil, err
 	}
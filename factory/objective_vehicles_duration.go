@@ -15,7 +15,7 @@ func addVehiclesDurationObjective(
 	options Options,
 ) (nextroute.Model, error) {
 	o := nextroute.NewVehiclesDurationObjective()
-	_, err := model.Objective().NewTerm(options.Objectives.VehiclesDuration, o)
+	_, err := newObjectiveTerm(model, "vehicles_duration", options.Objectives.VehiclesDuration, o)
 	if err != nil {
 		return nil, err
 	}
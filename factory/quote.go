@@ -0,0 +1,193 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/common"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// Quoter quotes the insertion of new stops into the routes of a solution of
+// an input. The model and the warm start are created once, with a spare stop
+// created from a template stop. Each quote sets the spare stop to the quoted
+// stop, see [nextroute.SetQuotingStop], instead of creating a new model. The
+// spare stop takes the ID of the quoted stop, but the stops of the model are
+// still looked up by the ID of the template, therefore quoted stops can not
+// have the ID of a stop of the input. A Quoter is not safe for concurrent use.
+type Quoter struct {
+	model     nextroute.Model
+	warmStart nextroute.Solution
+	spare     nextroute.ModelStop
+	options   Options
+}
+
+// NewQuoter creates a quoter for the solution of the input. A quoted stop
+// takes its location, duration and start time window from the quoted stop
+// and all other properties, such as the quantity or the compatibility
+// attributes, from the template. A quoted stop can only have a start time
+// window if the template has one. Inputs with matrices are not supported, the
+// matrices have no row and column for new stops.
+func NewQuoter(
+	input schema.Input,
+	options Options,
+	solution schema.SolutionOutput,
+	template schema.Stop,
+) (*Quoter, error) {
+	if hasMatrices(input) {
+		return nil, nmerror.NewInputDataError(errors.New(
+			"can not quote new stops for an input with matrices",
+		))
+	}
+
+	input.Stops = append(input.Stops[:len(input.Stops):len(input.Stops)], template)
+
+	model, err := NewModel(input, options)
+	if err != nil {
+		return nil, err
+	}
+
+	warmStart, _, err := NewWarmStartSolution(input, model, solution)
+	if err != nil {
+		return nil, err
+	}
+
+	spare, err := model.Stop(len(input.Stops) - 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := nextroute.SetQuotingStop(spare, spare.Location(), spare.Windows()); err != nil {
+		return nil, nmerror.NewInputDataError(err)
+	}
+
+	return &Quoter{
+		model:     model,
+		warmStart: warmStart,
+		spare:     spare,
+		options:   options,
+	}, nil
+}
+
+// Quote quotes the insertion of the stop, see [nextroute.QuoteInsertions].
+// Only the limit insertions with the best estimated change of the score are
+// executed, a limit of zero or less executes all allowed insertions.
+func (q *Quoter) Quote(
+	ctx context.Context,
+	stop schema.Stop,
+	limit int,
+) (nextroute.InsertionQuotes, error) {
+	data, err := getModelData(q.model)
+	if err != nil {
+		return nil, err
+	}
+	if index, ok := data.stopIDToIndex[stop.ID]; ok && index != q.spare.Index() {
+		return nil, nmerror.NewInputDataError(fmt.Errorf(
+			"can not quote stop %s, the stop is part of the input",
+			stop.ID,
+		))
+	}
+
+	location, err := toLocation(stop.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := q.windows(stop, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := nextroute.SetQuotingStop(q.spare, location, windows); err != nil {
+		return nil, nmerror.NewInputDataError(err)
+	}
+	q.spare.SetID(stop.ID)
+
+	if err := q.setDuration(stop); err != nil {
+		return nil, err
+	}
+
+	return nextroute.QuoteInsertions(
+		ctx,
+		q.warmStart,
+		q.warmStart.SolutionPlanStopsUnit(q.spare.PlanStopsUnit()),
+		limit,
+	)
+}
+
+// windows returns the start time windows of the quoted stop and sets the
+// latest start of the spare stop.
+func (q *Quoter) windows(
+	stop schema.Stop,
+	data modelData,
+) ([][2]time.Time, error) {
+	if q.options.Constraints.Disable.StartTimeWindows {
+		return nil, nil
+	}
+
+	var windows [][2]time.Time
+	if stop.StartTimeWindow != nil {
+		var err error
+		windows, err = convertTimeWindow(stop.StartTimeWindow, stop.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(windows) > 0 && data.latestStartConstraint == nil {
+		return nil, nmerror.NewInputDataError(fmt.Errorf(
+			"can not quote stop %s with a start time window, the template "+
+				"of the quoter has no start time window",
+			stop.ID,
+		))
+	}
+
+	if data.latestStartExpression != nil {
+		latestStart := q.model.MaxTime()
+		if len(windows) > 0 {
+			latestStart = windows[len(windows)-1][1]
+		}
+		data.latestStartExpression.SetTime(q.spare, latestStart)
+	}
+
+	return windows, nil
+}
+
+// setDuration sets the duration of the spare stop to the duration of the
+// quoted stop.
+func (q *Quoter) setDuration(stop schema.Stop) error {
+	if q.options.Properties.Disable.Durations {
+		return nil
+	}
+
+	duration := time.Duration(0)
+	if stop.Duration != nil {
+		duration = time.Duration(*stop.Duration) * time.Second
+	}
+
+	durationExpressions := common.UniqueDefined(
+		common.Map(
+			q.model.VehicleTypes(),
+			func(vt nextroute.ModelVehicleType) nextroute.DurationExpression {
+				return vt.DurationExpression()
+			}),
+		func(e nextroute.DurationExpression) int {
+			return e.Index()
+		},
+	)
+	for _, durationExpression := range durationExpressions {
+		durationGroupsExpression, ok := durationExpression.(DurationGroupsExpression)
+		if !ok {
+			return fmt.Errorf("process duration expression %s is not a duration group expression",
+				durationExpression.Name(),
+			)
+		}
+		durationGroupsExpression.SetStopDuration(q.spare, duration)
+	}
+
+	return nil
+}
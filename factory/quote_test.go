@@ -0,0 +1,322 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+var quoteStart = time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+
+// quoteWindow returns the start time window opening and closing the given
+// number of hours after the start of the vehicles.
+func quoteWindow(from, to int) []any {
+	return []any{
+		quoteStart.Add(time.Duration(from) * time.Hour),
+		quoteStart.Add(time.Duration(to) * time.Hour),
+	}
+}
+
+// quoteInput returns an input with a morning and an afternoon stop planned
+// on v1 and an empty vehicle v2, and a template which allows windows over the
+// whole day.
+func quoteInput() (schema.Input, schema.SolutionOutput, schema.Stop) {
+	speed := 10.0
+	start := quoteStart
+	depot := schema.Location{Lon: 7.6, Lat: 52.0}
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "morning", Location: schema.Location{Lon: 7.61, Lat: 52.0}, StartTimeWindow: quoteWindow(0, 1)},
+			{ID: "afternoon", Location: schema.Location{Lon: 7.62, Lat: 52.0}, StartTimeWindow: quoteWindow(5, 6)},
+		},
+		Vehicles: []schema.Vehicle{
+			{ID: "v1", StartLocation: &depot, StartTime: &start, Speed: &speed},
+			{ID: "v2", StartLocation: &depot, StartTime: &start, Speed: &speed},
+		},
+	}
+	solution := schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{ID: "v1", Route: plannedRoute("v1-start", "morning", "afternoon", "v1-end")},
+		},
+	}
+	template := schema.Stop{
+		ID:              "template",
+		Location:        schema.Location{Lon: 7.605, Lat: 52.0},
+		StartTimeWindow: quoteWindow(0, 10),
+	}
+	return input, solution, template
+}
+
+func TestQuoter(t *testing.T) {
+	input, solution, template := quoteInput()
+	quoter, err := NewQuoter(input, Options{}, solution, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(input.Stops) != 2 {
+		t.Fatalf("expected input to be unchanged")
+	}
+
+	ctx := context.Background()
+	anytime := schema.Stop{ID: "anytime", Location: schema.Location{Lon: 7.615, Lat: 52.0}}
+	quotes, err := quoter.Quote(ctx, anytime, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Three positions on the route of v1 and one on the empty vehicle v2.
+	if len(quotes) != 4 {
+		t.Fatalf("expected 4 quotes, got %v", len(quotes))
+	}
+	for i := 1; i < len(quotes); i++ {
+		if quotes[i].DeltaScore < quotes[i-1].DeltaScore {
+			t.Fatalf("expected quotes ordered by delta score")
+		}
+	}
+	if len(quotes.Within(quoteStart.Add(-time.Hour), quoteStart)) != 0 {
+		t.Errorf("expected no quote before the start of the vehicles")
+	}
+
+	// The window of the evening stop makes the afternoon stop late on v1 if
+	// it is inserted before it.
+	evening := schema.Stop{
+		ID:              "evening",
+		Location:        schema.Location{Lon: 7.615, Lat: 52.0},
+		StartTimeWindow: quoteWindow(7, 8),
+	}
+	quotes, err = quoter.Quote(ctx, evening, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("expected 2 quotes, got %v", len(quotes))
+	}
+	for _, quote := range quotes {
+		if quote.Vehicle.ID() == "v1" && quote.Stops[0].Previous.ID() != "afternoon" {
+			t.Errorf("expected evening after afternoon on v1, got %v", quote.Stops[0].Previous.ID())
+		}
+	}
+	if len(quotes.Within(quoteStart.Add(7*time.Hour), quoteStart.Add(8*time.Hour))) != 2 {
+		t.Errorf("expected all quotes within the window of evening, got %v", quotes)
+	}
+
+	// Only the best estimated insertion is executed.
+	quotes, err = quoter.Quote(ctx, anytime, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 {
+		t.Errorf("expected a single quote, got %v", quotes)
+	}
+
+	if _, err := quoter.Quote(ctx, schema.Stop{ID: "morning", Location: template.Location}, 0); err == nil {
+		t.Error("expected error for a stop of the input")
+	}
+
+	template.StartTimeWindow = nil
+	quoter, err = NewQuoter(input, Options{}, solution, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := quoter.Quote(ctx, evening, 0); err == nil {
+		t.Error("expected error for a start time window without a template window")
+	}
+}
+
+// TestQuoterBestMove compares the quotes of a quoter with the best move of a
+// model created with the quoted stop, for a stop with a window quoted after a
+// stop without one.
+func TestQuoterBestMove(t *testing.T) {
+	input, solution, template := quoteInput()
+	quoter, err := NewQuoter(input, Options{}, solution, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	duration := 600
+	stops := []schema.Stop{
+		{ID: "anytime", Location: schema.Location{Lon: 7.615, Lat: 52.0}},
+		{
+			ID:              "noon",
+			Location:        schema.Location{Lon: 7.608, Lat: 52.001},
+			Duration:        &duration,
+			StartTimeWindow: quoteWindow(4, 5),
+		},
+	}
+	ctx := context.Background()
+	for _, stop := range stops {
+		quotes, err := quoter.Quote(ctx, stop, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		withStop := input
+		withStop.Stops = append(input.Stops[:len(input.Stops):len(input.Stops)], stop)
+		model, err := NewModel(withStop, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		warmStart, _, err := NewWarmStartSolution(withStop, model, solution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modelStop, err := model.Stop(len(withStop.Stops) - 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		planUnit := warmStart.SolutionPlanStopsUnit(modelStop.PlanStopsUnit())
+		before := warmStart.Score()
+		if _, err := warmStart.BestMove(ctx, planUnit).Execute(ctx); err != nil {
+			t.Fatal(err)
+		}
+		planned := warmStart.SolutionStop(modelStop)
+		if !planned.IsPlanned() {
+			t.Fatalf("expected best move to plan %v", stop.ID)
+		}
+
+		found := false
+		for _, q := range quotes {
+			if q.Vehicle.ID() != planned.Vehicle().ModelVehicle().ID() ||
+				q.Stops[0].Previous.ID() != planned.Previous().ModelStop().ID() {
+				continue
+			}
+			found = true
+			if math.Abs(q.DeltaScore-(warmStart.Score()-before)) > 1e-6 {
+				t.Errorf(
+					"expected quote of %v to change the score by %v, got %v",
+					stop.ID,
+					warmStart.Score()-before,
+					q.DeltaScore,
+				)
+			}
+			if !q.Stops[0].Start.Equal(planned.Start()) || !q.Stops[0].End.Equal(planned.End()) {
+				t.Errorf(
+					"expected quote of %v from %v to %v, got %v to %v",
+					stop.ID,
+					planned.Start(),
+					planned.End(),
+					q.Stops[0].Start,
+					q.Stops[0].End,
+				)
+			}
+			if quotes[0].DeltaScore > q.DeltaScore {
+				t.Errorf("expected the best quote of %v to be at least as good as the best move", stop.ID)
+			}
+		}
+		if !found {
+			t.Errorf(
+				"expected a quote of %v after %v, got %v",
+				stop.ID,
+				planned.Previous().ModelStop().ID(),
+				quotes,
+			)
+		}
+	}
+}
+
+// randomQuoteInput returns an input of stops and vehicles at random
+// locations, with the stops distributed over the routes of the vehicles.
+func randomQuoteInput(
+	random *rand.Rand,
+	stopCount int,
+	vehicleCount int,
+) (schema.Input, schema.SolutionOutput) {
+	speed := 10.0
+	start := quoteStart
+	input := schema.Input{
+		Stops:    make([]schema.Stop, stopCount),
+		Vehicles: make([]schema.Vehicle, vehicleCount),
+	}
+	solution := schema.SolutionOutput{Vehicles: make([]schema.VehicleOutput, vehicleCount)}
+	for v := range input.Vehicles {
+		depot := randomQuoteLocation(random)
+		id := fmt.Sprintf("v%d", v)
+		input.Vehicles[v] = schema.Vehicle{ID: id, StartLocation: &depot, StartTime: &start, Speed: &speed}
+		solution.Vehicles[v] = schema.VehicleOutput{ID: id, Route: plannedRoute(id + "-start")}
+	}
+	for s := range input.Stops {
+		id := fmt.Sprintf("s%d", s)
+		input.Stops[s] = schema.Stop{ID: id, Location: randomQuoteLocation(random)}
+		vehicle := &solution.Vehicles[s%vehicleCount]
+		vehicle.Route = append(vehicle.Route, plannedRoute(id)...)
+	}
+	return input, solution
+}
+
+func randomQuoteLocation(random *rand.Rand) schema.Location {
+	return schema.Location{Lon: 7.6 + random.Float64()*0.2, Lat: 52.0 + random.Float64()*0.2}
+}
+
+// randomQuotedStop returns the i-th stop to quote, with a duration of five
+// minutes and a window opening i times twenty minutes after the start.
+func randomQuotedStop(random *rand.Rand, i int) schema.Stop {
+	duration := 300
+	return schema.Stop{
+		ID:              fmt.Sprintf("new%d", i),
+		Location:        randomQuoteLocation(random),
+		Duration:        &duration,
+		StartTimeWindow: []any{quoteStart.Add(time.Duration(i) * 20 * time.Minute), quoteStart.Add(10 * time.Hour)},
+	}
+}
+
+func TestQuoterDuration(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	input, solution := randomQuoteInput(random, 50, 5)
+	quoter, err := NewQuoter(
+		input,
+		Options{},
+		solution,
+		schema.Stop{ID: "template", Location: randomQuoteLocation(random), StartTimeWindow: quoteWindow(0, 10)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		stop := randomQuotedStop(random, i)
+		quotes, err := quoter.Quote(context.Background(), stop, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(quotes) == 0 || len(quotes) > 5 {
+			t.Fatalf("expected between 1 and 5 quotes, got %v", len(quotes))
+		}
+		opening := stop.StartTimeWindow.([]any)[0].(time.Time)
+		for _, quote := range quotes {
+			if quote.Stops[0].Start.Before(opening) {
+				t.Fatalf("expected quote to start within the window, got %v", quote.Stops[0].Start)
+			}
+			if quote.Stops[0].End.Sub(quote.Stops[0].Start) != 5*time.Minute {
+				t.Fatalf("expected duration of 5 minutes, got %v", quote.Stops[0].End.Sub(quote.Stops[0].Start))
+			}
+		}
+	}
+}
+
+func BenchmarkQuoter(b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+	input, solution := randomQuoteInput(random, 200, 10)
+	quoter, err := NewQuoter(
+		input,
+		Options{},
+		solution,
+		schema.Stop{ID: "template", Location: randomQuoteLocation(random), StartTimeWindow: quoteWindow(0, 10)},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := quoter.Quote(context.Background(), randomQuotedStop(random, i%20), 5); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
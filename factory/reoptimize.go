@@ -0,0 +1,524 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/nextmv-io/nextroute/common"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// NewReoptimizationInput creates the input to re-optimize the remainder of a
+// solution which is being executed at time now. The progress of the vehicles
+// tells which stops have been completed and where the vehicles are. The
+// returned input is the given input with:
+//
+//   - the completed stops removed and the new stops added,
+//   - the start of each vehicle moved to its current location and to now, or
+//     to its start time if it has not started yet,
+//   - the start level, the maximum and minimum number of stops, the maximum
+//     duration and the maximum distance of each vehicle reduced by what it
+//     already used,
+//   - the remaining stops of the previous solution as initial stops of their
+//     vehicles.
+//
+// A stop which has to be on the same route as a completed stop, such as the
+// delivery of a completed pickup or a stop in the same stop group, is fixed
+// as an initial stop on the vehicle which completed that stop. Precedences
+// and groups referring to completed stops are dropped otherwise.
+//
+// The duration and distance matrices of the input are reduced to the remaining
+// stops, a vehicle which completed stops starts from the row of its last
+// completed stop. Matrix files are read into inline matrices, the options
+// tell whether and from where matrix files are read. An arbitrary
+// current location of a vehicle, new stops and alternate stops are not part
+// of the matrices, they require an input without matrices.
+func NewReoptimizationInput(
+	input schema.Input,
+	previous schema.SolutionOutput,
+	now time.Time,
+	progress []schema.VehicleProgress,
+	newStops []schema.Stop,
+	options Options,
+) (schema.Input, error) {
+	matrices := hasMatrices(input)
+	if matrices {
+		if err := validateReoptimizationMatrices(input, progress, newStops); err != nil {
+			return schema.Input{}, err
+		}
+	}
+
+	reoptimized := input
+	reoptimized.Vehicles = slices.Clone(input.Vehicles)
+	reoptimized.Stops = append(slices.Clone(input.Stops), newStops...)
+	reoptimized = applyDefaults(reoptimized)
+
+	stops := make(map[string]schema.Stop, len(reoptimized.Stops))
+	for _, stop := range reoptimized.Stops {
+		if _, ok := stops[stop.ID]; ok {
+			return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+				"stop `%s` is defined more than once",
+				stop.ID,
+			))
+		}
+		stops[stop.ID] = stop
+	}
+
+	alternates := map[string]schema.AlternateStop{}
+	if reoptimized.AlternateStops != nil {
+		for _, alternate := range *reoptimized.AlternateStops {
+			alternates[alternate.ID] = alternate
+		}
+	}
+
+	vehicleIndices := make(map[string]int, len(reoptimized.Vehicles))
+	for idx, vehicle := range reoptimized.Vehicles {
+		vehicleIndices[vehicle.ID] = idx
+	}
+
+	// completed maps the ID of each completed stop to the index of the
+	// vehicle which completed it.
+	completed := map[string]int{}
+	progressByVehicle := make(map[int]schema.VehicleProgress, len(progress))
+	for _, vehicleProgress := range progress {
+		idx, ok := vehicleIndices[vehicleProgress.VehicleID]
+		if !ok {
+			return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+				"progress of vehicle `%s` which does not exist",
+				vehicleProgress.VehicleID,
+			))
+		}
+		progressByVehicle[idx] = vehicleProgress
+
+		for _, id := range vehicleProgress.CompletedStops {
+			if other, ok := completed[id]; ok {
+				return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+					"stop `%s` is completed by vehicle `%s` and vehicle `%s`",
+					id,
+					reoptimized.Vehicles[other].ID,
+					vehicleProgress.VehicleID,
+				))
+			}
+			_, isStop := stops[id]
+			if !isStop && !hasAlternateStop(reoptimized.Vehicles[idx], id) {
+				return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+					"completed stop `%s` of vehicle `%s` does not exist",
+					id,
+					vehicleProgress.VehicleID,
+				))
+			}
+			completed[id] = idx
+		}
+	}
+
+	pinned, err := pinnedStops(&reoptimized, completed)
+	if err != nil {
+		return schema.Input{}, err
+	}
+
+	remaining := make([]schema.Stop, 0, len(reoptimized.Stops))
+	for _, stop := range reoptimized.Stops {
+		if _, ok := completed[stop.ID]; ok {
+			continue
+		}
+		if stop.Precedes, err = remainingPrecedence(stop, "Precedes", completed); err != nil {
+			return schema.Input{}, err
+		}
+		if stop.Succeeds, err = remainingPrecedence(stop, "Succeeds", completed); err != nil {
+			return schema.Input{}, err
+		}
+		remaining = append(remaining, stop)
+	}
+
+	previousVehicles := make(map[string]schema.VehicleOutput, len(previous.Vehicles))
+	for _, vehicleOutput := range previous.Vehicles {
+		previousVehicles[vehicleOutput.ID] = vehicleOutput
+	}
+
+	assigned := map[string]bool{}
+	for idx, vehicle := range reoptimized.Vehicles {
+		completedStops := progressByVehicle[idx].CompletedStops
+
+		for _, id := range completedStops {
+			if _, isStop := stops[id]; !isStop {
+				// Only one of the alternate stops of a vehicle is serviced.
+				vehicle.AlternateStops = nil
+				break
+			}
+		}
+
+		fixed := map[string]bool{}
+		route := make([]string, 0)
+		if vehicle.InitialStops != nil {
+			for _, initialStop := range *vehicle.InitialStops {
+				fixed[initialStop.ID] = initialStop.Fixed != nil && *initialStop.Fixed
+				route = append(route, initialStop.ID)
+			}
+		}
+		if vehicleOutput, ok := previousVehicles[vehicle.ID]; ok {
+			route = route[:0]
+			for _, plannedStop := range vehicleOutput.Route {
+				route = append(route, plannedStop.Stop.ID)
+			}
+		}
+
+		initialStops := make([]schema.InitialStop, 0, len(route))
+		addInitialStop := func(id string, isFixed bool) {
+			assigned[id] = true
+			initialStop := schema.InitialStop{ID: id}
+			if isFixed {
+				initialStop.Fixed = &isFixed
+			}
+			initialStops = append(initialStops, initialStop)
+		}
+		for _, id := range route {
+			if _, ok := completed[id]; ok || assigned[id] {
+				continue
+			}
+			if _, isStop := stops[id]; !isStop && !hasAlternateStop(vehicle, id) {
+				continue
+			}
+			pinnedVehicle, isPinned := pinned[id]
+			if isPinned && pinnedVehicle != idx {
+				continue
+			}
+			addInitialStop(id, fixed[id] || isPinned)
+		}
+		for _, stop := range remaining {
+			if pinnedVehicle, ok := pinned[stop.ID]; ok && pinnedVehicle == idx && !assigned[stop.ID] {
+				addInitialStop(stop.ID, true)
+			}
+		}
+		vehicle.InitialStops = nil
+		if len(initialStops) > 0 {
+			vehicle.InitialStops = &initialStops
+		}
+
+		vehicle, err = advanceVehicle(
+			vehicle,
+			now,
+			progressByVehicle[idx],
+			previousVehicles[vehicle.ID],
+			stops,
+			alternates,
+		)
+		if err != nil {
+			return schema.Input{}, err
+		}
+		reoptimized.Vehicles[idx] = vehicle
+	}
+
+	reoptimized.Stops = remaining
+
+	if !matrices {
+		return reoptimized, nil
+	}
+
+	stopIndices := make(map[string]int, len(input.Stops))
+	for idx, stop := range input.Stops {
+		stopIndices[stop.ID] = idx
+	}
+	indices := make([]int, 0, len(remaining)+2*len(reoptimized.Vehicles))
+	for _, stop := range remaining {
+		indices = append(indices, stopIndices[stop.ID])
+	}
+	for idx := range reoptimized.Vehicles {
+		start := len(input.Stops) + 2*idx
+		if completedStops := progressByVehicle[idx].CompletedStops; len(completedStops) > 0 {
+			start = stopIndices[completedStops[len(completedStops)-1]]
+		}
+		indices = append(indices, start, len(input.Stops)+2*idx+1)
+	}
+
+	return selectMatrices(reoptimized, indices, options)
+}
+
+// validateReoptimizationMatrices returns an error if the progress or the new
+// stops refer to locations which are not part of the matrices of the input.
+func validateReoptimizationMatrices(
+	input schema.Input,
+	progress []schema.VehicleProgress,
+	newStops []schema.Stop,
+) error {
+	if len(newStops) > 0 {
+		return nmerror.NewInputDataError(errors.New(
+			"new stops can not be added to an input with duration or " +
+				"distance matrices, the new stops are not part of the matrices",
+		))
+	}
+	if input.AlternateStops != nil {
+		return nmerror.NewInputDataError(errors.New(
+			"re-optimization of an input with alternate stops and duration " +
+				"or distance matrices is not supported",
+		))
+	}
+	for _, vehicleProgress := range progress {
+		if vehicleProgress.Location != nil {
+			return nmerror.NewInputDataError(fmt.Errorf(
+				"the location of vehicle `%s` is not part of the duration or "+
+					"distance matrices, leave the location empty for a "+
+					"vehicle at its last completed stop",
+				vehicleProgress.VehicleID,
+			))
+		}
+	}
+	return nil
+}
+
+// pinnedStops returns for each remaining stop which has to be on the same
+// route as a completed stop the index of the vehicle which completed that
+// stop. The stop groups and duration groups of the input are reduced to the
+// remaining stops.
+func pinnedStops(
+	input *schema.Input,
+	completed map[string]int,
+) (map[string]int, error) {
+	pinned := map[string]int{}
+	pin := func(id string, vehicle int) error {
+		if other, ok := pinned[id]; ok && other != vehicle {
+			return nmerror.NewInputDataError(fmt.Errorf(
+				"stop `%s` must be on the route of vehicle `%s` and of vehicle `%s`",
+				id,
+				input.Vehicles[other].ID,
+				input.Vehicles[vehicle].ID,
+			))
+		}
+		pinned[id] = vehicle
+		return nil
+	}
+
+	for _, stop := range input.Stops {
+		sequences, err := getSequences(stop)
+		if err != nil {
+			return nil, err
+		}
+		for _, sequence := range sequences {
+			predecessorVehicle, predecessorCompleted := completed[sequence.predecessor]
+			successorVehicle, successorCompleted := completed[sequence.successor]
+			if successorCompleted && !predecessorCompleted {
+				return nil, nmerror.NewInputDataError(fmt.Errorf(
+					"stop `%s` is completed before stop `%s` which must precede it",
+					sequence.successor,
+					sequence.predecessor,
+				))
+			}
+			if successorCompleted && predecessorVehicle != successorVehicle {
+				return nil, nmerror.NewInputDataError(fmt.Errorf(
+					"stop `%s` and stop `%s` which must precede it are "+
+						"completed by different vehicles",
+					sequence.successor,
+					sequence.predecessor,
+				))
+			}
+			if predecessorCompleted && !successorCompleted {
+				if err := pin(sequence.successor, predecessorVehicle); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if input.StopGroups != nil {
+		groups := make([][]string, 0, len(*input.StopGroups))
+		for _, group := range *input.StopGroups {
+			vehicle := -1
+			remaining := make([]string, 0, len(group))
+			for _, id := range group {
+				if completedBy, ok := completed[id]; ok {
+					vehicle = completedBy
+					continue
+				}
+				remaining = append(remaining, id)
+			}
+			if vehicle >= 0 {
+				for _, id := range remaining {
+					if err := pin(id, vehicle); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if len(remaining) > 1 {
+				groups = append(groups, remaining)
+			}
+		}
+		input.StopGroups = &groups
+	}
+
+	if input.DurationGroups != nil {
+		groups := make([]schema.DurationGroup, 0, len(*input.DurationGroups))
+		for _, group := range *input.DurationGroups {
+			remaining := make([]string, 0, len(group.Group))
+			for _, id := range group.Group {
+				if _, ok := completed[id]; !ok {
+					remaining = append(remaining, id)
+				}
+			}
+			if len(remaining) > 0 {
+				groups = append(groups, schema.DurationGroup{
+					Group:    remaining,
+					Duration: group.Duration,
+				})
+			}
+		}
+		input.DurationGroups = &groups
+	}
+
+	return pinned, nil
+}
+
+// remainingPrecedence returns the "Precedes" or "Succeeds" field of the stop
+// without the completed stops. The field is returned as is if it does not
+// refer to a completed stop.
+func remainingPrecedence(
+	stop schema.Stop,
+	name string,
+	completed map[string]int,
+) (any, error) {
+	field := reflect.ValueOf(stop).FieldByName(name).Interface()
+	precedences, err := precedence(stop, name)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make([]any, 0, len(precedences))
+	for _, p := range precedences {
+		if _, ok := completed[p.id]; ok {
+			continue
+		}
+		kept = append(kept, map[string]any{"id": p.id, "direct": p.direct})
+	}
+
+	if len(kept) == len(precedences) {
+		return field, nil
+	}
+	if len(kept) == 0 {
+		return nil, nil
+	}
+
+	return kept, nil
+}
+
+// advanceVehicle moves the start of the vehicle to its current location and
+// to now, and reduces its limits by what it used for the completed stops. The
+// distance travelled is the planned distance to the last completed stop plus
+// the straight line distance from there to the current location.
+func advanceVehicle(
+	vehicle schema.Vehicle,
+	now time.Time,
+	progress schema.VehicleProgress,
+	previous schema.VehicleOutput,
+	stops map[string]schema.Stop,
+	alternates map[string]schema.AlternateStop,
+) (schema.Vehicle, error) {
+	start := now
+	if vehicle.StartTime != nil {
+		if vehicle.StartTime.After(now) {
+			start = *vehicle.StartTime
+		}
+		if vehicle.MaxDuration != nil {
+			elapsed := int(start.Sub(*vehicle.StartTime).Seconds())
+			maxDuration := max(*vehicle.MaxDuration-elapsed, 0)
+			vehicle.MaxDuration = &maxDuration
+		}
+	}
+	vehicle.StartTime = &start
+
+	// The vehicle is at its last completed stop, or at its start if it did
+	// not complete a stop, unless its current location is given.
+	completedStops := progress.CompletedStops
+	location := vehicle.StartLocation
+	travelled := 0.0
+	if len(completedStops) > 0 {
+		last := completedStops[len(completedStops)-1]
+		lastLocation := stops[last].Location
+		if _, isStop := stops[last]; !isStop {
+			lastLocation = alternates[last].Location
+		}
+		location = &lastLocation
+		for _, plannedStop := range previous.Route {
+			if plannedStop.Stop.ID == last {
+				travelled = float64(plannedStop.CumulativeTravelDistance)
+				break
+			}
+		}
+	}
+	if progress.Location != nil {
+		if location != nil {
+			distance, err := StraightLineDistance(*location, *progress.Location)
+			if err != nil {
+				return schema.Vehicle{}, nmerror.NewInputDataError(err)
+			}
+			travelled += distance.Value(common.Meters)
+		}
+		currentLocation := *progress.Location
+		location = &currentLocation
+	}
+	vehicle.StartLocation = location
+
+	if vehicle.MaxDistance != nil {
+		maxDistance := max(*vehicle.MaxDistance-int(math.Round(travelled)), 0)
+		vehicle.MaxDistance = &maxDistance
+	}
+
+	if len(completedStops) == 0 {
+		return vehicle, nil
+	}
+
+	if vehicle.MaxStops != nil {
+		maxStops := max(*vehicle.MaxStops-len(completedStops), 0)
+		vehicle.MaxStops = &maxStops
+	}
+	if vehicle.MinStops != nil {
+		minStops := max(*vehicle.MinStops-len(completedStops), 0)
+		vehicle.MinStops = &minStops
+	}
+
+	if vehicle.Capacity == nil {
+		return vehicle, nil
+	}
+
+	startLevels, err := resources(vehicle, "StartLevel", 1)
+	if err != nil {
+		return schema.Vehicle{}, err
+	}
+	levels := make(map[string]float64, len(startLevels))
+	for name, level := range startLevels {
+		levels[name] = level
+	}
+	for _, id := range completedStops {
+		var quantities map[string]float64
+		if stop, isStop := stops[id]; isStop {
+			quantities, err = resources(stop, "Quantity", -1)
+		} else {
+			quantities, err = resources(alternates[id], "Quantity", -1)
+		}
+		if err != nil {
+			return schema.Vehicle{}, err
+		}
+		for name, quantity := range quantities {
+			levels[name] += quantity
+		}
+	}
+
+	if level, ok := levels["default"]; ok && len(levels) == 1 {
+		vehicle.StartLevel = level
+	} else {
+		vehicle.StartLevel = levels
+	}
+
+	return vehicle, nil
+}
+
+// hasAlternateStop returns true if the stop with the given ID is one of the
+// alternate stops of the vehicle.
+func hasAlternateStop(vehicle schema.Vehicle, id string) bool {
+	return vehicle.AlternateStops != nil &&
+		slices.Contains(*vehicle.AlternateStops, id)
+}
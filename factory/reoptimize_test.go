@@ -0,0 +1,193 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// reoptimizationLocation returns the location of the stops of the
+// re-optimization tests, north of the depot in steps of about 1.1 km.
+func reoptimizationLocation(step int) schema.Location {
+	return schema.Location{Lon: 7.6, Lat: 52.0 + float64(step)*0.01}
+}
+
+func TestReoptimizationInput(t *testing.T) {
+	speed := 10.0
+	maxStops := 5
+	maxDistance := 10000
+	start := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+	now := start.Add(time.Hour)
+	depot := reoptimizationLocation(0)
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "pickup-a", Location: reoptimizationLocation(1), Quantity: -1, Precedes: "delivery-a"},
+			{ID: "delivery-a", Location: reoptimizationLocation(2), Quantity: 1},
+			{ID: "pickup-b", Location: reoptimizationLocation(3), Quantity: -1, Precedes: "delivery-b"},
+			{ID: "delivery-b", Location: reoptimizationLocation(4), Quantity: 1},
+		},
+		Vehicles: []schema.Vehicle{
+			{
+				ID:            "v1",
+				StartLocation: &depot,
+				StartTime:     &start,
+				Speed:         &speed,
+				Capacity:      2,
+				MaxStops:      &maxStops,
+				MaxDistance:   &maxDistance,
+			},
+			{ID: "v2", StartLocation: &depot, StartTime: &start, Speed: &speed, Capacity: 2},
+		},
+	}
+	previous := schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{
+				ID:    "v1",
+				Route: plannedRoute("v1-start", "pickup-a", "pickup-b", "delivery-a", "delivery-b", "v1-end"),
+			},
+			{ID: "v2", Route: plannedRoute("v2-start", "v2-end")},
+		},
+	}
+	previous.Vehicles[0].Route[1].CumulativeTravelDistance = 1000
+	current := schema.Location{Lon: 7.6, Lat: 52.015}
+	progress := []schema.VehicleProgress{
+		{VehicleID: "v1", CompletedStops: []string{"pickup-a"}, Location: &current},
+	}
+	newStops := []schema.Stop{{ID: "new", Location: reoptimizationLocation(5)}}
+
+	reoptimized, err := NewReoptimizationInput(input, previous, now, progress, newStops, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reoptimized.Stops) != 4 {
+		t.Fatalf("expected 4 remaining stops, got %v", len(reoptimized.Stops))
+	}
+	for _, stop := range reoptimized.Stops {
+		if stop.ID == "pickup-a" {
+			t.Fatalf("expected completed stop to be removed")
+		}
+	}
+
+	v1 := reoptimized.Vehicles[0]
+	if !v1.StartTime.Equal(now) {
+		t.Errorf("expected start time %v, got %v", now, v1.StartTime)
+	}
+	if v1.StartLocation.Lon != current.Lon {
+		t.Errorf("expected start location %v, got %v", current, v1.StartLocation)
+	}
+	if v1.StartLevel != 1.0 {
+		t.Errorf("expected start level 1, got %v", v1.StartLevel)
+	}
+	// The planned 1000 m to the first pickup and about 556 m from the pickup
+	// to the current location are used.
+	if *v1.MaxDistance < 8400 || *v1.MaxDistance > 8500 {
+		t.Errorf("expected max distance of about 8444, got %v", *v1.MaxDistance)
+	}
+	if *v1.MaxStops != 4 {
+		t.Errorf("expected max stops 4, got %v", *v1.MaxStops)
+	}
+	if v1.InitialStops == nil || len(*v1.InitialStops) != 3 {
+		t.Fatalf("expected 3 initial stops on v1, got %v", v1.InitialStops)
+	}
+	// The delivery of the completed pickup is fixed to the vehicle, the pair
+	// which has not been started can still be moved.
+	for _, initialStop := range *v1.InitialStops {
+		isFixed := initialStop.Fixed != nil && *initialStop.Fixed
+		if isFixed != (initialStop.ID == "delivery-a") {
+			t.Errorf("unexpected fixed %v of initial stop %v", isFixed, initialStop.ID)
+		}
+	}
+	if *input.Vehicles[0].StartTime != start || input.Vehicles[0].InitialStops != nil {
+		t.Errorf("expected input to be unchanged")
+	}
+
+	// The reoptimized input is a valid input.
+	model, err := NewModel(reoptimized, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(model.Vehicles()[0].Stops()) != 3 {
+		t.Errorf("expected 3 initial stops on v1 in the model")
+	}
+
+	progress = append(progress, schema.VehicleProgress{
+		VehicleID:      "v2",
+		CompletedStops: []string{"delivery-a"},
+	})
+	if _, err := NewReoptimizationInput(input, previous, now, progress, nil, Options{}); err == nil {
+		t.Errorf("expected error for delivery completed by another vehicle")
+	}
+}
+
+func TestReoptimizationInputMatrices(t *testing.T) {
+	now := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	// Rows and columns are s1, s2, s3, v1 start and v1 end.
+	matrix := [][]float64{
+		{0, 1, 2, 3, 4},
+		{5, 0, 6, 7, 8},
+		{9, 10, 0, 11, 12},
+		{13, 14, 15, 0, 16},
+		{17, 18, 19, 20, 0},
+	}
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "s1", Location: schema.Location{Lon: 0.01}},
+			{ID: "s2", Location: schema.Location{Lon: 0.02}},
+			{ID: "s3", Location: schema.Location{Lon: 0.03}},
+		},
+		Vehicles:       []schema.Vehicle{{ID: "v1"}},
+		DurationMatrix: matrix,
+		DistanceMatrix: &matrix,
+	}
+	progress := []schema.VehicleProgress{
+		{VehicleID: "v1", CompletedStops: []string{"s2"}},
+	}
+
+	reoptimized, err := NewReoptimizationInput(
+		input,
+		schema.SolutionOutput{},
+		now,
+		progress,
+		nil,
+		Options{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The vehicle starts at the row of s2, its last completed stop.
+	want := [][]float64{
+		{0, 2, 1, 4},
+		{9, 0, 10, 12},
+		{5, 6, 0, 8},
+		{17, 19, 18, 0},
+	}
+	for name, got := range map[string][][]float64{
+		"duration": reoptimized.DurationMatrix.([][]float64),
+		"distance": *reoptimized.DistanceMatrix,
+	} {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %s matrix %v, got %v", name, want, got)
+		}
+	}
+	if _, err := NewModel(reoptimized, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A current location and new stops are not part of the matrix.
+	location := schema.Location{Lon: 0.015}
+	progress[0].Location = &location
+	if _, err := NewReoptimizationInput(input, schema.SolutionOutput{}, now, progress, nil, Options{}); err == nil {
+		t.Error("expected error for a current location with matrices")
+	}
+	progress[0].Location = nil
+	newStops := []schema.Stop{{ID: "new"}}
+	if _, err := NewReoptimizationInput(input, schema.SolutionOutput{}, now, progress, newStops, Options{}); err == nil {
+		t.Error("expected error for new stops with matrices")
+	}
+}
@@ -0,0 +1,280 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/schema"
+	"github.com/nextmv-io/sdk/run"
+)
+
+// BaseScenarioName is the name of the scenario of the unmodified input.
+const BaseScenarioName = "base"
+
+// ScenarioSolution is the best solution found for a scenario.
+type ScenarioSolution struct {
+	// Scenario is the scenario which was solved.
+	Scenario schema.Scenario
+	// Solution is the best solution of the scenario.
+	Solution nextroute.Solution
+}
+
+// SolveScenarios solves the input and each of the scenarios, see
+// [ApplyScenario]. The solve of each scenario starts from the routes of the
+// best solution of the input, see [NewWarmStartSolution]. Each solve is
+// given the full duration of the solve options. The solution of the input is
+// returned first, as the scenario named [BaseScenarioName], followed by the
+// solutions of the scenarios in the given order.
+func SolveScenarios(
+	ctx context.Context,
+	input schema.Input,
+	options Options,
+	solveOptions nextroute.ParallelSolveOptions,
+	scenarios []schema.Scenario,
+) ([]ScenarioSolution, error) {
+	base, err := solveScenario(ctx, input, options, solveOptions, nil)
+	if err != nil {
+		return nil, err
+	}
+	baseOutput := ToSolutionOutput(base)
+
+	solutions := make([]ScenarioSolution, 0, len(scenarios)+1)
+	solutions = append(solutions, ScenarioSolution{
+		Scenario: schema.Scenario{Name: BaseScenarioName},
+		Solution: base,
+	})
+	for _, scenario := range scenarios {
+		scenarioInput, err := ApplyScenario(input, scenario, options)
+		if err != nil {
+			return nil, err
+		}
+		solution, err := solveScenario(
+			ctx,
+			scenarioInput,
+			options,
+			solveOptions,
+			&baseOutput,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("solving scenario `%s`: %w", scenario.Name, err)
+		}
+		solutions = append(solutions, ScenarioSolution{
+			Scenario: scenario,
+			Solution: solution,
+		})
+	}
+
+	return solutions, nil
+}
+
+// solveScenario solves the input, starting from the warm start if given.
+func solveScenario(
+	ctx context.Context,
+	input schema.Input,
+	options Options,
+	solveOptions nextroute.ParallelSolveOptions,
+	warmStart *schema.SolutionOutput,
+) (nextroute.Solution, error) {
+	model, err := NewModel(input, options)
+	if err != nil {
+		return nil, err
+	}
+
+	solver, err := nextroute.NewParallelSolver(model)
+	if err != nil {
+		return nil, err
+	}
+
+	startSolutions := make(nextroute.Solutions, 0, 1)
+	if warmStart != nil {
+		solution, _, err := NewWarmStartSolution(input, model, *warmStart)
+		if err != nil {
+			return nil, err
+		}
+		startSolutions = append(startSolutions, solution)
+	}
+
+	// The duration of the solve is measured from the start of the run.
+	ctx = context.WithValue(ctx, run.Start, time.Now())
+	solutions, err := solver.Solve(ctx, solveOptions, startSolutions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return solutions.Last()
+}
+
+// CompareScenarios compares the solution of each scenario with the first
+// solution, the solution of the base input.
+func CompareScenarios(solutions []ScenarioSolution) []schema.ScenarioOutput {
+	outputs := make([]schema.ScenarioOutput, 0, len(solutions))
+	if len(solutions) == 0 {
+		return outputs
+	}
+
+	base := solutions[0].Solution.Score()
+	for _, solution := range solutions {
+		statistics := DefaultCustomResultStatistics(solution.Solution)
+		outputs = append(outputs, schema.ScenarioOutput{
+			Name:              solution.Scenario.Name,
+			Objective:         toObjectiveOutput(solution.Solution),
+			DeltaObjective:    solution.Solution.Score() - base,
+			ActivatedVehicles: statistics.ActivatedVehicles,
+			UnplannedStops:    statistics.UnplannedStops,
+		})
+	}
+
+	return outputs
+}
+
+// ApplyScenario returns the input modified by the scenario. The vehicles of
+// the scenario are removed and added first, the capacities and end time
+// shifts apply to the remaining and added vehicles. The rows and columns of
+// removed vehicles are removed from the duration and distance matrices of the
+// input, matrix files are read as allowed by the options. Vehicles can not be
+// added if the input has matrices.
+func ApplyScenario(
+	input schema.Input,
+	scenario schema.Scenario,
+	options Options,
+) (schema.Input, error) {
+	if len(scenario.AddVehicles) > 0 && hasMatrices(input) {
+		return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+			"scenario `%s` adds vehicles, this is not supported "+
+				"for inputs with duration or distance matrices",
+			scenario.Name,
+		))
+	}
+
+	modified := input
+	modified.Vehicles = make(
+		[]schema.Vehicle,
+		0,
+		len(input.Vehicles)+len(scenario.AddVehicles),
+	)
+	for _, vehicle := range input.Vehicles {
+		if !slices.Contains(scenario.RemoveVehicles, vehicle.ID) {
+			modified.Vehicles = append(modified.Vehicles, vehicle)
+		}
+	}
+	for _, id := range scenario.RemoveVehicles {
+		if !slices.ContainsFunc(input.Vehicles, func(vehicle schema.Vehicle) bool {
+			return vehicle.ID == id
+		}) {
+			return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+				"scenario `%s` removes vehicle `%s` which does not exist",
+				scenario.Name,
+				id,
+			))
+		}
+	}
+	if len(scenario.RemoveVehicles) > 0 && hasMatrices(input) {
+		var err error
+		modified, err = selectMatrices(modified, scenarioMatrixIndices(input, scenario), options)
+		if err != nil {
+			return schema.Input{}, err
+		}
+	}
+	modified.Vehicles = append(modified.Vehicles, scenario.AddVehicles...)
+	modified = applyDefaults(modified)
+
+	vehicleIndices := make(map[string]int, len(modified.Vehicles))
+	for idx, vehicle := range modified.Vehicles {
+		if _, ok := vehicleIndices[vehicle.ID]; ok {
+			return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+				"scenario `%s` adds vehicle `%s` which already exists",
+				scenario.Name,
+				vehicle.ID,
+			))
+		}
+		vehicleIndices[vehicle.ID] = idx
+	}
+
+	for id, capacity := range scenario.Capacities {
+		idx, ok := vehicleIndices[id]
+		if !ok {
+			return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+				"scenario `%s` changes the capacity of vehicle `%s` which does not exist",
+				scenario.Name,
+				id,
+			))
+		}
+		modified.Vehicles[idx].Capacity = capacity
+	}
+
+	for id, shift := range scenario.EndTimeShifts {
+		idx, ok := vehicleIndices[id]
+		if !ok {
+			return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+				"scenario `%s` shifts the end time of vehicle `%s` which does not exist",
+				scenario.Name,
+				id,
+			))
+		}
+		if modified.Vehicles[idx].EndTime == nil {
+			return schema.Input{}, nmerror.NewInputDataError(fmt.Errorf(
+				"scenario `%s` shifts the end time of vehicle `%s` which has no end time",
+				scenario.Name,
+				id,
+			))
+		}
+		endTime := modified.Vehicles[idx].EndTime.Add(time.Duration(shift) * time.Second)
+		modified.Vehicles[idx].EndTime = &endTime
+	}
+
+	return modified, nil
+}
+
+// scenarioMatrixIndices returns the rows and columns of the matrices of the
+// input which remain after removing the vehicles of the scenario: all stops
+// and alternate stops, followed by the start and end of each remaining
+// vehicle.
+func scenarioMatrixIndices(input schema.Input, scenario schema.Scenario) []int {
+	offset := len(input.Stops)
+	if input.AlternateStops != nil {
+		offset += len(*input.AlternateStops)
+	}
+
+	indices := make([]int, 0, offset+2*len(input.Vehicles))
+	for idx := 0; idx < offset; idx++ {
+		indices = append(indices, idx)
+	}
+	for idx, vehicle := range input.Vehicles {
+		if !slices.Contains(scenario.RemoveVehicles, vehicle.ID) {
+			indices = append(indices, offset+2*idx, offset+2*idx+1)
+		}
+	}
+	return indices
+}
+
+// ReadScenarios reads the scenarios from the JSON file at the given path. The
+// file holds a list of scenarios.
+func ReadScenarios(path string) ([]schema.Scenario, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenarios []schema.Scenario
+	if err := json.Unmarshal(bytes, &scenarios); err != nil {
+		return nil, nmerror.NewInputDataError(
+			fmt.Errorf("error parsing scenarios: %w", err),
+		)
+	}
+	if len(scenarios) == 0 {
+		return nil, nmerror.NewInputDataError(
+			errors.New("scenarios file does not contain a scenario"),
+		)
+	}
+
+	return scenarios, nil
+}
@@ -0,0 +1,168 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestScenarios(t *testing.T) {
+	speed := 10.0
+	start := time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	depot := schema.Location{Lon: 7.6, Lat: 52.0}
+	// Each vehicle can carry two of the four stops. The nearest stop is about
+	// two minutes from the depot.
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "near1", Location: schema.Location{Lon: 7.6, Lat: 52.01}, Quantity: -1},
+			{ID: "near2", Location: schema.Location{Lon: 7.61, Lat: 52.01}, Quantity: -1},
+			{ID: "far1", Location: schema.Location{Lon: 7.6, Lat: 52.1}, Quantity: -1},
+			{ID: "far2", Location: schema.Location{Lon: 7.61, Lat: 52.1}, Quantity: -1},
+		},
+		Vehicles: []schema.Vehicle{
+			{ID: "v1", StartLocation: &depot, StartTime: &start, Speed: &speed, Capacity: 2, EndTime: &end},
+			{ID: "v2", StartLocation: &depot, StartTime: &start, Speed: &speed, Capacity: 2},
+		},
+	}
+
+	scenarios := []schema.Scenario{
+		{Name: "breakdown", RemoveVehicles: []string{"v2"}},
+		{Name: "short shift", EndTimeShifts: map[string]int{"v1": -3540}},
+		{
+			Name:           "replacement",
+			RemoveVehicles: []string{"v2"},
+			AddVehicles:    []schema.Vehicle{{ID: "v3", StartLocation: &depot, StartTime: &start, Speed: &speed}},
+			Capacities:     map[string]any{"v3": 2},
+		},
+	}
+
+	modified, err := ApplyScenario(input, scenarios[1], Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modified.Vehicles[0].EndTime.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected end time of v1 to be shifted, got %v", modified.Vehicles[0].EndTime)
+	}
+	modified, err = ApplyScenario(input, scenarios[2], Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modified.Vehicles) != 2 || modified.Vehicles[1].ID != "v3" || modified.Vehicles[1].Capacity != 2 {
+		t.Fatalf("expected v1 and added vehicle v3 with capacity 2, got %v", modified.Vehicles)
+	}
+	if !input.Vehicles[0].EndTime.Equal(end) || len(input.Vehicles) != 2 {
+		t.Errorf("expected input to be unchanged")
+	}
+
+	invalid := []schema.Scenario{
+		{Name: "unknown", RemoveVehicles: []string{"v4"}},
+		{Name: "duplicate", AddVehicles: []schema.Vehicle{{ID: "v1"}}},
+		{Name: "no end time", EndTimeShifts: map[string]int{"v2": 60}},
+	}
+	for _, scenario := range invalid {
+		if _, err := ApplyScenario(input, scenario, Options{}); err == nil {
+			t.Errorf("expected error for scenario %v", scenario.Name)
+		}
+	}
+
+	options := Options{}
+	options.Objectives.TravelDuration = 1
+	options.Objectives.UnplannedPenalty = 1
+	solutions, err := SolveScenarios(
+		context.Background(),
+		input,
+		options,
+		nextroute.ParallelSolveOptions{
+			Iterations:   50,
+			Duration:     time.Minute,
+			ParallelRuns: 1,
+		},
+		scenarios,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comparison := CompareScenarios(solutions)
+	if len(comparison) != 4 || comparison[0].Name != BaseScenarioName {
+		t.Fatalf("expected base and 3 scenarios, got %v", comparison)
+	}
+	if comparison[0].DeltaObjective != 0 || comparison[0].UnplannedStops != 0 {
+		t.Errorf("expected all stops planned in the base, got %v", comparison[0])
+	}
+	// The remaining vehicle can only carry two of the four stops.
+	if comparison[1].UnplannedStops != 2 || comparison[1].ActivatedVehicles != 1 {
+		t.Errorf("expected 2 unplanned stops on 1 vehicle, got %v", comparison[1])
+	}
+	if comparison[1].DeltaObjective <= 0 {
+		t.Errorf("expected a worse objective without v2, got %v", comparison[1])
+	}
+	// The shift of v1 ends before it can reach any stop.
+	if comparison[2].UnplannedStops != 2 || comparison[2].ActivatedVehicles != 1 {
+		t.Errorf("expected 2 unplanned stops on 1 vehicle, got %v", comparison[2])
+	}
+	if comparison[3].UnplannedStops != 0 {
+		t.Errorf("expected all stops planned with v3, got %v", comparison[3])
+	}
+}
+
+func TestScenarioMatrices(t *testing.T) {
+	// Rows and columns are s1, s2, v1 start, v1 end, v2 start and v2 end.
+	matrix := [][]float64{
+		{0, 1, 2, 3, 4, 5},
+		{6, 0, 7, 8, 9, 10},
+		{11, 12, 0, 13, 14, 15},
+		{16, 17, 18, 0, 19, 20},
+		{21, 22, 23, 24, 0, 25},
+		{26, 27, 28, 29, 30, 0},
+	}
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "s1", Location: schema.Location{Lon: 7.6, Lat: 52.01}},
+			{ID: "s2", Location: schema.Location{Lon: 7.6, Lat: 52.02}},
+		},
+		Vehicles:       []schema.Vehicle{{ID: "v1"}, {ID: "v2"}},
+		DurationMatrix: matrix,
+		DistanceMatrix: &matrix,
+	}
+
+	modified, err := ApplyScenario(
+		input,
+		schema.Scenario{Name: "breakdown", RemoveVehicles: []string{"v1"}},
+		Options{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]float64{
+		{0, 1, 4, 5},
+		{6, 0, 9, 10},
+		{21, 22, 0, 25},
+		{26, 27, 30, 0},
+	}
+	for name, got := range map[string][][]float64{
+		"duration": modified.DurationMatrix.([][]float64),
+		"distance": *modified.DistanceMatrix,
+	} {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %s matrix %v, got %v", name, want, got)
+		}
+	}
+	if _, err := NewModel(modified, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An added vehicle is not part of the matrices.
+	larger := schema.Scenario{Name: "larger", AddVehicles: []schema.Vehicle{{ID: "v3"}}}
+	if _, err := ApplyScenario(input, larger, Options{}); err == nil {
+		t.Error("expected error for an added vehicle with matrices")
+	}
+}
@@ -0,0 +1,312 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// The serializer names of the expressions and data of a model built by the
+// factory, see [nextroute.WriteModel].
+const (
+	durationGroupsSerializer = "factory.duration_groups"
+	modelDataSerializer      = "factory.model_data"
+	vehicleTypeSerializer    = "factory.vehicle_type_data"
+	alternateStopSerializer  = "factory.alternate_stop"
+)
+
+func init() {
+	nextroute.RegisterDeserializer(durationGroupsSerializer, deserializeDurationGroups)
+	nextroute.RegisterDeserializer(modelDataSerializer, deserializeModelData)
+	nextroute.RegisterDeserializer(vehicleTypeSerializer, deserializeVehicleTypeData)
+	nextroute.RegisterDeserializer(alternateStopSerializer, deserializeAlternateStop)
+
+	// The data of the stops and vehicles is the input they are built from,
+	// the untyped fields of the input hold the types decoded from JSON.
+	gob.Register(schema.Stop{})
+	gob.Register(schema.Vehicle{})
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+func encodeGob(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func decodeGob(data []byte, value any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// expressionAs returns the referenced expression as the given type.
+func expressionAs[T nextroute.ModelExpression](
+	decoder nextroute.ModelDecoder,
+	reference int,
+) (T, error) {
+	var zero T
+	expression, err := decoder.Expression(reference)
+	if err != nil || expression == nil {
+		return zero, err
+	}
+	typed, ok := expression.(T)
+	if !ok {
+		return zero, fmt.Errorf(
+			"expression `%s` of type %T is not of the expected type",
+			expression.Name(),
+			expression,
+		)
+	}
+	return typed, nil
+}
+
+type durationGroupsRecord struct {
+	GroupDuration []float64
+	ToGroupIndex  []int64
+	Durations     []float64
+	Stops         []int
+	GroupCount    int64
+}
+
+// SerializerName implements nextroute.Serializer.
+func (d *durationGroupDurationImpl) SerializerName() string {
+	return durationGroupsSerializer
+}
+
+// Serialize implements nextroute.Serializer.
+func (d *durationGroupDurationImpl) Serialize(_ nextroute.ModelEncoder) ([]byte, error) {
+	record := durationGroupsRecord{
+		GroupDuration: d.groupDuration,
+		ToGroupIndex:  d.toGroupIndex,
+		Durations:     d.durations,
+		Stops:         make([]int, len(d.stopIndexToStop)),
+		GroupCount:    d.groupCount,
+	}
+	for idx, stop := range d.stopIndexToStop {
+		record.Stops[idx] = -1
+		if stop != nil {
+			record.Stops[idx] = stop.Index()
+		}
+	}
+	return encodeGob(record)
+}
+
+func deserializeDurationGroups(decoder nextroute.ModelDecoder, data []byte) (any, error) {
+	var record durationGroupsRecord
+	if err := decodeGob(data, &record); err != nil {
+		return nil, err
+	}
+	stops := make([]nextroute.ModelStop, len(record.Stops))
+	for idx, index := range record.Stops {
+		if index < 0 {
+			continue
+		}
+		stop, err := decoder.Model().Stop(index)
+		if err != nil {
+			return nil, err
+		}
+		stops[idx] = stop
+	}
+	return &durationGroupDurationImpl{
+		index:           nextroute.NewModelExpressionIndex(),
+		groupDuration:   record.GroupDuration,
+		toGroupIndex:    record.ToGroupIndex,
+		durations:       record.Durations,
+		stopIndexToStop: stops,
+		groupCount:      record.GroupCount,
+	}, nil
+}
+
+type modelDataRecord struct {
+	StopIDToIndex         map[string]int
+	Sequences             []sequenceRecord
+	Groups                [][]string
+	Unplannable           []schema.UnplannableStopOutput
+	LatestEndExpression   int
+	LatestStartConstraint int
+	LatestStartExpression int
+	LatestEndConstraint   int
+	TargetTime            int
+}
+
+type sequenceRecord struct {
+	Predecessor string
+	Successor   string
+	Direct      bool
+}
+
+// SerializerName implements nextroute.Serializer.
+func (d modelData) SerializerName() string {
+	return modelDataSerializer
+}
+
+// Serialize implements nextroute.Serializer.
+func (d modelData) Serialize(encoder nextroute.ModelEncoder) ([]byte, error) {
+	record := modelDataRecord{
+		StopIDToIndex: d.stopIDToIndex,
+		Unplannable:   d.unplannable,
+	}
+	var err error
+	if record.LatestEndExpression, err = encoder.Expression(d.latestEndExpression); err != nil {
+		return nil, err
+	}
+	if record.LatestStartExpression, err = encoder.Expression(d.latestStartExpression); err != nil {
+		return nil, err
+	}
+	if record.TargetTime, err = encoder.Expression(d.targetTime); err != nil {
+		return nil, err
+	}
+	if record.LatestStartConstraint, err = encoder.Constraint(d.latestStartConstraint); err != nil {
+		return nil, err
+	}
+	if record.LatestEndConstraint, err = encoder.Constraint(d.latestEndConstraint); err != nil {
+		return nil, err
+	}
+	for _, s := range d.sequences {
+		record.Sequences = append(record.Sequences, sequenceRecord{
+			Predecessor: s.predecessor,
+			Successor:   s.successor,
+			Direct:      s.direct,
+		})
+	}
+	for _, g := range d.groups {
+		stops := make([]string, 0, len(g.stops))
+		for stop := range g.stops {
+			stops = append(stops, stop)
+		}
+		record.Groups = append(record.Groups, stops)
+	}
+	return encodeGob(record)
+}
+
+func deserializeModelData(decoder nextroute.ModelDecoder, data []byte) (any, error) {
+	var record modelDataRecord
+	if err := decodeGob(data, &record); err != nil {
+		return nil, err
+	}
+	d := modelData{
+		stopIDToIndex: record.StopIDToIndex,
+		groups:        make([]group, 0, len(record.Groups)),
+		unplannable:   record.Unplannable,
+	}
+	if d.stopIDToIndex == nil {
+		d.stopIDToIndex = make(map[string]int)
+	}
+	var err error
+	if d.latestEndExpression, err = expressionAs[nextroute.StopTimeExpression](
+		decoder,
+		record.LatestEndExpression,
+	); err != nil {
+		return nil, err
+	}
+	if d.latestStartExpression, err = expressionAs[nextroute.StopTimeExpression](
+		decoder,
+		record.LatestStartExpression,
+	); err != nil {
+		return nil, err
+	}
+	if d.targetTime, err = expressionAs[nextroute.StopTimeExpression](
+		decoder,
+		record.TargetTime,
+	); err != nil {
+		return nil, err
+	}
+	latestStart, err := decoder.Constraint(record.LatestStartConstraint)
+	if err != nil {
+		return nil, err
+	}
+	if latestStart != nil {
+		if d.latestStartConstraint, err = constraintAs[nextroute.LatestStart](latestStart); err != nil {
+			return nil, err
+		}
+	}
+	latestEnd, err := decoder.Constraint(record.LatestEndConstraint)
+	if err != nil {
+		return nil, err
+	}
+	if latestEnd != nil {
+		if d.latestEndConstraint, err = constraintAs[nextroute.LatestEnd](latestEnd); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range record.Sequences {
+		d.sequences = append(d.sequences, sequence{
+			predecessor: s.Predecessor,
+			successor:   s.Successor,
+			direct:      s.Direct,
+		})
+	}
+	for _, stops := range record.Groups {
+		g := group{stops: make(map[string]struct{}, len(stops))}
+		for _, stop := range stops {
+			g.stops[stop] = struct{}{}
+		}
+		d.groups = append(d.groups, g)
+	}
+	return d, nil
+}
+
+func constraintAs[T nextroute.ModelConstraint](constraint nextroute.ModelConstraint) (T, error) {
+	typed, ok := constraint.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("constraint of type %T is not of the expected type", constraint)
+	}
+	return typed, nil
+}
+
+// SerializerName implements nextroute.Serializer.
+func (d vehicleTypeData) SerializerName() string {
+	return vehicleTypeSerializer
+}
+
+// Serialize implements nextroute.Serializer.
+func (d vehicleTypeData) Serialize(encoder nextroute.ModelEncoder) ([]byte, error) {
+	reference, err := encoder.Expression(d.DistanceExpression)
+	if err != nil {
+		return nil, err
+	}
+	return encodeGob(reference)
+}
+
+func deserializeVehicleTypeData(decoder nextroute.ModelDecoder, data []byte) (any, error) {
+	var reference int
+	if err := decodeGob(data, &reference); err != nil {
+		return nil, err
+	}
+	distanceExpression, err := expressionAs[nextroute.DistanceExpression](decoder, reference)
+	if err != nil {
+		return nil, err
+	}
+	return vehicleTypeData{DistanceExpression: distanceExpression}, nil
+}
+
+type alternateStopRecord struct {
+	Stop  schema.AlternateStop
+	Index int
+}
+
+// SerializerName implements nextroute.Serializer.
+func (a alternateInputStop) SerializerName() string {
+	return alternateStopSerializer
+}
+
+// Serialize implements nextroute.Serializer.
+func (a alternateInputStop) Serialize(_ nextroute.ModelEncoder) ([]byte, error) {
+	return encodeGob(alternateStopRecord{Stop: a.stop, Index: a.index})
+}
+
+func deserializeAlternateStop(_ nextroute.ModelDecoder, data []byte) (any, error) {
+	var record alternateStopRecord
+	if err := decodeGob(data, &record); err != nil {
+		return nil, err
+	}
+	return alternateInputStop{index: record.Index, stop: record.Stop}, nil
+}
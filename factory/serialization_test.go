@@ -0,0 +1,158 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/schema"
+	"github.com/nextmv-io/sdk/run"
+)
+
+func TestModelSerialization(t *testing.T) {
+	files, err := filepath.Glob("../tests/golden/testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no test inputs found")
+	}
+
+	options := Options{}
+	options.Objectives.VehicleActivationPenalty = 1
+	options.Objectives.TravelDuration = 1
+	options.Objectives.VehiclesDuration = 1
+	options.Objectives.UnplannedPenalty = 1
+	options.Objectives.EarlyArrivalPenalty = 1
+	options.Objectives.LateArrivalPenalty = 1
+	options.Objectives.MinStops = 1
+	options.Objectives.StopBalance = 1
+
+	// With the cluster constraint the solver does not find the same solution
+	// on every solve of the same model, these models are compared by their
+	// start solutions only.
+	clusterOptions := options
+	clusterOptions.Constraints.Enable.Cluster = true
+
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var input schema.Input
+			if err := json.Unmarshal(data, &input); err != nil {
+				t.Fatal(err)
+			}
+			model, err := NewModel(input, options)
+			if err != nil {
+				t.Skipf("input does not build a model: %v", err)
+			}
+
+			read := readWrittenModel(t, model)
+			want, wantScore := solveSerialized(t, model)
+			got, gotScore := solveSerialized(t, read)
+			if got != want || gotScore != wantScore {
+				t.Errorf(
+					"expected solution of score %v\n%s\ngot score %v\n%s",
+					wantScore,
+					want,
+					gotScore,
+					got,
+				)
+			}
+
+			model, err = NewModel(input, clusterOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, wantScore = startSerialized(t, model)
+			got, gotScore = startSerialized(t, readWrittenModel(t, model))
+			if got != want || gotScore != wantScore {
+				t.Errorf(
+					"expected start solution with cluster constraint of score %v\n%s\ngot score %v\n%s",
+					wantScore,
+					want,
+					gotScore,
+					got,
+				)
+			}
+		})
+	}
+}
+
+func readWrittenModel(t *testing.T, model nextroute.Model) nextroute.Model {
+	var buffer bytes.Buffer
+	if err := nextroute.WriteModel(&buffer, model); err != nil {
+		t.Fatal(err)
+	}
+	read, err := nextroute.ReadModel(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.IsLocked() {
+		t.Error("expected the read model to be locked")
+	}
+
+	return read
+}
+
+func startSerialized(t *testing.T, model nextroute.Model) (string, float64) {
+	model.SetRandom(rand.New(rand.NewSource(0)))
+	solution, err := nextroute.NewSolution(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := json.Marshal(ToSolutionOutput(solution))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output), solution.Score()
+}
+
+func solveSerialized(t *testing.T, model nextroute.Model) (string, float64) {
+	model.SetRandom(rand.New(rand.NewSource(0)))
+	parameter := nextroute.IntParameterOptions{
+		StartValue:               2,
+		DeltaAfterIterations:     10,
+		Delta:                    1,
+		MinValue:                 2,
+		MaxValue:                 4,
+		SnapBackAfterImprovement: true,
+		Zigzag:                   true,
+	}
+	solver, err := nextroute.NewSolver(model, nextroute.SolverOptions{
+		Unplan:  parameter,
+		Plan:    parameter,
+		Restart: parameter,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), run.Start, time.Now())
+	solutions, err := solver.Solve(
+		ctx,
+		nextroute.SolveOptions{Iterations: 50, Duration: time.Minute},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := solutions.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := json.Marshal(ToSolutionOutput(last))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output), last.Score()
+}
@@ -4,7 +4,6 @@ package factory
 
 import (
 	"github.com/nextmv-io/nextroute"
-	"github.com/nextmv-io/nextroute/common"
 	"github.com/nextmv-io/nextroute/schema"
 )
 
@@ -19,10 +18,7 @@ func addStops(
 		return nil, err
 	}
 	for _, inputStop := range input.Stops {
-		location, err := common.NewLocation(
-			inputStop.Location.Lon,
-			inputStop.Location.Lat,
-		)
+		location, err := toLocation(inputStop.Location)
 		if err != nil {
 			return nil, err
 		}
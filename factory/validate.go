@@ -3,84 +3,204 @@
 package factory
 
 import (
-	"errors"
 	"fmt"
 	"math"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/nextmv-io/nextroute/common"
 	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/matrixfile"
 	"github.com/nextmv-io/nextroute/schema"
 )
 
-// validate the input and return an error if invalid.
+// Codes of the errors found validating the input, see
+// [nmerror.ValidationError].
+const (
+	// ValidationMissingID is the code of an entity without an id.
+	ValidationMissingID = "missing_id"
+	// ValidationDuplicateID is the code of an id used by more than one entity.
+	ValidationDuplicateID = "duplicate_id"
+	// ValidationUnknownID is the code of a reference to an id which does not
+	// exist.
+	ValidationUnknownID = "unknown_id"
+	// ValidationMissingValue is the code of a required value which is not set.
+	ValidationMissingValue = "missing_value"
+	// ValidationNegativeValue is the code of a value which must be
+	// non-negative.
+	ValidationNegativeValue = "negative_value"
+	// ValidationInvalidValue is the code of a value which can not be
+	// interpreted.
+	ValidationInvalidValue = "invalid_value"
+	// ValidationInconsistentValue is the code of a value which contradicts
+	// another value of the input.
+	ValidationInconsistentValue = "inconsistent_value"
+	// ValidationInvalidLocation is the code of an invalid location.
+	ValidationInvalidLocation = "invalid_location"
+	// ValidationInvalidTimeWindow is the code of an invalid time window.
+	ValidationInvalidTimeWindow = "invalid_time_window"
+	// ValidationInvalidMatrix is the code of an invalid distance or duration
+	// matrix.
+	ValidationInvalidMatrix = "invalid_matrix"
+)
+
+// validation collects all the errors found validating the input.
+type validation struct {
+	errors nmerror.ValidationErrors
+}
+
+// add adds an error for the value at the JSON path of the input.
+func (v *validation) add(path, code, format string, args ...any) {
+	v.errors = append(v.errors, nmerror.ValidationError{
+		Path:    path,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// addError adds an error returned converting the value at the JSON path of
+// the input.
+func (v *validation) addError(path, code string, err error) {
+	// The conversion functions return input data errors, the prefix is added
+	// once for all the errors.
+	v.add(path, code, "%s", strings.TrimPrefix(err.Error(), "input data error: "))
+}
+
+// err returns an input data error wrapping all the errors collected, nil if
+// there are none.
+func (v *validation) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return nmerror.NewInputDataError(v.errors)
+}
+
+// validate the input and return an input data error wrapping a
+// [nmerror.ValidationErrors] listing all the errors if invalid.
 func validate(input schema.Input, modelOptions Options) error {
+	if _, err := parseObjectivePriorities(modelOptions.Objectives.Priorities); err != nil {
+		return err
+	}
+	if _, err := planarDistanceExpression(modelOptions.Measures.Planar); err != nil {
+		return err
+	}
+
+	v := &validation{}
+
 	allStopIDs := map[string]bool{}
 	stopIDs := map[string]bool{}
 	alternateStopIDs := map[string]bool{}
 
 	for idx, stop := range input.Stops {
 		if stop.ID == "" {
-			return nmerror.NewInputDataError(fmt.Errorf("no id set for stop at index %v", idx))
+			v.add(
+				fmt.Sprintf("stops[%d].id", idx),
+				ValidationMissingID,
+				"no id set for stop at index %v",
+				idx,
+			)
+			continue
 		}
 		allStopIDs[stop.ID] = true
 		stopIDs[stop.ID] = true
 	}
-	duplicates := common.NotUniqueDefined(
-		input.Stops,
-		func(s schema.Stop) string {
-			return s.ID
-		},
-	)
-	if len(duplicates) != 0 {
-		return nmerror.NewInputDataError(fmt.Errorf(
-			"stop ID's are not unique, duplicate ID's are [`%v`]",
-			strings.Join(
-				common.Map(duplicates, func(s schema.Stop) string {
-					return s.ID
-				}),
-				"`, `",
-			)))
-	}
+	validateUniqueIDs(v, input.Stops, func(s schema.Stop) string {
+		return s.ID
+	}, "stops", "stop")
 
 	if input.AlternateStops != nil {
-		alternateDuplicates := common.NotUniqueDefined(
-			*input.AlternateStops,
-			func(s schema.AlternateStop) string {
-				return s.ID
-			},
-		)
-		if len(alternateDuplicates) != 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
-				"alternate stop ID's are not unique, duplicate ID's are [`%v`]",
-				strings.Join(
-					common.Map(alternateDuplicates, func(s schema.AlternateStop) string {
-						return s.ID
-					}),
-					"`, `",
-				)))
-		}
+		validateUniqueIDs(v, *input.AlternateStops, func(s schema.AlternateStop) string {
+			return s.ID
+		}, "alternate_stops", "alternate stop")
 		for idx, stop := range *input.AlternateStops {
 			if stop.ID == "" {
-				return nmerror.NewInputDataError(fmt.Errorf("empty id set for alternate stop at index %v", idx))
+				v.add(
+					fmt.Sprintf("alternate_stops[%d].id", idx),
+					ValidationMissingID,
+					"empty id set for alternate stop at index %v",
+					idx,
+				)
+				continue
 			}
 			allStopIDs[stop.ID] = true
 			alternateStopIDs[stop.ID] = true
 		}
 	}
 
-	if err := validateVehicles(input, allStopIDs); err != nil {
-		return err
-	}
-	if err := validateStops(input, allStopIDs, stopIDs, alternateStopIDs); err != nil {
-		return err
+	validateVehicles(v, input, allStopIDs)
+	validateStops(v, input, allStopIDs, stopIDs, alternateStopIDs)
+	validateCoordinateSystems(v, input)
+	validateResources(v, input, modelOptions)
+	validateConstraints(v, input, modelOptions)
+
+	return v.err()
+}
+
+// validateUniqueIDs adds an error for each entity at the JSON path whose id is
+// already used by a previous entity.
+func validateUniqueIDs[T any](
+	v *validation,
+	entities []T,
+	id func(T) string,
+	path string,
+	name string,
+) {
+	first := map[string]int{}
+	for idx, entity := range entities {
+		entityID := id(entity)
+		if entityID == "" {
+			continue
+		}
+		if firstIdx, ok := first[entityID]; ok {
+			v.add(
+				fmt.Sprintf("%s[%d].id", path, idx),
+				ValidationDuplicateID,
+				"%s ID `%s` is not unique, it is also used at %s[%d]",
+				name,
+				entityID,
+				path,
+				firstIdx,
+			)
+			continue
+		}
+		first[entityID] = idx
 	}
-	if err := validateResources(input, modelOptions); err != nil {
-		return err
+}
+
+// validateTimeWindow adds an error for each invalid time window at the JSON
+// path, the window is either a single window or a slice of windows.
+func validateTimeWindow(v *validation, path string, window any, stopID string) {
+	windows, ok := window.([]any)
+	if !ok || len(windows) == 0 {
+		if _, err := convertTimeWindow(window, stopID); err != nil {
+			v.addError(path, ValidationInvalidTimeWindow, err)
+		}
+		return
+	}
+	if _, isMultiple := windows[0].([]any); !isMultiple {
+		if _, err := convertTimeWindow(window, stopID); err != nil {
+			v.addError(path, ValidationInvalidTimeWindow, err)
+		}
+		return
+	}
+	for idx, w := range windows {
+		windowPath := fmt.Sprintf("%s[%d]", path, idx)
+		if w == nil {
+			v.add(
+				windowPath,
+				ValidationInvalidTimeWindow,
+				"window at index %d of stop %s is nil",
+				idx,
+				stopID,
+			)
+			continue
+		}
+		if _, err := convertTimeWindow(w, stopID); err != nil {
+			v.addError(windowPath, ValidationInvalidTimeWindow, err)
+		}
 	}
-	return validateConstraints(input, modelOptions)
 }
 
 func identify(input schema.Input, i int) string {
@@ -96,10 +216,7 @@ func identify(input schema.Input, i int) string {
 
 func location(input schema.Input, i int) common.Location {
 	if i < len(input.Stops) {
-		l, _ := common.NewLocation(
-			input.Stops[i].Location.Lon,
-			input.Stops[i].Location.Lat,
-		)
+		l, _ := toLocation(input.Stops[i].Location)
 		return l
 	}
 	idx := i - len(input.Stops)
@@ -109,41 +226,85 @@ func location(input schema.Input, i int) common.Location {
 		if vehicle.StartLocation == nil {
 			return common.NewInvalidLocation()
 		}
-		l, _ := common.NewLocation(
-			vehicle.StartLocation.Lon,
-			vehicle.StartLocation.Lat,
-		)
+		l, _ := toLocation(*vehicle.StartLocation)
 		return l
 	}
 	if vehicle.EndLocation == nil {
 		return common.NewInvalidLocation()
 	}
-	l, _ := common.NewLocation(
-		vehicle.EndLocation.Lon,
-		vehicle.EndLocation.Lat,
-	)
+	l, _ := toLocation(*vehicle.EndLocation)
 	return l
 }
 
+// validateCoordinateSystems adds an error for each location which is planar
+// while the first location of the input is geographical or vice versa.
+func validateCoordinateSystems(v *validation, input schema.Input) {
+	firstPath := ""
+	planar := false
+	check := func(path string, location *schema.Location) {
+		if location == nil || reflect.DeepEqual(*location, schema.Location{}) {
+			return
+		}
+		if firstPath == "" {
+			firstPath = path
+			planar = location.IsPlanar()
+			return
+		}
+		if location.IsPlanar() != planar {
+			v.add(
+				path,
+				ValidationInconsistentValue,
+				"planar and geographical locations can not be mixed,"+
+					" location at %s is planar: %t",
+				firstPath,
+				planar,
+			)
+		}
+	}
+	for i := range input.Stops {
+		check(fmt.Sprintf("stops[%d].location", i), &input.Stops[i].Location)
+	}
+	if input.AlternateStops != nil {
+		for i := range *input.AlternateStops {
+			check(
+				fmt.Sprintf("alternate_stops[%d].location", i),
+				&(*input.AlternateStops)[i].Location,
+			)
+		}
+	}
+	for i, vehicle := range input.Vehicles {
+		check(fmt.Sprintf("vehicles[%d].start_location", i), vehicle.StartLocation)
+		check(fmt.Sprintf("vehicles[%d].end_location", i), vehicle.EndLocation)
+	}
+}
+
 func validateMatrix(
+	v *validation,
 	input schema.Input,
 	matrix [][]float64,
 	asymmetryTolerance int,
-	preFix string) error {
+	path string,
+	preFix string) {
 	size := len(input.Stops) + len(input.Vehicles)*2
 	if len(matrix) != size {
-		return nmerror.NewInputDataError(fmt.Errorf(
+		v.add(
+			path,
+			ValidationInvalidMatrix,
 			"%s matrix length (%v)"+
 				" does not match number of stops (%v) plus number of vehicles (%v) times 2",
 			preFix,
 			len(matrix),
 			len(input.Stops),
 			len(input.Vehicles),
-		))
+		)
+		return
 	}
+	validSize := true
 	for i := 0; i < size; i++ {
 		if len(matrix[i]) != size {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				fmt.Sprintf("%s[%d]", path, i),
+				ValidationInvalidMatrix,
 				"%s matrix row %v length (%v)"+
 					" does not match number of stops (%v) plus number of vehicles (%v) times 2",
 				preFix,
@@ -151,10 +312,102 @@ func validateMatrix(
 				len(matrix[i]),
 				len(input.Stops),
 				len(input.Vehicles),
-			))
+			)
+			validSize = false
 		}
 	}
+	if !validSize {
+		return
+	}
 
+	validateMatrixValues(
+		v,
+		input,
+		func(from, to int) float64 { return matrix[from][to] },
+		asymmetryTolerance,
+		path,
+		preFix,
+	)
+}
+
+// validateMatrixFile adds an error if the matrix file is not allowed by the
+// options, see [matrixFilePath], can not be opened, is not square or its size
+// does not match the stops and vehicles of the input.
+// The size is read from the header of the file. If matrix validation is
+// enabled, the values of the matrix are validated like the values of an
+// inline matrix.
+func validateMatrixFile(
+	v *validation,
+	input schema.Input,
+	file string,
+	modelOptions Options,
+	path string,
+	preFix string,
+) {
+	filePath, err := matrixFilePath(file, modelOptions)
+	if err != nil {
+		v.addError(path, ValidationInvalidMatrix, err)
+		return
+	}
+	matrix, err := matrixfile.Open(filePath)
+	if err != nil {
+		v.addError(path, ValidationInvalidMatrix, err)
+		return
+	}
+	defer matrix.Close()
+
+	if matrix.Rows() != matrix.Columns() {
+		v.add(
+			path,
+			ValidationInvalidMatrix,
+			"%s matrix file %s has %v rows and %v columns, it must be square",
+			preFix,
+			file,
+			matrix.Rows(),
+			matrix.Columns(),
+		)
+		return
+	}
+	size := len(input.Stops) + len(input.Vehicles)*2
+	if matrix.Rows() != size {
+		v.add(
+			path,
+			ValidationInvalidMatrix,
+			"%s matrix file %s size (%v)"+
+				" does not match number of stops (%v) plus number of vehicles (%v) times 2",
+			preFix,
+			file,
+			matrix.Rows(),
+			len(input.Stops),
+			len(input.Vehicles),
+		)
+		return
+	}
+	if !modelOptions.Validate.Enable.Matrix {
+		return
+	}
+	validateMatrixValues(
+		v,
+		input,
+		matrix.Cost,
+		modelOptions.Validate.Enable.MatrixAsymmetryTolerance,
+		path,
+		preFix,
+	)
+}
+
+// validateMatrixValues adds errors for negative values, zero values between
+// different locations and asymmetries larger than the tolerance of a square
+// matrix of the size of the input.
+func validateMatrixValues(
+	v *validation,
+	input schema.Input,
+	value func(from, to int) float64,
+	asymmetryTolerance int,
+	path string,
+	preFix string,
+) {
+	size := len(input.Stops) + len(input.Vehicles)*2
 	var asymmetries []string
 	for i := 0; i < size; i++ {
 		for j := i + 1; j < size; j++ {
@@ -169,71 +422,81 @@ func validateMatrix(
 			jID := identify(input, j)
 
 			// Check if the matrix is negative
-			if matrix[i][j] < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+			if value(i, j) < 0 {
+				v.add(
+					fmt.Sprintf("%s[%d][%d]", path, i, j),
+					ValidationNegativeValue,
 					"%s matrix has negative value %v for stops `%s` and `%s`",
 					preFix,
-					matrix[i][j],
+					value(i, j),
 					iID,
 					jID,
-				))
+				)
 			}
-			if matrix[j][i] < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+			if value(j, i) < 0 {
+				v.add(
+					fmt.Sprintf("%s[%d][%d]", path, j, i),
+					ValidationNegativeValue,
 					"%s matrix has negative value %v for stops `%s` and `%s`",
 					preFix,
-					matrix[j][i],
+					value(j, i),
 					iID,
 					jID,
-				))
+				)
 			}
 			// Check if cells with zero have the same location
-			if matrix[i][j] == 0 {
+			if value(i, j) == 0 {
 				if !reflect.DeepEqual(iLocation, jLocation) {
-					return nmerror.NewInputDataError(fmt.Errorf(
+					v.add(
+						fmt.Sprintf("%s[%d][%d]", path, i, j),
+						ValidationInvalidMatrix,
 						"%s is zero for stop `%s`[%v] to `%s`[%v] at different locations",
 						preFix,
 						iID,
 						iLocation,
 						jID,
 						jLocation,
-					))
+					)
 				}
 			}
 			// Check if the duration matrix is symmetric within tolerance
-			diff := math.Abs(matrix[i][j]-matrix[j][i]) / ((matrix[i][j] + matrix[j][i]) / 2.0) * 100.0
+			diff := math.Abs(value(i, j)-value(j, i)) / ((value(i, j) + value(j, i)) / 2.0) * 100.0
 			if diff > float64(asymmetryTolerance) {
 				asymmetries = append(asymmetries, fmt.Sprintf(
 					"`%s` to `%s` is %v, reverse is %v, difference is %.2f percent",
 					iID,
 					jID,
-					matrix[i][j],
-					matrix[j][i],
+					value(i, j),
+					value(j, i),
 					diff),
 				)
 				if len(asymmetries) > 10 {
-					return nmerror.NewInputDataError(fmt.Errorf(
+					v.add(
+						path,
+						ValidationInvalidMatrix,
 						"%s matrix has too many asymmetries larger than %v percent, first 10 are `%s`",
 						preFix,
 						asymmetryTolerance,
 						strings.Join(asymmetries, "`, `"),
-					))
+					)
+					return
 				}
 			}
 		}
 	}
 	if len(asymmetries) > 0 {
-		return nmerror.NewInputDataError(fmt.Errorf(
+		v.add(
+			path,
+			ValidationInvalidMatrix,
 			"%s matrix has too many asymmetries larger than %v percent, `%s`",
 			preFix,
 			asymmetryTolerance,
 			strings.Join(asymmetries, "`, `"),
-		))
+		)
 	}
-	return nil
 }
 
-func validateConstraints(input schema.Input, modelOptions Options) error {
+func validateConstraints(v *validation, input schema.Input, modelOptions Options) {
 	if !modelOptions.Validate.Disable.StartTime {
 		hasStartTimeWindow := common.Has(
 			input.Stops,
@@ -250,76 +513,129 @@ func validateConstraints(input schema.Input, modelOptions Options) error {
 		)
 
 		if hasStartTimeWindow && len(vehiclesHaveStartTime) != len(input.Vehicles) {
-			return nmerror.NewInputDataError(fmt.Errorf(
-				"there are stops with a start_time_window but not all vehicles have start_time," +
-					" if intended use validate option to disable this start time validation" +
+			v.add(
+				"vehicles",
+				ValidationMissingValue,
+				"there are stops with a start_time_window but not all vehicles have start_time,"+
+					" if intended use validate option to disable this start time validation"+
 					" (`options.Model.Validate.Disable.StartTime = true`)",
-			))
+			)
+		}
+	}
+
+	if input.DistanceMatrixFile != "" {
+		if input.DistanceMatrix != nil {
+			v.add(
+				"distance_matrix_file",
+				ValidationInconsistentValue,
+				"distance matrix and distance matrix file are both set, only one is allowed",
+			)
+		}
+		validateMatrixFile(
+			v,
+			input,
+			input.DistanceMatrixFile,
+			modelOptions,
+			"distance_matrix_file",
+			"distance",
+		)
+	}
+
+	if input.DurationMatrixFile != "" {
+		if input.DurationMatrix != nil {
+			v.add(
+				"duration_matrix_file",
+				ValidationInconsistentValue,
+				"duration matrix and duration matrix file are both set, only one is allowed",
+			)
 		}
+		validateMatrixFile(
+			v,
+			input,
+			input.DurationMatrixFile,
+			modelOptions,
+			"duration_matrix_file",
+			"duration",
+		)
 	}
 
 	if input.DistanceMatrix != nil && modelOptions.Validate.Enable.Matrix {
-		distanceMatrix := *input.DistanceMatrix
-		if err := validateMatrix(
+		validateMatrix(
+			v,
 			input,
-			distanceMatrix,
+			*input.DistanceMatrix,
 			modelOptions.Validate.Enable.MatrixAsymmetryTolerance,
-			"distance"); err != nil {
-			return err
-		}
+			"distance_matrix",
+			"distance",
+		)
 	}
 
 	if input.DurationMatrix == nil {
-		return nil
+		return
 	}
 	switch matrix := input.DurationMatrix.(type) {
 	case [][]float64:
 		if modelOptions.Validate.Enable.Matrix {
-			if err := validateMatrix(
+			validateMatrix(
+				v,
 				input,
 				matrix,
 				modelOptions.Validate.Enable.MatrixAsymmetryTolerance,
-				"duration"); err != nil {
-				return err
-			}
+				"duration_matrix",
+				"duration",
+			)
 		}
 	case schema.TimeDependentMatrix:
-		return validateTimeDependentMatrix(input, matrix, modelOptions, true)
+		validateTimeDependentMatrix(v, input, matrix, modelOptions, true, "duration_matrix")
 	case []schema.TimeDependentMatrix:
-		return validateTimeDependentMatricesAndIDs(input, matrix, modelOptions)
+		validateTimeDependentMatricesAndIDs(v, input, matrix, modelOptions)
 	case map[string]any:
 		timeDependentMatrix, err := convertToTimeDependentMatrix(matrix)
 		if err != nil {
-			return err
+			v.addError("duration_matrix", ValidationInvalidMatrix, err)
+			return
 		}
-		return validateTimeDependentMatrix(input, timeDependentMatrix, modelOptions, true)
+		validateTimeDependentMatrix(v, input, timeDependentMatrix, modelOptions, true, "duration_matrix")
 	case []any:
 		// In this case we have a single matrix that can be a float64 matrix or a
 		// multi duration matrix.
-		return validateFloatOrMultiDurationMatrix(input, matrix, modelOptions)
+		validateFloatOrMultiDurationMatrix(v, input, matrix, modelOptions)
 	default:
-		return nmerror.NewInputDataError(fmt.Errorf("invalid duration matrix type %T", matrix))
+		v.add(
+			"duration_matrix",
+			ValidationInvalidMatrix,
+			"invalid duration matrix type %T",
+			matrix,
+		)
 	}
-	return nil
 }
 
-func validateFloatOrMultiDurationMatrix(input schema.Input, matrix []any, modelOptions Options) error {
+func validateFloatOrMultiDurationMatrix(
+	v *validation,
+	input schema.Input,
+	matrix []any,
+	modelOptions Options,
+) {
 	if floatMatrix, ok := common.TryAssertFloat64Matrix(matrix); ok {
 		if modelOptions.Validate.Enable.Matrix {
-			return validateMatrix(
+			validateMatrix(
+				v,
 				input,
 				floatMatrix,
 				modelOptions.Validate.Enable.MatrixAsymmetryTolerance,
-				"duration")
+				"duration_matrix",
+				"duration",
+			)
 		}
-		return nil
+		return
 	}
 
 	timeDependentMatrices, err := convertToTimeDependentMatrices(matrix)
 	if err != nil {
-		return err
+		v.addError("duration_matrix", ValidationInvalidMatrix, err)
+		return
 	}
-	return validateTimeDependentMatricesAndIDs(input, timeDependentMatrices, modelOptions)
+	validateTimeDependentMatricesAndIDs(v, input, timeDependentMatrices, modelOptions)
 }
 
 // Converts a time-dependent matrices from a slice of interfaces to []schema.TimeDependentMatrix.
@@ -342,115 +658,133 @@ func convertToTimeDependentMatrices(data []any) ([]schema.TimeDependentMatrix, e
 }
 
 func validateTimeDependentMatricesAndIDs(
+	v *validation,
 	input schema.Input,
 	timeDependentMatrices []schema.TimeDependentMatrix,
 	modelOptions Options,
-) error {
+) {
+	inputVehicleIDs := make(map[string]bool, len(input.Vehicles))
+	for _, vehicle := range input.Vehicles {
+		inputVehicleIDs[vehicle.ID] = true
+	}
+
 	vIDs := make(map[string]bool)
-	for _, durationMatrix := range timeDependentMatrices {
+	for idx, durationMatrix := range timeDependentMatrices {
+		path := fmt.Sprintf("duration_matrix[%d]", idx)
 		if len(durationMatrix.VehicleIDs) == 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".vehicle_ids",
+				ValidationMissingValue,
 				"vehicle ids are not set for duration matrix",
-			))
+			)
 		}
-		for _, vID := range durationMatrix.VehicleIDs {
+		for i, vID := range durationMatrix.VehicleIDs {
+			vehicleIDPath := fmt.Sprintf("%s.vehicle_ids[%d]", path, i)
 			if _, ok := vIDs[vID]; ok {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					vehicleIDPath,
+					ValidationDuplicateID,
 					"duplicate vehicle id in duration matrices: %s",
-					durationMatrix.VehicleIDs,
-				))
+					vID,
+				)
+			}
+			// Make sure there is no definition in the input that does not
+			// exist as a vehicle.
+			if !inputVehicleIDs[vID] {
+				v.add(
+					vehicleIDPath,
+					ValidationUnknownID,
+					"vehicle id %s in duration matrices does not match a vehicle id in input",
+					vID,
+				)
 			}
 			vIDs[vID] = true
 		}
-		if err := validateTimeDependentMatrix(input, durationMatrix, modelOptions, false); err != nil {
-			return err
-		}
+		validateTimeDependentMatrix(v, input, durationMatrix, modelOptions, false, path)
 	}
 
 	// Make sure all vehicles in the input have a duration matrix defined.
-	for _, vehicle := range input.Vehicles {
+	for idx, vehicle := range input.Vehicles {
 		if _, ok := vIDs[vehicle.ID]; !ok {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				fmt.Sprintf("vehicles[%d].id", idx),
+				ValidationMissingValue,
 				"vehicle id %s is not defined in duration matrices",
 				vehicle.ID,
-			))
+			)
 		}
 	}
-
-	// Make sure there is no definition in the input that does not exist as a vehicle.
-	if len(vIDs) != len(input.Vehicles) {
-		return nmerror.NewInputDataError(fmt.Errorf(
-			"vehicle ids in duration matrices do not match vehicle ids in input: %v",
-			vIDs,
-		))
-	}
-	return nil
 }
 
-func validateStop(idx int, stop schema.Stop, stopIDs map[string]bool) error {
-	if stop.ID == "" {
-		return nmerror.NewInputDataError(fmt.Errorf("no id set for stop at index %v", idx))
-	}
+func validateStop(v *validation, idx int, stop schema.Stop, stopIDs map[string]bool) {
+	path := fmt.Sprintf("stops[%d]", idx)
 
 	if stop.StartTimeWindow != nil {
-		_, err := convertTimeWindow(stop.StartTimeWindow, stop.ID)
-		if err != nil {
-			return err
-		}
+		validateTimeWindow(v, path+".start_time_window", stop.StartTimeWindow, stop.ID)
 	}
 
 	if stop.MaxWait != nil {
 		maxWait := *stop.MaxWait
 		if maxWait < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".max_wait",
+				ValidationNegativeValue,
 				"stop `%s` max wait must be non-negative, it is `%v` seconds",
 				stop.ID,
 				maxWait,
-			))
+			)
 		}
 	}
 
 	if stop.Duration != nil {
 		duration := *stop.Duration
 		if duration < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".duration",
+				ValidationNegativeValue,
 				"stop `%s` duration must be non-negative, it is `%v` seconds",
 				stop.ID,
 				duration,
-			))
+			)
 		}
 	}
 
 	if stop.UnplannedPenalty != nil {
 		unplannedPenalty := *stop.UnplannedPenalty
 		if unplannedPenalty < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".unplanned_penalty",
+				ValidationNegativeValue,
 				"stop `%s` unplanned penalty must be non-negative, it is `%v`",
 				stop.ID,
 				unplannedPenalty,
-			))
+			)
 		}
 	}
 
 	if stop.EarlyArrivalTimePenalty != nil {
 		earlyArrivalTimePenalty := *stop.EarlyArrivalTimePenalty
 		if earlyArrivalTimePenalty < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".early_arrival_time_penalty",
+				ValidationNegativeValue,
 				"stop `%s` early arrival time penalty must be non-negative, it is `%v`",
 				stop.ID,
 				earlyArrivalTimePenalty,
-			))
+			)
 		}
 	}
 
 	if stop.LateArrivalTimePenalty != nil {
 		lateArrivalTimePenalty := *stop.LateArrivalTimePenalty
 		if lateArrivalTimePenalty < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".late_arrival_time_penalty",
+				ValidationNegativeValue,
 				"stop `%s` late arrival time penalty must be non-negative, it is `%v`",
 				stop.ID,
 				lateArrivalTimePenalty,
-			))
+			)
 		}
 	}
 
@@ -458,249 +792,326 @@ func validateStop(idx int, stop schema.Stop, stopIDs map[string]bool) error {
 		compatibilityAttributes := *stop.CompatibilityAttributes
 		duplicateAttributes := common.NotUnique(compatibilityAttributes)
 		if len(duplicateAttributes) != 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".compatibility_attributes",
+				ValidationInvalidValue,
 				"stop `%s` has duplicate compatibility attributes, duplicates are [`%s`]",
 				stop.ID,
 				strings.Join(duplicateAttributes, "`, `"),
-			))
+			)
 		}
 	}
 
 	if reflect.DeepEqual(stop.Location, schema.Location{}) {
-		return nmerror.NewInputDataError(fmt.Errorf("stop `%s` has no location", stop.ID))
-	}
-
-	if _, err := common.NewLocation(
-		stop.Location.Lon,
-		stop.Location.Lat,
-	); err != nil {
-		return nmerror.NewInputDataError(fmt.Errorf(
-			"stop `%s` location is invalid: %w",
+		v.add(
+			path+".location",
+			ValidationMissingValue,
+			"stop `%s` has no location",
+			stop.ID,
+		)
+	} else if _, err := toLocation(stop.Location); err != nil {
+		v.add(
+			path+".location",
+			ValidationInvalidLocation,
+			"stop `%s` location is invalid: %v",
 			stop.ID,
 			err,
-		))
+		)
 	}
 
 	precedes, err := precedence(stop, "Precedes")
 	if err != nil {
-		return err
+		v.addError(path+".precedes", ValidationInvalidValue, err)
 	}
 
 	succeeds, err := precedence(stop, "Succeeds")
 	if err != nil {
-		return err
+		v.addError(path+".succeeds", ValidationInvalidValue, err)
 	}
 
 	for _, p := range precedes {
 		if !stopIDs[p.id] {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".precedes",
+				ValidationUnknownID,
 				"stop `%s` precedes references unknown stop %s",
 				stop.ID,
 				p.id,
-			))
+			)
 		}
 
 		if stop.Precedes == stop.ID {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".precedes",
+				ValidationInvalidValue,
 				"stop `%s` precedes itself",
 				stop.ID,
-			))
+			)
 		}
 	}
 	for _, s := range succeeds {
 		if !stopIDs[s.id] {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".succeeds",
+				ValidationUnknownID,
 				"stop `%s` succeeds references unknown stop %s",
 				stop.ID,
 				s.id,
-			))
+			)
 		}
 
 		if stop.Succeeds == stop.ID {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".succeeds",
+				ValidationInvalidValue,
 				"stop `%s` succeeds itself",
 				stop.ID,
-			))
+			)
 		}
 	}
-
-	return nil
 }
 
-func validateAlternateStop(idx int, stop schema.AlternateStop) error {
-	if stop.ID == "" {
-		return nmerror.NewInputDataError(fmt.Errorf("no id set for alternate stop at index %v", idx))
-	}
+func validateAlternateStop(v *validation, idx int, stop schema.AlternateStop) {
+	path := fmt.Sprintf("alternate_stops[%d]", idx)
 
 	if stop.StartTimeWindow != nil {
-		_, err := convertTimeWindow(stop.StartTimeWindow, stop.ID)
-		if err != nil {
-			return err
-		}
+		validateTimeWindow(v, path+".start_time_window", stop.StartTimeWindow, stop.ID)
 	}
 
 	if stop.MaxWait != nil {
 		maxWait := *stop.MaxWait
 		if maxWait < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".max_wait",
+				ValidationNegativeValue,
 				"alternate stop `%s` max wait must be non-negative, it is `%v` seconds",
 				stop.ID,
 				maxWait,
-			))
+			)
 		}
 	}
 
 	if stop.Duration != nil {
 		duration := *stop.Duration
 		if duration < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".duration",
+				ValidationNegativeValue,
 				"alternate stop `%s` duration must be non-negative, it is `%v` seconds",
 				stop.ID,
 				duration,
-			))
+			)
 		}
 	}
 
 	if stop.UnplannedPenalty != nil {
 		unplannedPenalty := *stop.UnplannedPenalty
 		if unplannedPenalty < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".unplanned_penalty",
+				ValidationNegativeValue,
 				"alternate stop `%s` unplanned penalty must be non-negative, it is `%v`",
 				stop.ID,
 				unplannedPenalty,
-			))
+			)
 		}
 	}
 
 	if stop.EarlyArrivalTimePenalty != nil {
 		earlyArrivalTimePenalty := *stop.EarlyArrivalTimePenalty
 		if earlyArrivalTimePenalty < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".early_arrival_time_penalty",
+				ValidationNegativeValue,
 				"alternate stop `%s` early arrival time penalty must be non-negative, it is `%v`",
 				stop.ID,
 				earlyArrivalTimePenalty,
-			))
+			)
 		}
 	}
 
 	if stop.LateArrivalTimePenalty != nil {
 		lateArrivalTimePenalty := *stop.LateArrivalTimePenalty
 		if lateArrivalTimePenalty < 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
+			v.add(
+				path+".late_arrival_time_penalty",
+				ValidationNegativeValue,
 				"alternate stop `%s` late arrival time penalty must be non-negative, it is `%v`",
 				stop.ID,
 				lateArrivalTimePenalty,
-			))
+			)
 		}
 	}
 
 	if reflect.DeepEqual(stop.Location, schema.Location{}) {
-		return nmerror.NewInputDataError(fmt.Errorf("alternate stop `%s` has no location", stop.ID))
-	}
-
-	if _, err := common.NewLocation(
-		stop.Location.Lon,
-		stop.Location.Lat,
-	); err != nil {
-		return nmerror.NewInputDataError(fmt.Errorf(
-			"stop `%s` location is invalid: %w",
+		v.add(
+			path+".location",
+			ValidationMissingValue,
+			"alternate stop `%s` has no location",
+			stop.ID,
+		)
+	} else if _, err := toLocation(stop.Location); err != nil {
+		v.add(
+			path+".location",
+			ValidationInvalidLocation,
+			"stop `%s` location is invalid: %v",
 			stop.ID,
 			err,
-		))
+		)
 	}
-
-	return nil
 }
 
 func validateTimeDependentMatrix(
+	v *validation,
 	input schema.Input,
 	durationMatrices schema.TimeDependentMatrix,
 	modelOptions Options,
 	isSingleMatrix bool,
-) error {
-	if modelOptions.Validate.Enable.Matrix {
-		if err := validateMatrix(
+	path string,
+) {
+	switch {
+	case durationMatrices.DefaultMatrixFile != "":
+		if durationMatrices.DefaultMatrix != nil {
+			v.add(
+				path+".default_matrix_file",
+				ValidationInconsistentValue,
+				"default matrix and default matrix file are both set, only one is allowed",
+			)
+		}
+		validateMatrixFile(
+			v,
+			input,
+			durationMatrices.DefaultMatrixFile,
+			modelOptions,
+			path+".default_matrix_file",
+			"time_dependent_duration",
+		)
+	case modelOptions.Validate.Enable.Matrix:
+		validateMatrix(
+			v,
 			input,
 			durationMatrices.DefaultMatrix,
 			modelOptions.Validate.Enable.MatrixAsymmetryTolerance,
-			"time_dependent_duration"); err != nil {
-			return err
-		}
+			path+".default_matrix",
+			"time_dependent_duration",
+		)
 	}
 	if isSingleMatrix {
 		if len(durationMatrices.VehicleIDs) != 0 {
-			return nmerror.NewInputDataError(fmt.Errorf(
-				"single matrix has vehicle ids set, it must be empty"))
+			v.add(
+				path+".vehicle_ids",
+				ValidationInvalidValue,
+				"single matrix has vehicle ids set, it must be empty",
+			)
 		}
 	}
 	for i, tf := range durationMatrices.MatrixTimeFrames {
-		if tf.Matrix == nil && tf.ScalingFactor == nil {
-			return nmerror.NewInputDataError(fmt.Errorf(
-				"duration for time frame %d is missing both matrix and scaling factor", i))
+		timeFramePath := fmt.Sprintf("%s.matrix_time_frames[%d]", path, i)
+		hasMatrix := tf.Matrix != nil || tf.MatrixFile != ""
+		if !hasMatrix && tf.ScalingFactor == nil {
+			v.add(
+				timeFramePath,
+				ValidationMissingValue,
+				"duration for time frame %d is missing both matrix and scaling factor",
+				i,
+			)
 		}
 
-		if tf.Matrix != nil && tf.ScalingFactor != nil {
-			return nmerror.NewInputDataError(fmt.Errorf(
-				"duration for time frame %d has both matrix and scaling factor, only one is allowed", i))
+		if hasMatrix && tf.ScalingFactor != nil {
+			v.add(
+				timeFramePath,
+				ValidationInvalidValue,
+				"duration for time frame %d has both matrix and scaling factor, only one is allowed",
+				i,
+			)
+		}
+
+		if tf.MatrixFile != "" {
+			if tf.Matrix != nil {
+				v.add(
+					timeFramePath+".matrix_file",
+					ValidationInconsistentValue,
+					"duration for time frame %d has both matrix and matrix file, only one is allowed",
+					i,
+				)
+			}
+			validateMatrixFile(
+				v,
+				input,
+				tf.MatrixFile,
+				modelOptions,
+				timeFramePath+".matrix_file",
+				fmt.Sprintf("time_dependent_duration for time frame %d", i),
+			)
 		}
 
 		if tf.Matrix != nil && modelOptions.Validate.Enable.Matrix {
-			if err := validateMatrix(
+			validateMatrix(
+				v,
 				input,
 				tf.Matrix,
 				modelOptions.Validate.Enable.MatrixAsymmetryTolerance,
+				timeFramePath+".matrix",
 				fmt.Sprintf("time_dependent_duration for time frame %d", i),
-			); err != nil {
-				return err
-			}
+			)
 		}
 
 		if tf.ScalingFactor != nil {
 			if *tf.ScalingFactor <= 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
-					"time_dependent_duration for time frame %d has invalid scaling factor %v", i, *tf.ScalingFactor))
+				v.add(
+					timeFramePath+".scaling_factor",
+					ValidationInvalidValue,
+					"time_dependent_duration for time frame %d has invalid scaling factor %v",
+					i,
+					*tf.ScalingFactor,
+				)
 			}
 		}
 
-		if tf.StartTime.IsZero() {
-			return nmerror.NewInputDataError(fmt.Errorf(
-				"time_dependent_duration for time frame %d has no start time", i))
-		}
-		if tf.EndTime.IsZero() {
-			return nmerror.NewInputDataError(fmt.Errorf(
-				"time_dependent_duration for time frame %d has no end time", i))
-		}
-		if tf.StartTime.After(tf.EndTime) || tf.StartTime.Equal(tf.EndTime) {
-			return nmerror.NewInputDataError(fmt.Errorf(
+		switch {
+		case tf.StartTime.IsZero():
+			v.add(
+				timeFramePath+".start_time",
+				ValidationMissingValue,
+				"time_dependent_duration for time frame %d has no start time",
+				i,
+			)
+		case tf.EndTime.IsZero():
+			v.add(
+				timeFramePath+".end_time",
+				ValidationMissingValue,
+				"time_dependent_duration for time frame %d has no end time",
+				i,
+			)
+		case tf.StartTime.After(tf.EndTime) || tf.StartTime.Equal(tf.EndTime):
+			v.add(
+				timeFramePath+".end_time",
+				ValidationInconsistentValue,
 				"time_dependent_duration for time frame %d has invalid start and end time, "+
-					"start time is after or equal to end time", i))
+					"start time is after or equal to end time",
+				i,
+			)
 		}
 	}
-	return nil
 }
 
 func validateStops(
+	v *validation,
 	input schema.Input,
 	allStopIDs map[string]bool,
 	stopIDs map[string]bool,
-	alternateStopIDs map[string]bool) error {
+	alternateStopIDs map[string]bool) {
 	if len(input.Stops) == 0 {
-		return errors.New("no stops provided")
+		v.add("stops", ValidationMissingValue, "no stops provided")
 	}
 
 	for idx, stop := range input.Stops {
-		err := validateStop(idx, stop, allStopIDs)
-		if err != nil {
-			return err
-		}
+		validateStop(v, idx, stop, allStopIDs)
 	}
 
 	if input.AlternateStops != nil {
 		for idx, stop := range *input.AlternateStops {
-			err := validateAlternateStop(idx, stop)
-			if err != nil {
-				return err
-			}
+			validateAlternateStop(v, idx, stop)
 		}
 	}
 
@@ -709,101 +1120,102 @@ func validateStops(
 		for i, stopGroup := range stopGroups {
 			duplicateStops := common.NotUnique(stopGroup)
 			if len(duplicateStops) != 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					fmt.Sprintf("stop_groups[%d]", i),
+					ValidationDuplicateID,
 					"stop group at index %d has duplicate stops, duplicates are [`%s`]",
 					i,
 					strings.Join(duplicateStops, "`, `"),
-				))
+				)
 			}
-			for _, id := range stopGroup {
-				if alternateStopIDs[id] {
-					return nmerror.NewInputDataError(fmt.Errorf("stop group at index %d references an alternate stop `%s`,"+
-						" alternate stops can not be used in stop groups",
+			for j, id := range stopGroup {
+				switch {
+				case alternateStopIDs[id]:
+					v.add(
+						fmt.Sprintf("stop_groups[%d][%d]", i, j),
+						ValidationInvalidValue,
+						"stop group at index %d references an alternate stop `%s`,"+
+							" alternate stops can not be used in stop groups",
 						i,
 						id,
-					))
-				}
-				if !stopIDs[id] {
-					return nmerror.NewInputDataError(fmt.Errorf("stop group at index %d references an unknown stop `%s`",
+					)
+				case !stopIDs[id]:
+					v.add(
+						fmt.Sprintf("stop_groups[%d][%d]", i, j),
+						ValidationUnknownID,
+						"stop group at index %d references an unknown stop `%s`",
 						i,
 						id,
-					))
+					)
 				}
 			}
 		}
 	}
-
-	return nil
 }
 
-func validateVehicles(input schema.Input, stopIDs map[string]bool) error {
+func validateVehicles(v *validation, input schema.Input, stopIDs map[string]bool) {
 	if len(input.Vehicles) == 0 {
-		return errors.New("no vehicles provided")
-	}
-	duplicates := common.NotUniqueDefined(
-		input.Vehicles,
-		func(v schema.Vehicle) string {
-			return v.ID
-		},
-	)
-	if len(duplicates) != 0 {
-		return nmerror.NewInputDataError(fmt.Errorf(
-			"vehicle ID's are not unique, duplicate ID's are [`%v`]",
-			strings.Join(
-				common.Map(duplicates, func(v schema.Vehicle) string {
-					return v.ID
-				}),
-				"`, `",
-			)))
+		v.add("vehicles", ValidationMissingValue, "no vehicles provided")
 	}
+	validateUniqueIDs(v, input.Vehicles, func(v schema.Vehicle) string {
+		return v.ID
+	}, "vehicles", "vehicle")
 
 	for idx, vehicle := range input.Vehicles {
+		path := fmt.Sprintf("vehicles[%d]", idx)
 		if vehicle.ID == "" {
-			return nmerror.NewInputDataError(fmt.Errorf("no id set for vehicle at index %v", idx))
+			v.add(
+				path+".id",
+				ValidationMissingID,
+				"no id set for vehicle at index %v",
+				idx,
+			)
 		}
 
-		if input.DurationMatrix == nil && vehicle.Speed == nil {
-			return nmerror.NewInputDataError(fmt.Errorf(
+		if input.DurationMatrix == nil && input.DurationMatrixFile == "" && vehicle.Speed == nil {
+			v.add(
+				path+".speed",
+				ValidationMissingValue,
 				"vehicle `%s` no duration matrix and no speed set,"+
 					" requires speed to determine duration based on distance",
 				vehicle.ID,
-			))
+			)
 		}
 
 		if vehicle.StartLocation != nil {
 			startLocation := *vehicle.StartLocation
-			if _, err := common.NewLocation(
-				startLocation.Lon,
-				startLocation.Lat,
-			); err != nil {
-				return nmerror.NewInputDataError(fmt.Errorf(
-					"vehicle `%s` start location is invalid: %w",
+			if _, err := toLocation(startLocation); err != nil {
+				v.add(
+					path+".start_location",
+					ValidationInvalidLocation,
+					"vehicle `%s` start location is invalid: %v",
 					vehicle.ID,
 					err,
-				))
+				)
 			}
 		}
 		if vehicle.EndLocation != nil {
 			endLocation := *vehicle.EndLocation
-			if _, err := common.NewLocation(
-				endLocation.Lon,
-				endLocation.Lat,
-			); err != nil {
-				return nmerror.NewInputDataError(fmt.Errorf(
-					"vehicle `%s` end location is invalid: %w",
+			if _, err := toLocation(endLocation); err != nil {
+				v.add(
+					path+".end_location",
+					ValidationInvalidLocation,
+					"vehicle `%s` end location is invalid: %v",
 					vehicle.ID,
 					err,
-				))
+				)
 			}
 		}
 		if vehicle.Speed != nil {
 			speed := *vehicle.Speed
 			if speed <= 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".speed",
+					ValidationInvalidValue,
 					"vehicle `%s` speed must be greater than 0, it is %v meters per second",
 					vehicle.ID,
 					speed,
-				))
+				)
 			}
 		}
 
@@ -812,13 +1224,15 @@ func validateVehicles(input schema.Input, stopIDs map[string]bool) error {
 			if vehicle.EndTime != nil {
 				endTime := *vehicle.EndTime
 				if startTime.After(endTime) {
-					return nmerror.NewInputDataError(fmt.Errorf(
+					v.add(
+						path+".end_time",
+						ValidationInconsistentValue,
 						"vehicle `%s` start time `%v` is %v after end time `%v`",
 						vehicle.ID,
 						startTime,
 						startTime.Sub(endTime),
 						endTime,
-					))
+					)
 				}
 			}
 		}
@@ -826,44 +1240,52 @@ func validateVehicles(input schema.Input, stopIDs map[string]bool) error {
 		if vehicle.MaxStops != nil {
 			maxStops := *vehicle.MaxStops
 			if maxStops < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".max_stops",
+					ValidationNegativeValue,
 					"vehicle `%s` maximum stops must be non-negative, it is %v",
 					vehicle.ID,
 					maxStops,
-				))
+				)
 			}
 		}
 
 		if vehicle.MaxDistance != nil {
 			maxDistance := *vehicle.MaxDistance
 			if maxDistance < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".max_distance",
+					ValidationNegativeValue,
 					"vehicle `%s` maximum distance must be non-negative, it is %v meters",
 					vehicle.ID,
 					maxDistance,
-				))
+				)
 			}
 		}
 
 		if vehicle.MaxDuration != nil {
 			maxDuration := *vehicle.MaxDuration
 			if maxDuration < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".max_duration",
+					ValidationNegativeValue,
 					"vehicle `%s`maximum duration must be non-negative, it is %v seconds",
 					vehicle.ID,
 					maxDuration,
-				))
+				)
 			}
 		}
 
 		if vehicle.MaxWait != nil {
 			maxWait := *vehicle.MaxWait
 			if maxWait < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".max_wait",
+					ValidationNegativeValue,
 					"vehicle `%s` maximum wait must be non-negative, it is %v seconds",
 					vehicle.ID,
 					maxWait,
-				))
+				)
 			}
 		}
 
@@ -871,55 +1293,44 @@ func validateVehicles(input schema.Input, stopIDs map[string]bool) error {
 			compatibilityAttributes := *vehicle.CompatibilityAttributes
 			duplicateAttributes := common.NotUnique(compatibilityAttributes)
 			if len(duplicateAttributes) != 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".compatibility_attributes",
+					ValidationInvalidValue,
 					"vehicle `%s` has duplicate compatibility attributes, duplicates are [`%s`]",
 					vehicle.ID,
 					strings.Join(duplicateAttributes, "`, `"),
-				))
+				)
 			}
 		}
 
 		if vehicle.InitialStops != nil {
 			initialStops := *vehicle.InitialStops
+			initialStopsPath := path + ".initial_stops"
 			for i, initialStop := range initialStops {
 				if initialStop.ID == "" {
-					return nmerror.NewInputDataError(fmt.Errorf(
+					v.add(
+						fmt.Sprintf("%s[%d].id", initialStopsPath, i),
+						ValidationMissingID,
 						"vehicle `%s` no id set for initial stop at index %v",
 						vehicle.ID,
-						i))
+						i,
+					)
+					continue
 				}
-			}
-			duplicateInitialStops := common.NotUniqueDefined(
-				initialStops,
-				func(s schema.InitialStop) string {
-					return s.ID
-				},
-			)
-			if len(duplicateInitialStops) != 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
-					"vehicle `%s` has duplicate initial stops, duplicates are [`%s`]",
-					vehicle.ID,
-					strings.Join(
-						common.Map(duplicateInitialStops, func(s schema.InitialStop) string {
-							return s.ID
-						}),
-						"`, `",
-					),
-				))
-			}
-		}
-
-		if vehicle.InitialStops != nil {
-			initialStops := *vehicle.InitialStops
-			for _, stop := range initialStops {
-				if _, ok := stopIDs[stop.ID]; !ok {
-					return nmerror.NewInputDataError(fmt.Errorf(
+				if _, ok := stopIDs[initialStop.ID]; !ok {
+					v.add(
+						fmt.Sprintf("%s[%d].id", initialStopsPath, i),
+						ValidationUnknownID,
 						"vehicle `%s` initial stop `%s` does not exist",
 						vehicle.ID,
-						stop.ID,
-					))
+						initialStop.ID,
+					)
 				}
 			}
+			validateUniqueIDs(v, initialStops, func(s schema.InitialStop) string {
+				return s.ID
+			}, initialStopsPath, fmt.Sprintf("vehicle `%s` initial stop", vehicle.ID))
+
 			if vehicle.AlternateStops != nil {
 				alternateInitialStops := common.Intersect(
 					common.Map(initialStops, func(s schema.InitialStop) string {
@@ -929,17 +1340,25 @@ func validateVehicles(input schema.Input, stopIDs map[string]bool) error {
 				)
 
 				if len(alternateInitialStops) > 1 {
-					return nmerror.NewInputDataError(fmt.Errorf(
+					v.add(
+						initialStopsPath,
+						ValidationInvalidValue,
 						"vehicle `%s` has multiple initial stops that are alternate stops, only one allowed, initial stops are [`%s`]",
 						vehicle.ID,
 						strings.Join(alternateInitialStops, "`, `"),
-					))
+					)
 				}
 			}
 		}
 	}
+}
 
-	return nil
+// sortedResourceNames returns the names of the resources in alphabetical
+// order, so that the errors are reported in the same order on each run.
+func sortedResourceNames(resources map[string]float64) []string {
+	names := common.Keys(resources)
+	slices.Sort(names)
+	return names
 }
 
 type resourceInfo struct {
@@ -950,23 +1369,28 @@ type resourceInfo struct {
 	allStopsPositive         bool
 }
 
-func validateResources(input schema.Input, modelOptions Options) error {
+func validateResources(v *validation, input schema.Input, modelOptions Options) {
 	resourcesInfo := map[string]*resourceInfo{}
 
-	for _, vehicle := range input.Vehicles {
+	for idx, vehicle := range input.Vehicles {
+		path := fmt.Sprintf("vehicles[%d]", idx)
 		resourceCapacities, err := resources(vehicle, "Capacity", 1)
 		if err != nil {
-			return err
+			v.addError(path+".capacity", ValidationInvalidValue, err)
+			continue
 		}
 
-		for name, resourceCapacity := range resourceCapacities {
+		for _, name := range sortedResourceNames(resourceCapacities) {
+			resourceCapacity := resourceCapacities[name]
 			if resourceCapacity < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".capacity",
+					ValidationNegativeValue,
 					"vehicle `%s` capacity must be positive, resource `%s` has negative capacity %f",
 					vehicle.ID,
 					name,
 					resourceCapacity,
-				))
+				)
 			}
 
 			if _, ok := resourcesInfo[name]; !ok {
@@ -982,36 +1406,43 @@ func validateResources(input schema.Input, modelOptions Options) error {
 
 		levels, err := resources(vehicle, "StartLevel", 1)
 		if err != nil {
-			return err
+			v.addError(path+".start_level", ValidationInvalidValue, err)
+			continue
 		}
-		for name, level := range levels {
-			if _, ok := resourceCapacities[name]; !ok {
-				return nmerror.NewInputDataError(fmt.Errorf(
+		for _, name := range sortedResourceNames(levels) {
+			level := levels[name]
+			resourceCapacity, ok := resourceCapacities[name]
+			if !ok {
+				v.add(
+					path+".start_level",
+					ValidationInconsistentValue,
 					"vehicle `%s` start level for resource `%s` is set but resource is not defined",
 					vehicle.ID,
 					name,
-				))
+				)
 			}
 			if level < 0 {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path+".start_level",
+					ValidationNegativeValue,
 					"vehicle `%s` start level must be positive, resource `%s` has negative start level %f",
 					vehicle.ID,
 					name,
 					level,
-				))
+				)
 			}
 
-			if resourceCapacity, ok := resourceCapacities[name]; ok {
-				if level > resourceCapacity {
-					return nmerror.NewInputDataError(fmt.Errorf(
-						"vehicle `%s` start level must be less or equal to capacity,"+
-							" resource `%s` has capacity %f and start level %f",
-						vehicle.ID,
-						name,
-						resourceCapacity,
-						level,
-					))
-				}
+			if ok && level > resourceCapacity {
+				v.add(
+					path+".start_level",
+					ValidationInconsistentValue,
+					"vehicle `%s` start level must be less or equal to capacity,"+
+						" resource `%s` has capacity %f and start level %f",
+					vehicle.ID,
+					name,
+					resourceCapacity,
+					level,
+				)
 			}
 		}
 
@@ -1029,21 +1460,27 @@ func validateResources(input schema.Input, modelOptions Options) error {
 		}
 	}
 
-	for _, stop := range input.Stops {
+	for idx, stop := range input.Stops {
+		path := fmt.Sprintf("stops[%d].quantity", idx)
 		quantity, err := resources(stop, "Quantity", 1)
 		if err != nil {
-			return err
+			v.addError(path, ValidationInvalidValue, err)
+			continue
 		}
 
-		for name, value := range quantity {
+		for _, name := range sortedResourceNames(quantity) {
+			value := quantity[name]
 			if _, ok := resourcesInfo[name]; !ok {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					path,
+					ValidationInconsistentValue,
 					"stop `%s` quantity %f for resource `%s` is set,"+
 						" but capacity for resource is not defined on any vehicle",
 					stop.ID,
 					value,
 					name,
-				))
+				)
+				continue
 			}
 			resourcesInfo[name].anyStops = true
 			resourcesInfo[name].allStopsNegative =
@@ -1054,21 +1491,27 @@ func validateResources(input schema.Input, modelOptions Options) error {
 	}
 
 	if input.AlternateStops != nil {
-		for _, stop := range *input.AlternateStops {
+		for idx, stop := range *input.AlternateStops {
+			path := fmt.Sprintf("alternate_stops[%d].quantity", idx)
 			quantity, err := resources(stop, "Quantity", 1)
 			if err != nil {
-				return err
+				v.addError(path, ValidationInvalidValue, err)
+				continue
 			}
 
-			for name, value := range quantity {
+			for _, name := range sortedResourceNames(quantity) {
+				value := quantity[name]
 				if _, ok := resourcesInfo[name]; !ok {
-					return nmerror.NewInputDataError(fmt.Errorf(
+					v.add(
+						path,
+						ValidationInconsistentValue,
 						"alternate stop `%s` quantity %v for resource `%s` is set,"+
 							" but capacity for resource is not defined on any vehicle",
 						stop.ID,
 						value,
 						name,
-					))
+					)
+					continue
 				}
 				resourcesInfo[name].anyStops = true
 				resourcesInfo[name].allStopsNegative =
@@ -1080,9 +1523,14 @@ func validateResources(input schema.Input, modelOptions Options) error {
 	}
 
 	if !modelOptions.Validate.Disable.Resources {
-		for name, info := range resourcesInfo {
+		names := common.Keys(resourcesInfo)
+		slices.Sort(names)
+		for _, name := range names {
+			info := resourcesInfo[name]
 			if info.anyStops && info.allStopsPositive && info.allStartLevelsZero {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					"stops",
+					ValidationInconsistentValue,
 					"resource `%s` is starting without any capacity being"+
 						" used. All your stops have a positive quantity and"+
 						" are considered as dropoff stops. You need to have"+
@@ -1090,11 +1538,13 @@ func validateResources(input schema.Input, modelOptions Options) error {
 						" start level > 0 to plan a stop with a positive"+
 						" quantity",
 					name,
-				))
+				)
 			}
 
 			if info.anyStops && info.allStopsNegative && info.allStartLevelsAtCapacity {
-				return nmerror.NewInputDataError(fmt.Errorf(
+				v.add(
+					"stops",
+					ValidationInconsistentValue,
 					"resource `%s` is starting with all of the capacity"+
 						" being used. All your stops have a negative quantity"+
 						" and are considered as pickup stops. You need to have"+
@@ -1102,25 +1552,26 @@ func validateResources(input schema.Input, modelOptions Options) error {
 						" start level < max capacity to plan a stop with a"+
 						" negative quantity",
 					name,
-				))
+				)
 			}
 		}
 	}
-
-	return nil
 }
 
 // Converts a time-dependent matrix from a JSON map to a schema.TimeDependentMatrix.
 func convertToTimeDependentMatrix(data map[string]any) (schema.TimeDependentMatrix, error) {
 	var result schema.TimeDependentMatrix
 
+	if file, ok := data["default_matrix_file"].(string); ok && file != "" {
+		result.DefaultMatrixFile = file
+	}
 	if dMatrix, ok := data["default_matrix"].([]any); ok {
 		if fMatrix, ok := common.TryAssertFloat64Matrix(dMatrix); ok {
 			result.DefaultMatrix = fMatrix
 		} else {
 			return result, fmt.Errorf("invalid or missing default_matrix")
 		}
-	} else {
+	} else if result.DefaultMatrixFile == "" {
 		return result, fmt.Errorf("invalid or missing default_matrix")
 	}
 
@@ -1146,6 +1597,10 @@ func convertToTimeDependentMatrix(data map[string]any) (schema.TimeDependentMatr
 				}
 			}
 
+			if file, ok := timeFrame["matrix_file"].(string); ok {
+				mtf.MatrixFile = file
+			}
+
 			if scalingFactor, ok := timeFrame["scaling_factor"].(float64); ok {
 				mtf.ScalingFactor = &scalingFactor
 			}
@@ -0,0 +1,63 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"errors"
+	"testing"
+
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestValidateCollectsAllErrors(t *testing.T) {
+	speed := 10.0
+	negative := -1
+	location := schema.Location{Lon: 0.01, Lat: 0}
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "s1", Location: location, MaxWait: &negative},
+			{ID: "s1", Location: location},
+			{
+				ID:       "s3",
+				Location: location,
+				StartTimeWindow: []any{
+					[]any{"2023-01-01T12:00:00Z", "2023-01-01T13:00:00Z"},
+					[]any{"2023-01-01T11:00:00Z", "2023-01-01T10:00:00Z"},
+				},
+			},
+			{ID: "s4"},
+		},
+		Vehicles: []schema.Vehicle{
+			{ID: "v1", Speed: &speed},
+			{ID: "v2"},
+		},
+	}
+
+	err := validate(input, Options{})
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	var validationErrors nmerror.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	want := []nmerror.ValidationError{
+		{Path: "stops[1].id", Code: ValidationDuplicateID},
+		{Path: "vehicles[1].speed", Code: ValidationMissingValue},
+		{Path: "stops[0].max_wait", Code: ValidationNegativeValue},
+		{Path: "stops[2].start_time_window[1]", Code: ValidationInvalidTimeWindow},
+		{Path: "stops[3].location", Code: ValidationMissingValue},
+		{Path: "vehicles", Code: ValidationMissingValue},
+	}
+	if len(validationErrors) != len(want) {
+		t.Fatalf("expected %v errors, got %v", len(want), validationErrors)
+	}
+	for i, w := range want {
+		got := validationErrors[i]
+		if got.Path != w.Path || got.Code != w.Code || got.Message == "" {
+			t.Errorf("error %v: expected %v [%v], got %+v", i, w.Path, w.Code, got)
+		}
+	}
+}
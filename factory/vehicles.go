@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 
 	"github.com/nextmv-io/nextroute"
 	"github.com/nextmv-io/nextroute/common"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/matrixfile"
 	"github.com/nextmv-io/nextroute/schema"
 	"github.com/nextmv-io/sdk/measure"
 )
@@ -26,17 +29,28 @@ func addVehicles(
 
 	var travelDuration nextroute.DurationExpression
 	travelDurationMap := make(map[string]*nextroute.DurationExpression)
+	if input.DurationMatrixFile != "" {
+		matrix, err := byIndex(nil, input.DurationMatrixFile, options)
+		if err != nil {
+			return nil, err
+		}
+		travelDuration = nextroute.NewDurationExpression(
+			"travelDuration",
+			nextroute.NewMeasureByIndexExpression(matrix),
+			common.Second,
+		)
+	}
 	switch matrix := input.DurationMatrix.(type) {
 	case [][]float64:
 		travelDuration = travelDurationExpression(matrix)
 	case schema.TimeDependentMatrix:
-		travelDuration, err = dependentTravelDurationExpression(matrix, model)
+		travelDuration, err = dependentTravelDurationExpression(matrix, model, options)
 		if err != nil {
 			return nil, err
 		}
 	case []schema.TimeDependentMatrix:
 		for _, durationMatrix := range matrix {
-			m, err := dependentTravelDurationExpression(durationMatrix, model)
+			m, err := dependentTravelDurationExpression(durationMatrix, model, options)
 			if err != nil {
 				return nil, err
 			}
@@ -54,7 +68,7 @@ func addVehicles(
 		if err != nil {
 			return nil, err
 		}
-		travelDuration, err = dependentTravelDurationExpression(durationMatrices, model)
+		travelDuration, err = dependentTravelDurationExpression(durationMatrices, model, options)
 		if err != nil {
 			return nil, err
 		}
@@ -74,7 +88,7 @@ func addVehicles(
 				return nil, err
 			}
 			for _, durationMatrix := range durationMatrices {
-				m, err := dependentTravelDurationExpression(durationMatrix, model)
+				m, err := dependentTravelDurationExpression(durationMatrix, model, options)
 				if err != nil {
 					return nil, err
 				}
@@ -89,7 +103,10 @@ func addVehicles(
 	}
 
 	durationGroupsExpression := NewDurationGroupsExpression(model.NumberOfStops(), len(input.Vehicles))
-	distanceExpression := distanceExpression(input.DistanceMatrix)
+	distanceExpression, err := distanceExpression(input, options)
+	if err != nil {
+		return nil, err
+	}
 
 	inputVehicleHasAlternateStops := false
 
@@ -213,10 +230,7 @@ func newVehicle(
 	startLocation := common.NewInvalidLocation()
 	var err error
 	if inputVehicle.StartLocation != nil {
-		startLocation, err = common.NewLocation(
-			inputVehicle.StartLocation.Lon,
-			inputVehicle.StartLocation.Lat,
-		)
+		startLocation, err = toLocation(*inputVehicle.StartLocation)
 		if err != nil {
 			return nil, err
 		}
@@ -229,10 +243,7 @@ func newVehicle(
 
 	endLocation := common.NewInvalidLocation()
 	if inputVehicle.EndLocation != nil {
-		endLocation, err = common.NewLocation(
-			inputVehicle.EndLocation.Lon,
-			inputVehicle.EndLocation.Lat,
-		)
+		endLocation, err = toLocation(*inputVehicle.EndLocation)
 		if err != nil {
 			return nil, err
 		}
@@ -283,13 +294,18 @@ func travelDurationExpression(matrix [][]float64) nextroute.DurationExpression {
 func dependentTravelDurationExpression(
 	durationMatrices schema.TimeDependentMatrix,
 	model nextroute.Model,
+	options Options,
 ) (nextroute.DurationExpression, error) {
-	if durationMatrices.DefaultMatrix == nil {
+	if durationMatrices.DefaultMatrix == nil && durationMatrices.DefaultMatrixFile == "" {
 		return nil, errors.New("no duration matrix provided")
 	}
+	defaultMatrix, err := byIndex(durationMatrices.DefaultMatrix, durationMatrices.DefaultMatrixFile, options)
+	if err != nil {
+		return nil, err
+	}
 	defaultExpression := nextroute.NewDurationExpression(
 		"default_duration_expression",
-		nextroute.NewMeasureByIndexExpression(measure.Matrix(durationMatrices.DefaultMatrix)),
+		nextroute.NewMeasureByIndexExpression(defaultMatrix),
 		common.Second,
 	)
 
@@ -305,9 +321,13 @@ func dependentTravelDurationExpression(
 				return nil, err
 			}
 		} else {
+			matrix, err := byIndex(tf.Matrix, tf.MatrixFile, options)
+			if err != nil {
+				return nil, err
+			}
 			trafficExpression := nextroute.NewDurationExpression(
 				fmt.Sprintf("traffic_duration_expression_%d", i),
-				nextroute.NewMeasureByIndexExpression(measure.Matrix(tf.Matrix)),
+				nextroute.NewMeasureByIndexExpression(matrix),
 				common.Second,
 			)
 			if err := timeExpression.SetExpression(tf.StartTime, tf.EndTime, trafficExpression); err != nil {
@@ -319,15 +339,81 @@ func dependentTravelDurationExpression(
 	return timeExpression, nil
 }
 
-// distanceExpression creates a distance expression for later use.
-func distanceExpression(distanceMatrix *[][]float64) nextroute.DistanceExpression {
-	distanceExpression := nextroute.NewHaversineExpression()
-	if distanceMatrix != nil {
-		distanceExpression = nextroute.NewDistanceExpression(
+// distanceExpression creates a distance expression for later use. Without a
+// distance matrix the distance is the haversine distance between
+// geographical locations or the planar measure between planar locations.
+func distanceExpression(
+	input schema.Input,
+	options Options,
+) (nextroute.DistanceExpression, error) {
+	if input.DistanceMatrixFile != "" {
+		matrix, err := byIndex(nil, input.DistanceMatrixFile, options)
+		if err != nil {
+			return nil, err
+		}
+		return nextroute.NewDistanceExpression(
+			"travelDistance",
+			nextroute.NewMeasureByIndexExpression(matrix),
+			common.Meters,
+		), nil
+	}
+	if input.DistanceMatrix != nil {
+		return nextroute.NewDistanceExpression(
 			"travelDistance",
-			nextroute.NewMeasureByIndexExpression(measure.Matrix(*distanceMatrix)),
+			nextroute.NewMeasureByIndexExpression(measure.Matrix(*input.DistanceMatrix)),
 			common.Meters,
+		), nil
+	}
+	if isPlanar(input) {
+		return planarDistanceExpression(options.Measures.Planar)
+	}
+	return nextroute.NewHaversineExpression(), nil
+}
+
+// byIndex returns the measure of the matrix or, if a file is given, of the
+// memory mapped matrix file, see [matrixFilePath].
+func byIndex(matrix [][]float64, file string, options Options) (measure.ByIndex, error) {
+	if file != "" {
+		path, err := matrixFilePath(file, options)
+		if err != nil {
+			return nil, nmerror.NewInputDataError(err)
+		}
+		m, err := matrixfile.Open(path)
+		if err != nil {
+			return nil, nmerror.NewInputDataError(err)
+		}
+		return m, nil
+	}
+	return measure.Matrix(matrix), nil
+}
+
+// matrixFilePath returns the path of a matrix file referenced by the input.
+// The input can only reference matrix files if they are enabled in the
+// options. The path is relative to the matrix files directory of the options
+// and must not be absolute or lead out of the directory, unless the options
+// allow any path.
+func matrixFilePath(file string, options Options) (string, error) {
+	if !options.MatrixFiles.Enable {
+		return "", fmt.Errorf(
+			"matrix file %s can not be read, matrix files are not enabled"+
+				" (`options.Model.MatrixFiles.Enable = true`)",
+			file,
+		)
+	}
+	if filepath.IsAbs(file) {
+		if !options.MatrixFiles.AllowAnyPath {
+			return "", fmt.Errorf(
+				"matrix file %s is an absolute path, it must be relative to the matrix files directory",
+				file,
+			)
+		}
+		return file, nil
+	}
+	if !filepath.IsLocal(file) && !options.MatrixFiles.AllowAnyPath {
+		return "", fmt.Errorf(
+			"matrix file %s leads out of the matrix files directory",
+			file,
 		)
 	}
-	return distanceExpression
+	return filepath.Join(options.MatrixFiles.Directory, file), nil
 }
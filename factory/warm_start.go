@@ -0,0 +1,201 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/nextmv-io/nextroute"
+	nmerror "github.com/nextmv-io/nextroute/common/errors"
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+// NewWarmStartSolution creates a solution of the model from a previous
+// solution, the warm start. The routes of the vehicles in the warm start are
+// mapped back onto the stops of the model by ID. Vehicles and stops which no
+// longer exist in the input are dropped, the start and end stops of the
+// vehicles are ignored. Stops which can not be planned as part of their route
+// are left unplanned, see [nextroute.NewRoutesSolution]. Each stop of the warm
+// start which was dropped or infeasible is returned as a violation.
+func NewWarmStartSolution(
+	input schema.Input,
+	model nextroute.Model,
+	warmStart schema.SolutionOutput,
+) (nextroute.Solution, []schema.ViolationOutput, error) {
+	routes, violations, err := toSolutionRoutes(input, model, warmStart)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	solution, infeasibilities, err := nextroute.NewRoutesSolution(model, routes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return solution, append(violations, toViolations(infeasibilities)...), nil
+}
+
+// toViolations returns a violation for each stop which could not be planned
+// as part of its route.
+func toViolations(
+	infeasibilities nextroute.RouteInfeasibilities,
+) []schema.ViolationOutput {
+	violations := make([]schema.ViolationOutput, 0, len(infeasibilities))
+	for _, infeasibility := range infeasibilities {
+		for _, stop := range infeasibility.Stops {
+			violations = append(violations, schema.ViolationOutput{
+				StopID:     stop.ID(),
+				VehicleID:  infeasibility.Vehicle.ID(),
+				Constraint: infeasibility.ConstraintName(),
+				Reason:     infeasibility.Reason,
+				Dropped:    true,
+			})
+		}
+	}
+	return violations
+}
+
+// toSolutionRoutes maps the routes of the vehicles in the solution output
+// onto the vehicles and stops of the model by ID. The start and end stops of
+// the vehicles are ignored. Stops which do not exist in the model, or whose
+// vehicle does not exist, are returned as violations.
+func toSolutionRoutes(
+	input schema.Input,
+	model nextroute.Model,
+	solutionOutput schema.SolutionOutput,
+) (nextroute.SolutionRoutes, []schema.ViolationOutput, error) {
+	data, err := getModelData(model)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vehicleIndices := make(map[string]int, len(input.Vehicles))
+	for idx, inputVehicle := range input.Vehicles {
+		vehicleIndices[inputVehicle.ID] = idx
+	}
+
+	violations := make([]schema.ViolationOutput, 0)
+	routes := make(nextroute.SolutionRoutes, 0, len(solutionOutput.Vehicles))
+	for _, vehicleOutput := range solutionOutput.Vehicles {
+		idx, vehicleExists := vehicleIndices[vehicleOutput.ID]
+
+		stops := make(nextroute.ModelStops, 0, len(vehicleOutput.Route))
+		for position, plannedStop := range vehicleOutput.Route {
+			if isVehicleStartOrEnd(vehicleOutput, position) {
+				continue
+			}
+			id := plannedStop.Stop.ID
+
+			if !vehicleExists {
+				violations = append(violations, schema.ViolationOutput{
+					StopID:    id,
+					VehicleID: vehicleOutput.ID,
+					Reason:    "is on a vehicle which does not exist",
+					Dropped:   true,
+				})
+				continue
+			}
+
+			modelStop, ok := warmStartStop(model, data, input.Vehicles[idx], id)
+			if !ok {
+				violations = append(violations, schema.ViolationOutput{
+					StopID:    id,
+					VehicleID: vehicleOutput.ID,
+					Reason:    "does not exist",
+					Dropped:   true,
+				})
+				continue
+			}
+			stops = append(stops, modelStop)
+		}
+
+		if vehicleExists {
+			routes = append(routes, nextroute.SolutionRoute{
+				Vehicle: model.Vehicles()[idx],
+				Stops:   stops,
+			})
+		}
+	}
+
+	return routes, violations, nil
+}
+
+// warmStartStop returns the model stop with the given ID as it can be visited
+// by the given vehicle. Alternate stops of the vehicle take precedence over
+// the stops of the input.
+func warmStartStop(
+	model nextroute.Model,
+	data modelData,
+	inputVehicle schema.Vehicle,
+	id string,
+) (nextroute.ModelStop, bool) {
+	index, ok := data.stopIDToIndex[alternateStopID(id, inputVehicle)]
+	if !ok {
+		index, ok = data.stopIDToIndex[id]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	modelStop, err := model.Stop(index)
+	if err != nil || modelStop.ID() != id {
+		return nil, false
+	}
+
+	return modelStop, true
+}
+
+// isVehicleStartOrEnd returns true if the stop at the position of the route
+// of the vehicle is the start or end stop of the vehicle.
+func isVehicleStartOrEnd(vehicle schema.VehicleOutput, position int) bool {
+	id := vehicle.Route[position].Stop.ID
+	return position == 0 && id == vehicle.ID+"-start" ||
+		position == len(vehicle.Route)-1 && id == vehicle.ID+"-end"
+}
+
+// ReadSolutionOutput reads a solution, such as a warm start, from the file at
+// the given path. The file holds either a single solution or the output of a
+// run, in which case the first solution of the run is used.
+func ReadSolutionOutput(path string) (schema.SolutionOutput, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return schema.SolutionOutput{}, err
+	}
+
+	return ParseSolutionOutput(bytes)
+}
+
+// ParseSolutionOutput parses a solution from JSON. The JSON holds either a
+// single solution or the output of a run, in which case the first solution of
+// the run is used.
+func ParseSolutionOutput(bytes []byte) (schema.SolutionOutput, error) {
+	var output struct {
+		Solutions *[]schema.SolutionOutput `json:"solutions"`
+	}
+	if err := json.Unmarshal(bytes, &output); err != nil {
+		return schema.SolutionOutput{}, nmerror.NewInputDataError(
+			fmt.Errorf("error parsing solution: %w", err),
+		)
+	}
+
+	if output.Solutions != nil {
+		if len(*output.Solutions) == 0 {
+			return schema.SolutionOutput{}, nmerror.NewInputDataError(
+				errors.New("run output does not contain a solution"),
+			)
+		}
+		return (*output.Solutions)[0], nil
+	}
+
+	var solution schema.SolutionOutput
+	if err := json.Unmarshal(bytes, &solution); err != nil {
+		return schema.SolutionOutput{}, nmerror.NewInputDataError(
+			fmt.Errorf("error parsing solution: %w", err),
+		)
+	}
+
+	return solution, nil
+}
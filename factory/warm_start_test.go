@@ -0,0 +1,80 @@
+// © 2019-present nextmv.io inc
+
+package factory
+
+import (
+	"testing"
+
+	"github.com/nextmv-io/nextroute/schema"
+)
+
+func TestWarmStart(t *testing.T) {
+	speed := 10.0
+	start := schema.Location{Lon: 0}
+	input := schema.Input{
+		Stops: []schema.Stop{
+			{ID: "s1", Location: schema.Location{Lon: 0.01}},
+			{ID: "s2", Location: schema.Location{Lon: 0.02}},
+			{ID: "s3", Location: schema.Location{Lon: 0.03}},
+		},
+		Vehicles: []schema.Vehicle{
+			{ID: "v1", StartLocation: &start, Speed: &speed},
+			{ID: "v2", StartLocation: &start, Speed: &speed},
+		},
+	}
+
+	model, err := NewModel(input, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warmStart, err := ParseSolutionOutput([]byte(`{"solutions": []}`))
+	if err == nil {
+		t.Fatalf("expected error for run output without solutions, got %v", warmStart)
+	}
+
+	warmStart = schema.SolutionOutput{
+		Vehicles: []schema.VehicleOutput{
+			{ID: "v1", Route: plannedRoute("v1-start", "s2", "removed", "s1")},
+			{ID: "v2", Route: plannedRoute("v2-start", "s1")},
+			{ID: "v3", Route: plannedRoute("v3-start", "s3")},
+		},
+	}
+
+	solution, violations, err := NewWarmStartSolution(input, model, warmStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The unknown stop, the stop planned twice and the unknown vehicle are
+	// reported.
+	if len(violations) != 3 {
+		t.Fatalf("expected 3 violations, got %v", violations)
+	}
+	if violations[2].StopID != "s1" || violations[2].VehicleID != "v2" {
+		t.Errorf("expected stop s1 planned twice on v2, got %v", violations[2])
+	}
+
+	got := make([]string, 0)
+	for _, stop := range solution.Vehicles()[0].SolutionStops() {
+		got = append(got, stop.ModelStop().ID())
+	}
+	want := []string{"v1-start", "s2", "s1", "v1-end"}
+	if len(got) != len(want) {
+		t.Fatalf("expected route %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected route %v, got %v", want, got)
+		}
+	}
+	if !solution.Vehicles()[1].IsEmpty() {
+		t.Errorf("expected vehicle v2 to be empty")
+	}
+	if solution.UnPlannedPlanUnits().Size() != 1 {
+		t.Errorf(
+			"expected 1 unplanned plan unit, got %v",
+			solution.UnPlannedPlanUnits().Size(),
+		)
+	}
+}
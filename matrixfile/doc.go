@@ -0,0 +1,22 @@
+// © 2019-present nextmv.io inc
+
+/*
+Package matrixfile reads matrices from binary files without decoding them. A
+file is memory mapped and the values are read from the mapping when they are
+needed, so large duration and distance matrices use little memory and load
+instantly.
+
+Two formats are supported:
+
+  - The nextroute matrix format, a 24 byte header followed by the values as
+    little-endian float32 in row-major order. The header is the magic `NRMX`,
+    the version 1 as a little-endian uint32 and the number of rows and
+    columns as little-endian uint64. Use [Write] to create such a file.
+  - The NumPy `.npy` format of a two dimensional array in C order with
+    little-endian float32 (`<f4`) or float64 (`<f8`) values, as written by
+    `numpy.save`.
+
+The format of a file is detected from its first bytes. A [Matrix] implements
+measure.ByIndex and can be used with nextroute.NewMeasureByIndexExpression.
+*/
+package matrixfile
@@ -0,0 +1,184 @@
+// © 2019-present nextmv.io inc
+
+package matrixfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+)
+
+const (
+	magic        = "NRMX"
+	version      = 1
+	headerLength = 24
+)
+
+// Matrix is a matrix of a memory mapped file. The values of the matrix are
+// read from the mapping, the file is not decoded.
+type Matrix struct {
+	path        string
+	data        []byte
+	values      []byte
+	rows        int
+	columns     int
+	elementSize int
+}
+
+// Open memory maps the matrix file at the given path. The format is detected
+// from the first bytes of the file. The mapping is released by
+// [Matrix.Close] or when the matrix is no longer referenced.
+func Open(path string) (*Matrix, error) {
+	data, err := mmap(path)
+	if err != nil {
+		return nil, fmt.Errorf("matrix file %s: %w", path, err)
+	}
+
+	m := &Matrix{path: path, data: data}
+	switch {
+	case bytes.HasPrefix(data, []byte(magic)):
+		err = m.parseHeader()
+	case bytes.HasPrefix(data, []byte(npyMagic)):
+		err = m.parseNpyHeader()
+	default:
+		err = fmt.Errorf("unknown format, expected `%s` or `.npy` file", magic)
+	}
+	if err == nil {
+		err = m.checkSize()
+	}
+	if err != nil {
+		_ = munmap(data)
+		return nil, fmt.Errorf("matrix file %s: %w", path, err)
+	}
+
+	runtime.SetFinalizer(m, (*Matrix).Close)
+	return m, nil
+}
+
+func (m *Matrix) parseHeader() error {
+	if len(m.data) < headerLength {
+		return fmt.Errorf("header is truncated")
+	}
+	if v := binary.LittleEndian.Uint32(m.data[4:8]); v != version {
+		return fmt.Errorf("unsupported version %d, expected %d", v, version)
+	}
+	rows := binary.LittleEndian.Uint64(m.data[8:16])
+	columns := binary.LittleEndian.Uint64(m.data[16:24])
+	if rows > math.MaxInt32 || columns > math.MaxInt32 {
+		return fmt.Errorf("matrix of %d by %d is too large", rows, columns)
+	}
+	m.rows = int(rows)
+	m.columns = int(columns)
+	m.elementSize = 4
+	m.values = m.data[headerLength:]
+	return nil
+}
+
+func (m *Matrix) checkSize() error {
+	// Compare the number of values to avoid overflowing the size in bytes.
+	available := len(m.values) / m.elementSize
+	if m.rows < 0 || m.columns < 0 ||
+		m.rows > 0 && m.columns > available/m.rows {
+		return fmt.Errorf(
+			"expected %d rows and %d columns of %d byte values, got %d bytes",
+			m.rows,
+			m.columns,
+			m.elementSize,
+			len(m.values),
+		)
+	}
+	m.values = m.values[:m.rows*m.columns*m.elementSize]
+	return nil
+}
+
+// Path returns the path of the file of the matrix.
+func (m *Matrix) Path() string {
+	return m.path
+}
+
+// Rows returns the number of rows of the matrix.
+func (m *Matrix) Rows() int {
+	return m.rows
+}
+
+// Columns returns the number of columns of the matrix.
+func (m *Matrix) Columns() int {
+	return m.columns
+}
+
+// Cost returns the value at row from and column to. It panics if the indices
+// are out of range, as measure.Matrix does.
+func (m *Matrix) Cost(from, to int) float64 {
+	if from < 0 || from >= m.rows || to < 0 || to >= m.columns {
+		panic(fmt.Sprintf(
+			"index [%d][%d] out of range of matrix %s of %d by %d",
+			from,
+			to,
+			m.path,
+			m.rows,
+			m.columns,
+		))
+	}
+	offset := (from*m.columns + to) * m.elementSize
+	if m.elementSize == 4 {
+		return float64(math.Float32frombits(
+			binary.LittleEndian.Uint32(m.values[offset:]),
+		))
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(m.values[offset:]))
+}
+
+// Close releases the mapping of the file. The matrix must not be used after
+// it is closed.
+func (m *Matrix) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	runtime.SetFinalizer(m, nil)
+	data := m.data
+	m.data = nil
+	m.values = nil
+	return munmap(data)
+}
+
+// Write writes the matrix in the nextroute matrix format. All rows must have
+// the same length, the values are stored as float32.
+func Write(w io.Writer, matrix [][]float64) error {
+	columns := 0
+	if len(matrix) > 0 {
+		columns = len(matrix[0])
+	}
+
+	buffer := bufio.NewWriter(w)
+	header := make([]byte, headerLength)
+	copy(header, magic)
+	binary.LittleEndian.PutUint32(header[4:8], version)
+	binary.LittleEndian.PutUint64(header[8:16], uint64(len(matrix)))
+	binary.LittleEndian.PutUint64(header[16:24], uint64(columns))
+	if _, err := buffer.Write(header); err != nil {
+		return err
+	}
+
+	value := make([]byte, 4)
+	for i, row := range matrix {
+		if len(row) != columns {
+			return fmt.Errorf(
+				"row %d has %d columns, expected %d",
+				i,
+				len(row),
+				columns,
+			)
+		}
+		for _, v := range row {
+			binary.LittleEndian.PutUint32(value, math.Float32bits(float32(v)))
+			if _, err := buffer.Write(value); err != nil {
+				return err
+			}
+		}
+	}
+	return buffer.Flush()
+}
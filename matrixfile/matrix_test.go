@@ -0,0 +1,107 @@
+// © 2019-present nextmv.io inc
+
+package matrixfile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nextmv-io/nextroute/matrixfile"
+)
+
+var values = [][]float64{
+	{0, 1.5, 2},
+	{3, 0, 4},
+	{5, 6.25, 0},
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// npy returns the values as a NumPy version 1.0 file of float64 values.
+func npy(header string) []byte {
+	// The header is padded with spaces and a newline to a multiple of 64.
+	length := 10 + len(header) + 1
+	header += strings.Repeat(" ", (64-length%64)%64) + "\n"
+
+	var b bytes.Buffer
+	b.WriteString("\x93NUMPY\x01\x00")
+	_ = binary.Write(&b, binary.LittleEndian, uint16(len(header)))
+	b.WriteString(header)
+	for _, row := range values {
+		for _, v := range row {
+			_ = binary.Write(&b, binary.LittleEndian, math.Float64bits(v))
+		}
+	}
+	return b.Bytes()
+}
+
+func assertValues(t *testing.T, m *matrixfile.Matrix) {
+	if m.Rows() != 3 || m.Columns() != 3 {
+		t.Fatalf("expected 3 by 3 matrix, got %v by %v", m.Rows(), m.Columns())
+	}
+	for i, row := range values {
+		for j, v := range row {
+			if got := m.Cost(i, j); got != v {
+				t.Errorf("expected %v at [%v][%v], got %v", v, i, j, got)
+			}
+		}
+	}
+}
+
+func TestMatrixFile(t *testing.T) {
+	var b bytes.Buffer
+	if err := matrixfile.Write(&b, values); err != nil {
+		t.Fatal(err)
+	}
+	m, err := matrixfile.Open(writeFile(t, "matrix.bin", b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, m)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = matrixfile.Open(writeFile(t, "truncated.bin", b.Bytes()[:b.Len()-1]))
+	if err == nil {
+		t.Error("expected truncated matrix file to fail")
+	}
+	_, err = matrixfile.Open(writeFile(t, "matrix.json", []byte("[[0]]")))
+	if err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("expected unknown format, got %v", err)
+	}
+}
+
+func TestNpyFile(t *testing.T) {
+	m, err := matrixfile.Open(writeFile(t, "matrix.npy", npy(
+		"{'descr': '<f8', 'fortran_order': False, 'shape': (3, 3), }",
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertValues(t, m)
+
+	_, err = matrixfile.Open(writeFile(t, "fortran.npy", npy(
+		"{'descr': '<f8', 'fortran_order': True, 'shape': (3, 3), }",
+	)))
+	if err == nil {
+		t.Error("expected fortran order to fail")
+	}
+	_, err = matrixfile.Open(writeFile(t, "int.npy", npy(
+		"{'descr': '<i8', 'fortran_order': False, 'shape': (3, 3), }",
+	)))
+	if err == nil {
+		t.Error("expected integer values to fail")
+	}
+}
@@ -0,0 +1,17 @@
+// © 2019-present nextmv.io inc
+
+//go:build !unix
+
+package matrixfile
+
+import "os"
+
+// mmap reads the file at the given path into memory on platforms without
+// memory mapping support.
+func mmap(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func munmap(_ []byte) error {
+	return nil
+}
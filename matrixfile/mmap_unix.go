@@ -0,0 +1,41 @@
+// © 2019-present nextmv.io inc
+
+//go:build unix
+
+package matrixfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmap maps the file at the given path read-only into memory.
+func mmap(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return []byte{}, nil
+	}
+	return syscall.Mmap(
+		int(file.Fd()),
+		0,
+		int(info.Size()),
+		syscall.PROT_READ,
+		syscall.MAP_SHARED,
+	)
+}
+
+func munmap(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,96 @@
+// © 2019-present nextmv.io inc
+
+package matrixfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const npyMagic = "\x93NUMPY"
+
+var (
+	npyDescr        = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortranOrder = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShape        = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// parseNpyHeader parses the header of a NumPy `.npy` file. The header is the
+// magic string, the major and minor version, the length of the header data
+// and the header data, a Python dict literal describing the array.
+func (m *Matrix) parseNpyHeader() error {
+	if len(m.data) < 10 {
+		return fmt.Errorf("npy header is truncated")
+	}
+	major := m.data[6]
+	var length, offset int
+	switch major {
+	case 1:
+		length = int(binary.LittleEndian.Uint16(m.data[8:10]))
+		offset = 10
+	case 2, 3:
+		if len(m.data) < 12 {
+			return fmt.Errorf("npy header is truncated")
+		}
+		length = int(binary.LittleEndian.Uint32(m.data[8:12]))
+		offset = 12
+	default:
+		return fmt.Errorf("unsupported npy version %d", major)
+	}
+	if len(m.data) < offset+length {
+		return fmt.Errorf("npy header is truncated")
+	}
+	header := string(m.data[offset : offset+length])
+
+	descr := npyDescr.FindStringSubmatch(header)
+	if descr == nil {
+		return fmt.Errorf("npy header has no descr")
+	}
+	switch descr[1] {
+	case "<f4":
+		m.elementSize = 4
+	case "<f8":
+		m.elementSize = 8
+	default:
+		return fmt.Errorf(
+			"unsupported npy descr `%s`, expected `<f4` or `<f8`",
+			descr[1],
+		)
+	}
+
+	if order := npyFortranOrder.FindStringSubmatch(header); order == nil ||
+		order[1] != "False" {
+		return fmt.Errorf("npy array must be in C order")
+	}
+
+	shape := npyShape.FindStringSubmatch(header)
+	if shape == nil {
+		return fmt.Errorf("npy header has no shape")
+	}
+	dimensions := make([]int, 0, 2)
+	for _, dimension := range strings.Split(shape[1], ",") {
+		dimension = strings.TrimSpace(dimension)
+		if dimension == "" {
+			continue
+		}
+		n, err := strconv.Atoi(dimension)
+		if err != nil {
+			return fmt.Errorf("npy shape `%s` is invalid", shape[1])
+		}
+		dimensions = append(dimensions, n)
+	}
+	if len(dimensions) != 2 {
+		return fmt.Errorf(
+			"npy array must have 2 dimensions, got shape (%s)",
+			shape[1],
+		)
+	}
+
+	m.rows = dimensions[0]
+	m.columns = dimensions[1]
+	m.values = m.data[offset+length:]
+	return nil
+}
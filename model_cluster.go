@@ -146,7 +146,7 @@ func (l *clusterImpl) updateData(
 		centroid = solutionStop.Previous().ConstraintData(l).(*centroidData)
 	}
 
-	location, err := common.NewLocation(
+	location, err := solutionStop.modelStop().Location().WithCoordinates(
 		centroid.location.Longitude()+
 			(solutionStop.modelStop().Location().Longitude()-
 				centroid.location.Longitude())/float64(nrStops),
@@ -176,7 +176,7 @@ func compactness(
 			location.Latitude()) / float64(numberOfStops+1)
 		newLong := (centroid.Longitude()*float64(numberOfStops) +
 			location.Longitude()) / float64(numberOfStops+1)
-		newLocation, err := common.NewLocation(newLong, newLat)
+		newLocation, err := location.WithCoordinates(newLong, newLat)
 		if err != nil {
 			panic(err)
 		}
@@ -185,7 +185,7 @@ func compactness(
 	}
 	compactness := 0.0
 	for _, stop := range stops {
-		dist := haversineDistance(centroid, stop.modelStop().Location())
+		dist := straightLineDistance(centroid, stop.modelStop().Location())
 		compactness += dist.Value(common.Meters) * dist.Value(common.Meters)
 	}
 	return compactness
@@ -238,7 +238,7 @@ func (l *clusterImpl) estimateDeltaScore(
 		centroid := c.location
 
 		if asConstraint {
-			distanceToCentroid := haversineDistance(
+			distanceToCentroid := straightLineDistance(
 				centroid,
 				candidate.modelStop().Location(),
 			).Value(common.Meters)
@@ -252,7 +252,7 @@ func (l *clusterImpl) estimateDeltaScore(
 					Last().
 					ConstraintData(l).(*centroidData).location
 
-				if haversineDistance(
+				if straightLineDistance(
 					centroidOtherVehicle,
 					candidate.modelStop().Location(),
 				).Value(common.Meters) < distanceToCentroid {
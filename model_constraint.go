@@ -2,6 +2,11 @@
 
 package nextroute
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // Copier is the interface that all objects that can be copied must implement.
 type Copier interface {
 	// Copy returns a copy of the object.
@@ -120,6 +125,19 @@ type ModelConstraint interface {
 // ModelConstraints is a slice of ModelConstraint.
 type ModelConstraints []ModelConstraint
 
+// ConstraintName returns the ID of the constraint if it is an [Identifier],
+// otherwise its string representation or, if it is not a [fmt.Stringer], its
+// type name.
+func ConstraintName(constraint ModelConstraint) string {
+	if identifier, ok := constraint.(Identifier); ok {
+		return identifier.ID()
+	}
+	if stringer, ok := constraint.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return reflect.TypeOf(constraint).String()
+}
+
 // ConstraintDataUpdater is a deprecated interface. Please use
 // ConstraintStopDataUpdater instead.
 type ConstraintDataUpdater interface {
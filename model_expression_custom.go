@@ -300,7 +300,7 @@ func (s *fromExpression) SetValue(
 	stop ModelStop,
 	value float64,
 ) error {
-	if stop.Model().IsLocked() {
+	if isStopLocked(stop) {
 		return fmt.Errorf(
 			fmt.Sprintf(
 				"cannot set value of stop '%v' on '%v' after model is locked",
@@ -380,7 +380,7 @@ func (s *toExpression) SetValue(
 	stop ModelStop,
 	value float64,
 ) error {
-	if stop.Model().IsLocked() {
+	if isStopLocked(stop) {
 		return fmt.Errorf(
 			"cannot set value of stop '%v' on '%v' after model is locked",
 			stop,
@@ -284,7 +284,7 @@ func (s *stopDurationExpressionImpl) SetDuration(
 	if stop == nil {
 		panic("stop is nil")
 	}
-	if stop.Model().IsLocked() {
+	if isStopLocked(stop) {
 		panic(
 			fmt.Sprintf(
 				"cannot set value on '%v' after model is locked",
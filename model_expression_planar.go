@@ -0,0 +1,88 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"fmt"
+
+	"github.com/nextmv-io/nextroute/common"
+)
+
+// NewEuclideanExpression returns a new DistanceExpression which is the
+// straight line distance between the planar locations of two stops. The
+// coordinates of planar locations are in meters.
+func NewEuclideanExpression() DistanceExpression {
+	return &planarExpression{
+		index:    NewModelExpressionIndex(),
+		name:     "euclidean",
+		distance: common.Euclidean,
+	}
+}
+
+// NewManhattanExpression returns a new DistanceExpression which is the
+// distance between the planar locations of two stops moving only parallel to
+// the axes. The coordinates of planar locations are in meters.
+func NewManhattanExpression() DistanceExpression {
+	return &planarExpression{
+		index:    NewModelExpressionIndex(),
+		name:     "manhattan",
+		distance: common.Manhattan,
+	}
+}
+
+type planarExpression struct {
+	distance func(from, to common.Location) (common.Distance, error)
+	name     string
+	index    int
+}
+
+func (p *planarExpression) HasNegativeValues() bool {
+	return false
+}
+
+func (p *planarExpression) HasPositiveValues() bool {
+	return true
+}
+
+func (p *planarExpression) String() string {
+	return fmt.Sprintf("%v[%v]",
+		p.name,
+		p.index,
+	)
+}
+
+func (p *planarExpression) Distance(
+	vehicleType ModelVehicleType,
+	from, to ModelStop,
+) common.Distance {
+	return common.NewDistance(p.Value(vehicleType, from, to), common.Meters)
+}
+
+func (p *planarExpression) Index() int {
+	return p.index
+}
+
+func (p *planarExpression) Name() string {
+	return p.name
+}
+
+func (p *planarExpression) SetName(n string) {
+	p.name = n
+}
+
+func (p *planarExpression) Value(
+	vehicle ModelVehicleType,
+	from ModelStop,
+	to ModelStop,
+) float64 {
+	// Stops without a location, typically the start or end of a vehicle,
+	// have no distance to any other stop.
+	if !from.Location().IsValid() || !to.Location().IsValid() {
+		return 0
+	}
+	d, err := p.distance(from.Location(), to.Location())
+	if err != nil {
+		panic(err)
+	}
+	return d.Value(vehicle.Model().DistanceUnit())
+}
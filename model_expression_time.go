@@ -170,7 +170,7 @@ func (s *stopTimeExpressionImpl) Time(stop ModelStop) time.Time {
 }
 
 func (s *stopTimeExpressionImpl) SetTime(stop ModelStop, t time.Time) {
-	if stop.Model().IsLocked() {
+	if isStopLocked(stop) {
 		panic(
 			fmt.Sprintf(
 				"Cannot set time for %v in expression %s in locked model",
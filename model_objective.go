@@ -5,6 +5,7 @@ package nextroute
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 
 	nmerror "github.com/nextmv-io/nextroute/common/errors"
@@ -51,9 +52,36 @@ type ModelObjectiveSum interface {
 	ModelObjective
 
 	// NewTerm adds an objective to the sum. The objective is multiplied by the
-	// factor.
+	// factor. The term has priority 0, see NewPriorityTerm.
 	NewTerm(factor float64, objective ModelObjective) (ModelObjectiveTerm, error)
 
+	// NewPriorityTerm adds an objective to the sum at the given priority
+	// level. The objective is multiplied by the factor. Terms with the same
+	// priority form a tier. Tiers are compared lexicographically, a lower
+	// priority is more important: a solution is better than another solution
+	// if the value of the first tier in which they differ is smaller. Terms of
+	// less important tiers can only break ties of more important tiers. As
+	// long as all terms have the same priority, the sum behaves like a
+	// weighted sum.
+	NewPriorityTerm(
+		priority int,
+		factor float64,
+		objective ModelObjective,
+	) (ModelObjectiveTerm, error)
+
+	// Priorities returns the distinct priorities of the terms in ascending
+	// order, the most important priority first. Each priority defines a tier.
+	Priorities() []int
+
+	// EstimateDeltaValues returns the estimated change in the value of each
+	// tier if the given move were executed on the given solution. The values
+	// are ordered by Priorities.
+	EstimateDeltaValues(move SolutionMoveStops) []float64
+
+	// IsLexicographic returns true if the terms of the sum have more than one
+	// priority.
+	IsLexicographic() bool
+
 	// Terms returns the model objective terms that are part of the sum.
 	Terms() ModelObjectiveTerms
 }
@@ -64,6 +92,9 @@ type ModelObjectiveTerm interface {
 	Factor() float64
 	// Objective returns the objective that is multiplied by the factor.
 	Objective() ModelObjective
+	// Priority returns the priority level of the term, lower priorities are
+	// more important.
+	Priority() int
 }
 
 // ModelObjectiveTerms is a slice of model objective terms.
@@ -84,8 +115,11 @@ func newModelObjectiveImpl() modelObjectiveImpl {
 
 type modelObjectiveSumImpl struct {
 	modelObjectiveImpl
-	model *modelImpl
-	terms ModelObjectiveTerms
+	model      *modelImpl
+	terms      ModelObjectiveTerms
+	priorities []int
+	// tiers holds for each term the index of its priority in priorities.
+	tiers []int
 }
 
 func (m *modelObjectiveSumImpl) ModelExpressions() ModelExpressions {
@@ -106,6 +140,9 @@ func (m *modelObjectiveSumImpl) String() string {
 		if idx > 0 {
 			fmt.Fprintf(&sb, " + ")
 		}
+		if m.IsLexicographic() {
+			fmt.Fprintf(&sb, "[%v] ", term.Priority())
+		}
 		fmt.Fprintf(&sb, "%v * %v",
 			term.Factor(),
 			term.Objective(),
@@ -122,6 +159,51 @@ func (m *modelObjectiveSumImpl) EstimateDeltaValue(move SolutionMoveStops) float
 	return estimateDeltaScore
 }
 
+func (m *modelObjectiveSumImpl) EstimateDeltaValues(move SolutionMoveStops) []float64 {
+	return m.estimateDeltaValues(move, nil)
+}
+
+// estimateDeltaValues sets values to the estimated change in the value of each
+// tier and returns it, values is reused if it has sufficient capacity.
+func (m *modelObjectiveSumImpl) estimateDeltaValues(
+	move SolutionMoveStops,
+	values []float64,
+) []float64 {
+	values = m.zeroDeltaValues(values)
+	for idx, term := range m.terms {
+		values[m.tiers[idx]] += term.Factor() * term.Objective().EstimateDeltaValue(move)
+	}
+	return values
+}
+
+// zeroDeltaValues returns values set to a zero change in the value of each
+// tier, values is reused if it has sufficient capacity.
+func (m *modelObjectiveSumImpl) zeroDeltaValues(values []float64) []float64 {
+	values = values[:0]
+	for range m.priorities {
+		values = append(values, 0.0)
+	}
+	return values
+}
+
+func (m *modelObjectiveSumImpl) Priorities() []int {
+	return slices.Clone(m.priorities)
+}
+
+func (m *modelObjectiveSumImpl) IsLexicographic() bool {
+	return len(m.priorities) > 1
+}
+
+// tierValues returns the value of each tier of the solution ordered by
+// priority.
+func (m *modelObjectiveSumImpl) tierValues(solution Solution) []float64 {
+	values := make([]float64, len(m.priorities))
+	for idx, term := range m.terms {
+		values[m.tiers[idx]] += solution.ObjectiveValue(term.Objective())
+	}
+	return values
+}
+
 func (m *modelObjectiveSumImpl) Value(_ Solution) float64 {
 	panic("use Solution.ObjectiveValue or solution.Score to query objective value")
 }
@@ -134,7 +216,15 @@ func (m *modelObjectiveSumImpl) NewTerm(
 	factor float64,
 	objective ModelObjective,
 ) (ModelObjectiveTerm, error) {
-	term := newModelObjectiveTerm(factor, objective)
+	return m.NewPriorityTerm(0, factor, objective)
+}
+
+func (m *modelObjectiveSumImpl) NewPriorityTerm(
+	priority int,
+	factor float64,
+	objective ModelObjective,
+) (ModelObjectiveTerm, error) {
+	term := newModelObjectiveTerm(priority, factor, objective)
 	for _, existingTerm := range m.terms {
 		if &existingTerm == &term {
 			return nil, nmerror.NewModelCustomizationError(fmt.Errorf(
@@ -159,6 +249,7 @@ func (m *modelObjectiveSumImpl) NewTerm(
 	}
 
 	m.terms = append(m.terms, term)
+	m.updateTiers()
 
 	if registered, ok := term.Objective().(RegisteredModelExpressions); ok {
 		for _, expression := range registered.ModelExpressions() {
@@ -183,3 +274,17 @@ func (m *modelObjectiveSumImpl) NewTerm(
 
 	return term, nil
 }
+
+// updateTiers recomputes the distinct priorities and the tier of each term.
+func (m *modelObjectiveSumImpl) updateTiers() {
+	m.priorities = m.priorities[:0]
+	for _, term := range m.terms {
+		m.priorities = append(m.priorities, term.Priority())
+	}
+	slices.Sort(m.priorities)
+	m.priorities = slices.Compact(m.priorities)
+	m.tiers = make([]int, len(m.terms))
+	for idx, term := range m.terms {
+		m.tiers[idx], _ = slices.BinarySearch(m.priorities, term.Priority())
+	}
+}
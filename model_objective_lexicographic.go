@@ -0,0 +1,116 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import "math"
+
+// lexicographicTolerance is the relative tolerance used to decide if the
+// values of a tier are equal. Small differences caused by floating point
+// arithmetic in a more important tier must not overrule a less important tier.
+const lexicographicTolerance = 1e-9
+
+// CompareObjectiveValues compares two vectors of tier values, as returned by
+// Solution.Scores or SolutionMove.Values, lexicographically. The first tier is
+// the most important tier. Returns -1 if a is smaller (better) than b, 1 if a
+// is larger (worse) than b and 0 if all tiers are equal. Values of a tier are
+// considered equal if they differ by less than a small relative tolerance.
+// Missing tiers are treated as zero.
+func CompareObjectiveValues(a, b []float64) int {
+	for idx := 0; idx < max(len(a), len(b)); idx++ {
+		valueA, valueB := 0.0, 0.0
+		if idx < len(a) {
+			valueA = a[idx]
+		}
+		if idx < len(b) {
+			valueB = b[idx]
+		}
+		if c := compareTierValue(valueA, valueB); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareTierValue(a, b float64) int {
+	scale := math.Max(1.0, math.Max(math.Abs(a), math.Abs(b)))
+	if math.Abs(a-b) <= lexicographicTolerance*scale {
+		return 0
+	}
+	if a < b {
+		return -1
+	}
+	return 1
+}
+
+// compareMoves compares the values of two moves. The tier values are compared
+// lexicographically if both moves have them, otherwise the scalar values are
+// compared.
+func compareMoves(a, b SolutionMove) int {
+	valuesA, valuesB := a.Values(), b.Values()
+	if valuesA != nil && valuesB != nil {
+		return CompareObjectiveValues(valuesA, valuesB)
+	}
+	return compareScalar(a.Value(), b.Value())
+}
+
+// isImprovementValue returns true if the delta value is an improvement. The
+// tier values are used if present, otherwise the scalar value is used.
+func isImprovementValue(value float64, values []float64) bool {
+	if values != nil {
+		return CompareObjectiveValues(values, nil) < 0
+	}
+	return value < 0
+}
+
+// compareSolutions compares the scores of two solutions of the same model.
+// The tier scores are compared lexicographically if the objective of the model
+// is lexicographic, otherwise the scalar scores are compared.
+func compareSolutions(a, b Solution) int {
+	if a.Model().Objective().IsLexicographic() {
+		return CompareObjectiveValues(a.Scores(), b.Scores())
+	}
+	return compareScalar(a.Score(), b.Score())
+}
+
+// deltaScore returns the change in score going from solution b to solution a.
+// If the objective of the model is lexicographic, the change in the value of
+// the most important tier in which the solutions differ is returned, so that
+// the delta is negative if and only if a is better than b.
+func deltaScore(a, b Solution) float64 {
+	if !a.Model().Objective().IsLexicographic() {
+		return a.Score() - b.Score()
+	}
+	scoresA, scoresB := a.Scores(), b.Scores()
+	for idx := range scoresA {
+		if compareTierValue(scoresA[idx], scoresB[idx]) != 0 {
+			return scoresA[idx] - scoresB[idx]
+		}
+	}
+	return 0
+}
+
+// compareMoveValue compares the value of the move to zero, the tier values
+// are used if present.
+func compareMoveValue(move SolutionMove) int {
+	if values := move.Values(); values != nil {
+		return CompareObjectiveValues(values, nil)
+	}
+	return compareScalar(move.Value(), 0)
+}
+
+// IsBetterSolution returns true if solution a has a better score than solution
+// b. Both solutions must be solutions of the same model. If the objective of
+// the model is lexicographic, the tier scores are compared lexicographically.
+func IsBetterSolution(a, b Solution) bool {
+	return compareSolutions(a, b) < 0
+}
+
+func compareScalar(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
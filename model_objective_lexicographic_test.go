@@ -0,0 +1,132 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/nextmv-io/nextroute"
+)
+
+func TestCompareObjectiveValues(t *testing.T) {
+	tests := []struct {
+		a, b     []float64
+		expected int
+	}{
+		{[]float64{1, 100}, []float64{2, 0}, -1},
+		{[]float64{2, 0}, []float64{1, 100}, 1},
+		{[]float64{1, 5}, []float64{1, 6}, -1},
+		{[]float64{1, 5}, []float64{1 + 1e-12, 5}, 0},
+		{[]float64{0, -1}, nil, -1},
+		{nil, nil, 0},
+	}
+	for _, test := range tests {
+		if c := nextroute.CompareObjectiveValues(test.a, test.b); c != test.expected {
+			t.Errorf(
+				"expected comparison of %v and %v to be %v, got %v",
+				test.a,
+				test.b,
+				test.expected,
+				c,
+			)
+		}
+	}
+}
+
+func TestLexicographicObjective(t *testing.T) {
+	for _, lexicographic := range []bool{false, true} {
+		model, err := createModel(singleVehiclePlanSingleStopsModel())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The unplanned penalty is tiny compared to the activation penalty,
+		// as a weighted sum it is cheaper to not plan anything.
+		activationPriority := 0
+		if lexicographic {
+			activationPriority = 1
+		}
+		_, err = model.Objective().NewPriorityTerm(
+			0,
+			1.0,
+			nextroute.NewUnPlannedObjective(
+				nextroute.NewStopExpression("unplanned", 1.0),
+			),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = model.Objective().NewPriorityTerm(
+			activationPriority,
+			1.0,
+			nextroute.NewVehiclesObjective(
+				nextroute.NewVehicleTypeValueExpression("activation", 1_000_000),
+			),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if model.Objective().IsLexicographic() != lexicographic {
+			t.Fatalf(
+				"expected lexicographic objective to be %v",
+				lexicographic,
+			)
+		}
+
+		// The tier values of a move are estimated with its value.
+		empty, err := nextroute.NewSolution(model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		planUnit := empty.UnPlannedPlanUnits().SolutionPlanUnits()[0]
+		move := empty.BestMove(context.Background(), planUnit)
+		if values := move.Values(); lexicographic {
+			moveStops, ok := move.(nextroute.SolutionMoveStops)
+			if !ok {
+				t.Fatalf("expected a move of stops, got %T", move)
+			}
+			estimated := model.Objective().EstimateDeltaValues(moveStops)
+			if !slices.Equal(values, estimated) || values[0]+values[1] != move.Value() {
+				t.Errorf(
+					"expected tier values %v summing to %v, got %v",
+					estimated,
+					move.Value(),
+					values,
+				)
+			}
+		} else if values != nil {
+			t.Errorf("expected no tier values, got %v", values)
+		}
+
+		solution, err := nextroute.NewSweepSolution(context.Background(), model)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		unplanned := solution.UnPlannedPlanUnits().Size()
+		if lexicographic && unplanned != 0 {
+			t.Errorf(
+				"expected all plan units to be planned, got %v unplanned",
+				unplanned,
+			)
+		}
+		if !lexicographic && unplanned == 0 {
+			t.Error("expected plan units to be unplanned for weighted sum")
+		}
+
+		scores := solution.Scores()
+		if lexicographic {
+			if len(scores) != 2 {
+				t.Fatalf("expected 2 tier scores, got %v", len(scores))
+			}
+			if scores[0] != 0 || scores[1] != 1_000_000 {
+				t.Errorf("expected tier scores [0 1000000], got %v", scores)
+			}
+		} else if len(scores) != 1 || scores[0] != solution.Score() {
+			t.Errorf("expected single tier score %v, got %v", solution.Score(), scores)
+		}
+	}
+}
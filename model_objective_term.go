@@ -7,18 +7,21 @@ import (
 )
 
 func newModelObjectiveTerm(
+	priority int,
 	factor float64,
 	objective ModelObjective,
 ) ModelObjectiveTerm {
 	return modelObjectiveTermImpl{
 		factor:    factor,
 		objective: objective,
+		priority:  priority,
 	}
 }
 
 type modelObjectiveTermImpl struct {
 	objective ModelObjective
 	factor    float64
+	priority  int
 }
 
 func (m modelObjectiveTermImpl) Factor() float64 {
@@ -29,6 +32,10 @@ func (m modelObjectiveTermImpl) Objective() ModelObjective {
 	return m.objective
 }
 
+func (m modelObjectiveTermImpl) Priority() int {
+	return m.priority
+}
+
 func (m modelObjectiveTermImpl) String() string {
 	return fmt.Sprintf("%v * %v", m.factor, m.objective)
 }
@@ -0,0 +1,845 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nextmv-io/nextroute/common"
+)
+
+const (
+	modelMagic = "NRMD"
+	// modelVersion is the version of the model file format. Files of another
+	// version are rejected by ReadModel.
+	modelVersion = 1
+	// builtinPrefix prefixes the names of the serialized built-in
+	// expressions, constraints and objectives.
+	builtinPrefix = "nextroute."
+)
+
+// Serializer is implemented by custom expressions, constraints, objectives
+// and data of model constructs to be serialized with a model by [WriteModel].
+// The serialized value is restored by the [Deserializer] registered with
+// [RegisterDeserializer] under the name returned by SerializerName.
+type Serializer interface {
+	// SerializerName returns the name of the deserializer which restores
+	// the serialized value.
+	SerializerName() string
+	// Serialize returns the serialized value. Expressions, constraints and
+	// objectives referenced by the value are serialized through the encoder,
+	// stops, vehicles, vehicle types and plan units are referenced by their
+	// index.
+	Serialize(encoder ModelEncoder) ([]byte, error)
+}
+
+// Deserializer restores a value serialized by a [Serializer].
+type Deserializer func(decoder ModelDecoder, data []byte) (any, error)
+
+// ModelEncoder serializes the expressions, constraints and objectives
+// referenced by a value serialized by a [Serializer]. Each expression,
+// constraint and objective is serialized once, the returned reference
+// resolves to the same restored instance in the [ModelDecoder].
+type ModelEncoder interface {
+	// Expression returns the reference of the expression, -1 for nil.
+	Expression(expression ModelExpression) (int, error)
+	// Constraint returns the reference of the constraint, -1 for nil.
+	Constraint(constraint ModelConstraint) (int, error)
+	// Objective returns the reference of the objective, -1 for nil.
+	Objective(objective ModelObjective) (int, error)
+}
+
+// ModelDecoder resolves the references of a [ModelEncoder] while a model is
+// read by [ReadModel]. The stops are restored first, followed by the
+// expressions, the vehicle types, vehicles and plan units, the constraints
+// and objectives and finally the data.
+type ModelDecoder interface {
+	// Model returns the model being restored, it is not locked yet.
+	Model() Model
+	// Expression returns the expression of the reference, nil for -1.
+	Expression(reference int) (ModelExpression, error)
+	// Constraint returns the constraint of the reference, nil for -1.
+	Constraint(reference int) (ModelConstraint, error)
+	// Objective returns the objective of the reference, nil for -1.
+	Objective(reference int) (ModelObjective, error)
+}
+
+var (
+	deserializers      = make(map[string]Deserializer)
+	deserializersMutex sync.RWMutex
+)
+
+// RegisterDeserializer registers the deserializer restoring the values of
+// the [Serializer] with the given name. It panics if a deserializer is
+// already registered with the name or if the name starts with `nextroute.`,
+// which is reserved for the built-in expressions, constraints and objectives.
+func RegisterDeserializer(name string, deserializer Deserializer) {
+	deserializersMutex.Lock()
+	defer deserializersMutex.Unlock()
+	if strings.HasPrefix(name, builtinPrefix) {
+		panic(fmt.Sprintf("deserializer name `%s` is reserved", name))
+	}
+	if _, ok := deserializers[name]; ok {
+		panic(fmt.Sprintf("deserializer `%s` already registered", name))
+	}
+	deserializers[name] = deserializer
+}
+
+func deserializer(name string) (Deserializer, error) {
+	deserializersMutex.RLock()
+	defer deserializersMutex.RUnlock()
+	d, ok := deserializers[name]
+	if !ok {
+		return nil, fmt.Errorf("no deserializer registered for `%s`", name)
+	}
+	return d, nil
+}
+
+// WriteModel writes the model to the writer in the versioned binary model
+// format, the model is locked if it is not locked yet. The model can be read
+// back with [ReadModel] to solve it again without rebuilding it.
+//
+// The stops, vehicles, vehicle types, plan units, built-in expressions,
+// constraints and objectives are serialized. Measures of expressions are
+// serialized as the matrix of their values between the stops of the model.
+// Custom expressions, constraints and objectives must implement
+// [Serializer], an error is returned otherwise. The data of the model and its
+// constructs is serialized by its [Serializer] if it implements it, otherwise
+// it is encoded with encoding/gob and its type must be registered with
+// gob.Register.
+func WriteModel(w io.Writer, model Model) error {
+	m, ok := model.(*modelImpl)
+	if !ok {
+		return fmt.Errorf("model of type %T can not be serialized", model)
+	}
+	if err := m.lock(); err != nil {
+		return err
+	}
+
+	snapshot, err := newModelEncoder(m).encode()
+	if err != nil {
+		return fmt.Errorf("writing model: %w", err)
+	}
+
+	buffer := bufio.NewWriter(w)
+	header := make([]byte, 8)
+	copy(header, modelMagic)
+	binary.LittleEndian.PutUint32(header[4:8], modelVersion)
+	if _, err := buffer.Write(header); err != nil {
+		return fmt.Errorf("writing model: %w", err)
+	}
+	if err := gob.NewEncoder(buffer).Encode(snapshot); err != nil {
+		return fmt.Errorf("writing model: %w", err)
+	}
+	return buffer.Flush()
+}
+
+// ReadModel reads a model written by [WriteModel]. The deserializers of the
+// custom expressions, constraints, objectives and data must be registered
+// with [RegisterDeserializer]. The returned model is locked.
+func ReadModel(r io.Reader) (Model, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("reading model: %w", err)
+	}
+	if string(header[:4]) != modelMagic {
+		return nil, fmt.Errorf("reading model: not a nextroute model file")
+	}
+	if version := binary.LittleEndian.Uint32(header[4:8]); version != modelVersion {
+		return nil, fmt.Errorf(
+			"reading model: unsupported version %v, expected %v",
+			version,
+			modelVersion,
+		)
+	}
+
+	var snapshot modelSnapshot
+	if err := gob.NewDecoder(reader).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("reading model: %w", err)
+	}
+	model, err := decodeModel(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("reading model: %w", err)
+	}
+	return model, nil
+}
+
+type modelSnapshot struct {
+	TimeFormat         string
+	Stops              []stopRecord
+	Expressions        []expressionRecord
+	VehicleTypes       []vehicleTypeRecord
+	Vehicles           []vehicleRecord
+	PlanUnits          []planUnitRecord
+	Components         []componentRecord
+	Constraints        []int
+	Terms              []termRecord
+	Data               dataRecord
+	SequenceSampleSize int
+}
+
+type locationRecord struct {
+	Longitude float64
+	Latitude  float64
+	Valid     bool
+	Planar    bool
+}
+
+type stopRecord struct {
+	ID            string
+	Windows       [][2]float64
+	Data          dataRecord
+	Location      locationRecord
+	EarliestStart float64
+	MeasureIndex  int
+}
+
+type vehicleTypeRecord struct {
+	ID             string
+	Data           dataRecord
+	TravelDuration int
+	Duration       int
+}
+
+type vehicleRecord struct {
+	Start       time.Time
+	ID          string
+	Stops       []int
+	Fixed       []bool
+	Data        dataRecord
+	VehicleType int
+	First       int
+	Last        int
+}
+
+type planUnitRecord struct {
+	Stops       []int
+	Arcs        [][2]int
+	DirectArcs  []bool
+	PlanUnits   []int
+	Data        dataRecord
+	IsUnits     bool
+	OneOf       bool
+	SameVehicle bool
+}
+
+type termRecord struct {
+	Component int
+	Factor    float64
+	Priority  int
+}
+
+// dataRecord is the serialized data of a model construct. The data is nil if
+// Data is nil, it is encoded with gob if Serializer is empty.
+type dataRecord struct {
+	Serializer string
+	Data       []byte
+}
+
+// gobData wraps data encoded with gob to encode its concrete type.
+type gobData struct {
+	Value any
+}
+
+type modelEncoder struct {
+	model          *modelImpl
+	expressions    []expressionRecord
+	expressionRefs map[any]int
+	components     []componentRecord
+	componentRefs  map[any]int
+	inProgress     map[any]bool
+}
+
+func newModelEncoder(model *modelImpl) *modelEncoder {
+	return &modelEncoder{
+		model:          model,
+		expressionRefs: make(map[any]int),
+		componentRefs:  make(map[any]int),
+		inProgress:     make(map[any]bool),
+	}
+}
+
+// identity returns the key identifying the value in the reference maps,
+// false if the value can not be identified.
+func identity(value any) (any, bool) {
+	if value == nil || !reflect.TypeOf(value).Comparable() {
+		return nil, false
+	}
+	return value, true
+}
+
+func (e *modelEncoder) Expression(expression ModelExpression) (int, error) {
+	if expression == nil {
+		return -1, nil
+	}
+	if value := reflect.ValueOf(expression); value.Kind() == reflect.Pointer && value.IsNil() {
+		return -1, nil
+	}
+	key, identifiable := identity(expression)
+	if identifiable {
+		if ref, ok := e.expressionRefs[key]; ok {
+			return ref, nil
+		}
+		if e.inProgress[key] {
+			return -1, fmt.Errorf("expression `%s` references itself", expression.Name())
+		}
+		e.inProgress[key] = true
+		defer delete(e.inProgress, key)
+	}
+
+	record, err := e.encodeExpression(expression)
+	if err != nil {
+		return -1, err
+	}
+	e.expressions = append(e.expressions, record)
+	ref := len(e.expressions) - 1
+	if identifiable {
+		e.expressionRefs[key] = ref
+	}
+	return ref, nil
+}
+
+func (e *modelEncoder) Constraint(constraint ModelConstraint) (int, error) {
+	if constraint == nil {
+		return -1, nil
+	}
+	return e.component(constraint)
+}
+
+func (e *modelEncoder) Objective(objective ModelObjective) (int, error) {
+	if objective == nil {
+		return -1, nil
+	}
+	return e.component(objective)
+}
+
+// component returns the reference of a constraint or objective. A construct
+// which is both a constraint and an objective is serialized once.
+func (e *modelEncoder) component(component any) (int, error) {
+	key, identifiable := identity(component)
+	if identifiable {
+		if ref, ok := e.componentRefs[key]; ok {
+			return ref, nil
+		}
+		if e.inProgress[key] {
+			return -1, fmt.Errorf("%T references itself", component)
+		}
+		e.inProgress[key] = true
+		defer delete(e.inProgress, key)
+	}
+
+	record, err := e.encodeComponent(component)
+	if err != nil {
+		return -1, err
+	}
+	e.components = append(e.components, record)
+	ref := len(e.components) - 1
+	if identifiable {
+		e.componentRefs[key] = ref
+	}
+	return ref, nil
+}
+
+func (e *modelEncoder) data(data any) (dataRecord, error) {
+	if data == nil {
+		return dataRecord{}, nil
+	}
+	if serializer, ok := data.(Serializer); ok {
+		bytes, err := serializer.Serialize(e)
+		if err != nil {
+			return dataRecord{}, err
+		}
+		return dataRecord{
+			Serializer: serializer.SerializerName(),
+			Data:       bytes,
+		}, nil
+	}
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(&gobData{Value: data}); err != nil {
+		return dataRecord{}, fmt.Errorf(
+			"data of type %T does not implement nextroute.Serializer"+
+				" and can not be encoded with gob, register the type with"+
+				" gob.Register: %w",
+			data,
+			err,
+		)
+	}
+	return dataRecord{Data: buffer.Bytes()}, nil
+}
+
+func (e *modelEncoder) encode() (modelSnapshot, error) {
+	m := e.model
+	snapshot := modelSnapshot{
+		TimeFormat:         m.timeFormat,
+		SequenceSampleSize: m.sequenceSampleSize,
+	}
+
+	snapshot.Stops = make([]stopRecord, len(m.stops))
+	for idx, modelStop := range m.stops {
+		stop := modelStop.(*stopImpl)
+		data, err := e.data(stop.Data())
+		if err != nil {
+			return modelSnapshot{}, fmt.Errorf("stop `%s`: %w", stop.ID(), err)
+		}
+		snapshot.Stops[idx] = stopRecord{
+			ID: stop.id,
+			Location: locationRecord{
+				Longitude: stop.location.Longitude(),
+				Latitude:  stop.location.Latitude(),
+				Valid:     stop.location.IsValid(),
+				Planar:    stop.location.IsPlanar(),
+			},
+			MeasureIndex:  stop.measureIndex,
+			Windows:       stop.windows,
+			EarliestStart: stop.earliestStartTime,
+			Data:          data,
+		}
+	}
+
+	snapshot.VehicleTypes = make([]vehicleTypeRecord, len(m.vehicleTypes))
+	for idx, modelVehicleType := range m.vehicleTypes {
+		vehicleType := modelVehicleType.(*vehicleTypeImpl)
+		travelDuration, err := e.Expression(vehicleType.travelDuration)
+		if err != nil {
+			return modelSnapshot{}, fmt.Errorf("vehicle type `%s`: %w", vehicleType.ID(), err)
+		}
+		duration, err := e.Expression(vehicleType.duration)
+		if err != nil {
+			return modelSnapshot{}, fmt.Errorf("vehicle type `%s`: %w", vehicleType.ID(), err)
+		}
+		data, err := e.data(vehicleType.Data())
+		if err != nil {
+			return modelSnapshot{}, fmt.Errorf("vehicle type `%s`: %w", vehicleType.ID(), err)
+		}
+		snapshot.VehicleTypes[idx] = vehicleTypeRecord{
+			ID:             vehicleType.id,
+			TravelDuration: travelDuration,
+			Duration:       duration,
+			Data:           data,
+		}
+	}
+
+	snapshot.Vehicles = make([]vehicleRecord, len(m.vehicles))
+	for idx, modelVehicle := range m.vehicles {
+		vehicle := modelVehicle.(*modelVehicleImpl)
+		data, err := e.data(vehicle.Data())
+		if err != nil {
+			return modelSnapshot{}, fmt.Errorf("vehicle `%s`: %w", vehicle.ID(), err)
+		}
+		record := vehicleRecord{
+			ID:          vehicle.id,
+			VehicleType: vehicle.vehicleType.Index(),
+			Start:       vehicle.start,
+			First:       vehicle.First().Index(),
+			Last:        vehicle.Last().Index(),
+			Data:        data,
+		}
+		for _, stop := range vehicle.Stops() {
+			record.Stops = append(record.Stops, stop.Index())
+			record.Fixed = append(record.Fixed, stop.IsFixed())
+		}
+		snapshot.Vehicles[idx] = record
+	}
+
+	snapshot.PlanUnits = make([]planUnitRecord, len(m.planUnits))
+	for idx, planUnit := range m.planUnits {
+		data, err := e.data(planUnit.Data())
+		if err != nil {
+			return modelSnapshot{}, fmt.Errorf("plan unit %d: %w", idx, err)
+		}
+		record := planUnitRecord{Data: data}
+		switch unit := planUnit.(type) {
+		case *planMultipleStopsImpl:
+			for _, stop := range unit.stops {
+				record.Stops = append(record.Stops, stop.Index())
+			}
+			for _, arc := range unit.dag.Arcs() {
+				record.Arcs = append(
+					record.Arcs,
+					[2]int{arc.Origin().Index(), arc.Destination().Index()},
+				)
+				record.DirectArcs = append(record.DirectArcs, arc.IsDirect())
+			}
+		case *planUnitsUnitImpl:
+			record.IsUnits = true
+			record.OneOf = unit.planOneOf
+			record.SameVehicle = unit.sameVehicle
+			for _, child := range unit.planUnits {
+				record.PlanUnits = append(record.PlanUnits, child.Index())
+			}
+		default:
+			return modelSnapshot{}, fmt.Errorf(
+				"plan unit %d of type %T can not be serialized",
+				idx,
+				planUnit,
+			)
+		}
+		snapshot.PlanUnits[idx] = record
+	}
+
+	for _, constraint := range m.constraints {
+		ref, err := e.Constraint(constraint)
+		if err != nil {
+			return modelSnapshot{}, err
+		}
+		snapshot.Constraints = append(snapshot.Constraints, ref)
+	}
+	for _, term := range m.objective.Terms() {
+		ref, err := e.Objective(term.Objective())
+		if err != nil {
+			return modelSnapshot{}, err
+		}
+		snapshot.Terms = append(snapshot.Terms, termRecord{
+			Component: ref,
+			Factor:    term.Factor(),
+			Priority:  term.Priority(),
+		})
+	}
+
+	data, err := e.data(m.Data())
+	if err != nil {
+		return modelSnapshot{}, fmt.Errorf("model: %w", err)
+	}
+	snapshot.Data = data
+
+	snapshot.Expressions = e.expressions
+	snapshot.Components = e.components
+	return snapshot, nil
+}
+
+type modelDecoder struct {
+	model       *modelImpl
+	expressions []ModelExpression
+	components  []any
+}
+
+func (d *modelDecoder) Model() Model {
+	return d.model
+}
+
+func (d *modelDecoder) Expression(reference int) (ModelExpression, error) {
+	if reference == -1 {
+		return nil, nil
+	}
+	if reference < 0 || reference >= len(d.expressions) {
+		return nil, fmt.Errorf("expression reference %d is out of range", reference)
+	}
+	return d.expressions[reference], nil
+}
+
+func (d *modelDecoder) Constraint(reference int) (ModelConstraint, error) {
+	if reference == -1 {
+		return nil, nil
+	}
+	if reference < 0 || reference >= len(d.components) {
+		return nil, fmt.Errorf("constraint reference %d is out of range", reference)
+	}
+	constraint, ok := d.components[reference].(ModelConstraint)
+	if !ok {
+		return nil, fmt.Errorf(
+			"reference %d of type %T is not a constraint",
+			reference,
+			d.components[reference],
+		)
+	}
+	return constraint, nil
+}
+
+func (d *modelDecoder) Objective(reference int) (ModelObjective, error) {
+	if reference == -1 {
+		return nil, nil
+	}
+	if reference < 0 || reference >= len(d.components) {
+		return nil, fmt.Errorf("objective reference %d is out of range", reference)
+	}
+	objective, ok := d.components[reference].(ModelObjective)
+	if !ok {
+		return nil, fmt.Errorf(
+			"reference %d of type %T is not an objective",
+			reference,
+			d.components[reference],
+		)
+	}
+	return objective, nil
+}
+
+func (d *modelDecoder) data(record dataRecord) (any, error) {
+	if record.Serializer != "" {
+		return d.deserialize(record.Serializer, record.Data)
+	}
+	if record.Data == nil {
+		return nil, nil
+	}
+	var data gobData
+	if err := gob.NewDecoder(bytes.NewReader(record.Data)).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data.Value, nil
+}
+
+func decodeLocation(record locationRecord) (common.Location, error) {
+	switch {
+	case !record.Valid:
+		return common.NewInvalidLocation(), nil
+	case record.Planar:
+		return common.NewPlanarLocation(record.Longitude, record.Latitude)
+	default:
+		return common.NewLocation(record.Longitude, record.Latitude)
+	}
+}
+
+func decodeModel(snapshot modelSnapshot) (Model, error) {
+	model, err := NewModel()
+	if err != nil {
+		return nil, err
+	}
+	m := model.(*modelImpl)
+	m.timeFormat = snapshot.TimeFormat
+	m.sequenceSampleSize = snapshot.SequenceSampleSize
+	d := &modelDecoder{model: m}
+
+	for _, record := range snapshot.Stops {
+		location, err := decodeLocation(record.Location)
+		if err != nil {
+			return nil, fmt.Errorf("stop `%s`: %w", record.ID, err)
+		}
+		modelStop, err := m.NewStop(location)
+		if err != nil {
+			return nil, err
+		}
+		stop := modelStop.(*stopImpl)
+		stop.id = record.ID
+		stop.measureIndex = record.MeasureIndex
+		stop.earliestStartTime = record.EarliestStart
+		if len(record.Windows) > 0 {
+			checker, err := common.NewIntervalCheckerSliceLookup(record.Windows)
+			if err != nil {
+				return nil, fmt.Errorf("stop `%s`: %w", record.ID, err)
+			}
+			stop.windows = record.Windows
+			stop.windowChecker = checker
+		}
+	}
+
+	d.expressions = make([]ModelExpression, 0, len(snapshot.Expressions))
+	for idx, record := range snapshot.Expressions {
+		expression, err := d.decodeExpression(record)
+		if err != nil {
+			return nil, fmt.Errorf("expression %d `%s`: %w", idx, record.Name, err)
+		}
+		d.expressions = append(d.expressions, expression)
+	}
+
+	for _, record := range snapshot.VehicleTypes {
+		travelDuration, err := d.Expression(record.TravelDuration)
+		if err != nil {
+			return nil, fmt.Errorf("vehicle type `%s`: %w", record.ID, err)
+		}
+		duration, err := d.Expression(record.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("vehicle type `%s`: %w", record.ID, err)
+		}
+		var travelDurationExpression TimeDependentDurationExpression
+		if travelDuration != nil {
+			e, ok := travelDuration.(TimeDependentDurationExpression)
+			if !ok {
+				return nil, fmt.Errorf(
+					"vehicle type `%s`: travel duration %T is not time dependent",
+					record.ID,
+					travelDuration,
+				)
+			}
+			travelDurationExpression = e
+		}
+		var durationExpression DurationExpression
+		if duration != nil {
+			e, ok := duration.(DurationExpression)
+			if !ok {
+				return nil, fmt.Errorf(
+					"vehicle type `%s`: duration %T is not a duration expression",
+					record.ID,
+					duration,
+				)
+			}
+			durationExpression = e
+		}
+		vehicleType, err := m.NewVehicleType(travelDurationExpression, durationExpression)
+		if err != nil {
+			return nil, err
+		}
+		vehicleType.SetID(record.ID)
+	}
+
+	for _, record := range snapshot.Vehicles {
+		if record.VehicleType < 0 || record.VehicleType >= len(m.vehicleTypes) {
+			return nil, fmt.Errorf("vehicle `%s`: vehicle type out of range", record.ID)
+		}
+		first, err := m.Stop(record.First)
+		if err != nil {
+			return nil, fmt.Errorf("vehicle `%s`: %w", record.ID, err)
+		}
+		last, err := m.Stop(record.Last)
+		if err != nil {
+			return nil, fmt.Errorf("vehicle `%s`: %w", record.ID, err)
+		}
+		vehicle, err := m.NewVehicle(
+			m.vehicleTypes[record.VehicleType],
+			record.Start,
+			first,
+			last,
+		)
+		if err != nil {
+			return nil, err
+		}
+		vehicle.SetID(record.ID)
+	}
+
+	for idx, record := range snapshot.PlanUnits {
+		if err := d.decodePlanUnit(record); err != nil {
+			return nil, fmt.Errorf("plan unit %d: %w", idx, err)
+		}
+	}
+
+	// Stops are added to the vehicles once they belong to a plan unit.
+	for idx, record := range snapshot.Vehicles {
+		for i, index := range record.Stops {
+			stop, err := m.Stop(index)
+			if err != nil {
+				return nil, fmt.Errorf("vehicle `%s`: %w", record.ID, err)
+			}
+			if err := m.vehicles[idx].AddStop(stop, record.Fixed[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	d.components = make([]any, 0, len(snapshot.Components))
+	for idx, record := range snapshot.Components {
+		component, err := d.decodeComponent(record)
+		if err != nil {
+			return nil, fmt.Errorf("component %d `%s`: %w", idx, record.Type, err)
+		}
+		d.components = append(d.components, component)
+	}
+	for _, ref := range snapshot.Constraints {
+		constraint, err := d.Constraint(ref)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.AddConstraint(constraint); err != nil {
+			return nil, err
+		}
+	}
+	for _, term := range snapshot.Terms {
+		objective, err := d.Objective(term.Component)
+		if err != nil {
+			return nil, err
+		}
+		_, err = m.objective.NewPriorityTerm(term.Priority, term.Factor, objective)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for idx, record := range snapshot.Stops {
+		data, err := d.data(record.Data)
+		if err != nil {
+			return nil, fmt.Errorf("data of stop `%s`: %w", record.ID, err)
+		}
+		m.stops[idx].SetData(data)
+	}
+	for idx, record := range snapshot.VehicleTypes {
+		data, err := d.data(record.Data)
+		if err != nil {
+			return nil, fmt.Errorf("data of vehicle type `%s`: %w", record.ID, err)
+		}
+		m.vehicleTypes[idx].SetData(data)
+	}
+	for idx, record := range snapshot.Vehicles {
+		data, err := d.data(record.Data)
+		if err != nil {
+			return nil, fmt.Errorf("data of vehicle `%s`: %w", record.ID, err)
+		}
+		m.vehicles[idx].SetData(data)
+	}
+	for idx, record := range snapshot.PlanUnits {
+		data, err := d.data(record.Data)
+		if err != nil {
+			return nil, fmt.Errorf("data of plan unit %d: %w", idx, err)
+		}
+		m.planUnits[idx].SetData(data)
+	}
+	data, err := d.data(snapshot.Data)
+	if err != nil {
+		return nil, fmt.Errorf("data of model: %w", err)
+	}
+	m.SetData(data)
+
+	if err := m.lock(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (d *modelDecoder) decodePlanUnit(record planUnitRecord) error {
+	m := d.model
+	if record.IsUnits {
+		planUnits := make(ModelPlanUnits, len(record.PlanUnits))
+		for idx, index := range record.PlanUnits {
+			if index < 0 || index >= len(m.planUnits) {
+				return fmt.Errorf("plan unit reference %d is out of range", index)
+			}
+			planUnits[idx] = m.planUnits[index]
+		}
+		if record.OneOf {
+			_, err := m.NewPlanOneOfPlanUnits(planUnits...)
+			return err
+		}
+		_, err := m.NewPlanAllPlanUnits(record.SameVehicle, planUnits...)
+		return err
+	}
+
+	stops := make(ModelStops, len(record.Stops))
+	for idx, index := range record.Stops {
+		stop, err := m.Stop(index)
+		if err != nil {
+			return err
+		}
+		stops[idx] = stop
+	}
+	if len(stops) == 1 {
+		_, err := m.NewPlanSingleStop(stops[0])
+		return err
+	}
+	dag := NewDirectedAcyclicGraph()
+	for idx, arc := range record.Arcs {
+		origin, err := m.Stop(arc[0])
+		if err != nil {
+			return err
+		}
+		destination, err := m.Stop(arc[1])
+		if err != nil {
+			return err
+		}
+		if record.DirectArcs[idx] {
+			err = dag.AddDirectArc(origin, destination)
+		} else {
+			err = dag.AddArc(origin, destination)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := m.NewPlanMultipleStops(stops, dag)
+	return err
+}
@@ -0,0 +1,1048 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/nextmv-io/nextroute/common"
+	"github.com/nextmv-io/sdk/measure"
+)
+
+// expressionRecord is a serialized expression. Built-in expressions use the
+// fields relevant to their type, custom expressions store the data returned
+// by their [Serializer].
+type expressionRecord struct {
+	Time         time.Time
+	Type         string
+	Name         string
+	Data         []byte
+	Expressions  []int
+	Values       []float64
+	HasValue     []bool
+	ValuesByKey  map[int]float64
+	FromTo       map[int]map[int]float64
+	TypeFromTo   map[int]map[int]map[int]float64
+	Intervals    [][2]float64
+	Keys         []int
+	Points       [][2]float64
+	Matrix       [][]float64
+	Value        float64
+	Unit         int
+	HasPositive  bool
+	HasNegative  bool
+	Triangular   bool
+	DefaultValue float64
+}
+
+// componentRecord is a serialized constraint or objective. Built-in
+// constraints and objectives use the fields relevant to their type, custom
+// constraints and objectives store the data returned by their [Serializer].
+type componentRecord struct {
+	Type                  string
+	Name                  string
+	Data                  []byte
+	Expressions           []int
+	StopAttributes        map[int][]string
+	VehicleTypeAttributes map[int][]string
+	Successors            map[int][]int
+	Insert                map[int]MixItem
+	Remove                map[int]MixItem
+	Value                 float64
+	TemporalReference     int
+	IncludeFirst          bool
+	IncludeLast           bool
+}
+
+const (
+	constantExpressionType              = builtinPrefix + "constant"
+	fromExpressionType                  = builtinPrefix + "from_stop"
+	toExpressionType                    = builtinPrefix + "stop"
+	vehicleTypeExpressionType           = builtinPrefix + "vehicle_type"
+	vehicleTypeDistanceExpressionType   = builtinPrefix + "vehicle_type_distance"
+	fromToExpressionType                = builtinPrefix + "from_to"
+	vehicleTypeFromToExpressionType     = builtinPrefix + "vehicle_type_from_to"
+	distanceExpressionType              = builtinPrefix + "distance"
+	scaledDurationExpressionType        = builtinPrefix + "scaled_duration"
+	stopDurationExpressionType          = builtinPrefix + "stop_duration"
+	vehicleTypeDurationExpressionType   = builtinPrefix + "vehicle_type_duration"
+	constantDurationExpressionType      = builtinPrefix + "constant_duration"
+	travelDurationExpressionType        = builtinPrefix + "travel_duration"
+	haversineExpressionType             = builtinPrefix + "haversine"
+	euclideanExpressionType             = builtinPrefix + "euclidean"
+	manhattanExpressionType             = builtinPrefix + "manhattan"
+	measureByIndexExpressionType        = builtinPrefix + "measure_by_index"
+	measureByPointExpressionType        = builtinPrefix + "measure_by_point"
+	sumExpressionType                   = builtinPrefix + "sum"
+	timeExpressionType                  = builtinPrefix + "time"
+	stopTimeExpressionType              = builtinPrefix + "stop_time"
+	timeDependentExpressionType         = builtinPrefix + "time_dependent"
+	timeIndependentExpressionType       = builtinPrefix + "time_independent"
+	termExpressionType                  = builtinPrefix + "term"
+	composedExpressionType              = builtinPrefix + "composed_per_vehicle_type"
+	attributesConstraintType            = builtinPrefix + "attributes"
+	maximumDurationConstraintType       = builtinPrefix + "maximum_duration"
+	maximumStopsConstraintType          = builtinPrefix + "maximum_stops"
+	maximumTravelDurationConstraintType = builtinPrefix + "maximum_travel_duration"
+	maximumWaitStopConstraintType       = builtinPrefix + "maximum_wait_stop"
+	maximumWaitVehicleConstraintType    = builtinPrefix + "maximum_wait_vehicle"
+	successorConstraintType             = builtinPrefix + "successor"
+	noMixConstraintType                 = builtinPrefix + "no_mix"
+	maximumType                         = builtinPrefix + "maximum"
+	latestType                          = builtinPrefix + "latest"
+	clusterType                         = builtinPrefix + "cluster"
+	earlinessObjectiveType              = builtinPrefix + "earliness"
+	expressionObjectiveType             = builtinPrefix + "expression"
+	minStopsObjectiveType               = builtinPrefix + "min_stops"
+	balanceObjectiveType                = builtinPrefix + "stop_balance"
+	travelDurationObjectiveType         = builtinPrefix + "travel_duration"
+	unplannedObjectiveType              = builtinPrefix + "unplanned"
+	vehiclesObjectiveType               = builtinPrefix + "vehicles"
+	vehiclesDurationObjectiveType       = builtinPrefix + "vehicles_duration"
+)
+
+func (e *modelEncoder) references(expressions ...ModelExpression) ([]int, error) {
+	refs := make([]int, len(expressions))
+	for idx, expression := range expressions {
+		ref, err := e.Expression(expression)
+		if err != nil {
+			return nil, err
+		}
+		refs[idx] = ref
+	}
+	return refs, nil
+}
+
+func (e *modelEncoder) encodeExpression(expression ModelExpression) (expressionRecord, error) {
+	record := expressionRecord{Name: expression.Name()}
+	var refs []ModelExpression
+	switch x := expression.(type) {
+	case *constantExpression:
+		record.Type = constantExpressionType
+		record.Value = x.value
+	case *fromExpression:
+		record.Type = fromExpressionType
+		record.Values = x.values
+		record.DefaultValue = x.defaultValue
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *toExpression:
+		record.Type = toExpressionType
+		record.Values = x.values
+		record.DefaultValue = x.defaultValue
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *vehicleTypeExpressionImpl:
+		record.Type = vehicleTypeExpressionType
+		record.Values = x.values
+		record.DefaultValue = x.defaultValue
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *vehicleTypeDistanceExpressionImpl:
+		record.Type = vehicleTypeDistanceExpressionType
+		record.Values = make([]float64, len(x.values))
+		for idx, value := range x.values {
+			record.Values[idx] = value.Value(common.Meters)
+		}
+		record.DefaultValue = x.defaultValue.Value(common.Meters)
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *fromToExpression:
+		record.Type = fromToExpressionType
+		record.FromTo = x.values
+		record.DefaultValue = x.defaultValue
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *vehicleTypeFromToExpression:
+		record.Type = vehicleTypeFromToExpressionType
+		record.TypeFromTo = x.values
+		record.DefaultValue = x.defaultValue
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *distanceExpression:
+		record.Type = distanceExpressionType
+		record.Unit = int(x.unit)
+		refs = ModelExpressions{x.modelExpression}
+	case *scaledDurationExpressionImpl:
+		record.Type = scaledDurationExpressionType
+		record.Value = x.multiplier
+		refs = ModelExpressions{x.expression}
+	case *stopDurationExpressionImpl:
+		record.Type = stopDurationExpressionType
+		record.ValuesByKey = x.values
+		record.DefaultValue = x.defaultValue
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *vehicleTypeDurationExpressionImpl:
+		record.Type = vehicleTypeDurationExpressionType
+		record.ValuesByKey = x.values
+		record.DefaultValue = x.defaultValue
+		record.HasPositive, record.HasNegative = x.hasPositiveValues, x.hasNegativeValues
+	case *constantDurationExpressionImpl:
+		record.Type = constantDurationExpressionType
+		record.Value = float64(x.duration)
+	case *travelDurationExpression:
+		record.Type = travelDurationExpressionType
+		record.Value = x.speed.Value(common.MetersPerSecond)
+		refs = ModelExpressions{x.distanceExpression}
+	case *haversineExpression:
+		record.Type = haversineExpressionType
+	case *planarExpression:
+		switch reflect.ValueOf(x.distance).Pointer() {
+		case reflect.ValueOf(common.Euclidean).Pointer():
+			record.Type = euclideanExpressionType
+		case reflect.ValueOf(common.Manhattan).Pointer():
+			record.Type = manhattanExpressionType
+		default:
+			return expressionRecord{}, fmt.Errorf(
+				"planar expression `%s` has an unknown distance function",
+				x.name,
+			)
+		}
+	case *measureByIndexExpression:
+		record.Type = measureByIndexExpressionType
+		// Stops without a valid measure index, such as the first and last
+		// stop of a vehicle without a location, are left out.
+		for _, stop := range e.model.stops {
+			index := stop.(*stopImpl).measureIndex
+			if _, ok := measureCost(func() float64 { return x.measure.Cost(index, index) }); ok {
+				record.Keys = append(record.Keys, index)
+			}
+		}
+		slices.Sort(record.Keys)
+		record.Keys = slices.Compact(record.Keys)
+		record.Matrix = make([][]float64, len(record.Keys))
+		for i, from := range record.Keys {
+			record.Matrix[i] = make([]float64, len(record.Keys))
+			for j, to := range record.Keys {
+				cost, ok := measureCost(func() float64 { return x.measure.Cost(from, to) })
+				if !ok {
+					return expressionRecord{}, fmt.Errorf(
+						"measure of expression `%s` fails from %d to %d",
+						x.name,
+						from,
+						to,
+					)
+				}
+				record.Matrix[i][j] = cost
+			}
+		}
+	case *measureByPointExpression:
+		record.Type = measureByPointExpressionType
+		seen := make(map[[2]float64]bool, len(e.model.stops))
+		for _, stop := range e.model.stops {
+			location := stop.Location()
+			point := [2]float64{location.Longitude(), location.Latitude()}
+			if !seen[point] {
+				seen[point] = true
+				record.Points = append(record.Points, point)
+			}
+		}
+		record.Matrix = make([][]float64, len(record.Points))
+		for i, from := range record.Points {
+			record.Matrix[i] = make([]float64, len(record.Points))
+			for j, to := range record.Points {
+				cost, ok := measureCost(func() float64 {
+					return x.measure.Cost(
+						measure.Point{from[0], from[1]},
+						measure.Point{to[0], to[1]},
+					)
+				})
+				if !ok {
+					return expressionRecord{}, fmt.Errorf(
+						"measure of expression `%s` fails from %v to %v",
+						x.name,
+						from,
+						to,
+					)
+				}
+				record.Matrix[i][j] = cost
+			}
+		}
+	case *sumExpressionImpl:
+		record.Type = sumExpressionType
+		refs = x.expressions
+	case *timeExpressionImpl:
+		record.Type = timeExpressionType
+		record.Time = x.epoch
+		refs = ModelExpressions{x.expression}
+	case *stopTimeExpressionImpl:
+		record.Type = stopTimeExpressionType
+		record.Time = x.defaultTime
+		record.Values = x.values
+		record.HasValue = x.hasValue
+		record.DefaultValue = x.defaultValue
+	case *timeDependentDurationExpressionImpl:
+		record.Type = timeDependentExpressionType
+		record.Triangular = x.satisfiesTriangleInequality
+		refs = ModelExpressions{x.defaultExpression}
+		if x.startElement != nil {
+			for element := x.startElement.next; element.next != nil; element = element.next {
+				record.Intervals = append(
+					record.Intervals,
+					[2]float64{element.start, element.end},
+				)
+				refs = append(refs, element.expression)
+			}
+		}
+	case *timeIndependentDurationExpressionImpl:
+		record.Type = timeIndependentExpressionType
+		record.Triangular = x.satisfiesTriangleInequality
+		refs = ModelExpressions{x.expression}
+	case *termExpression:
+		record.Type = termExpressionType
+		record.Value = x.factor
+		refs = ModelExpressions{x.expression}
+	case *composedPerVehicleTypeExpressionImpl:
+		record.Type = composedExpressionType
+		refs = append(ModelExpressions{x.defaultExpression}, x.expressions...)
+	case *binaryExpression:
+		return expressionRecord{}, fmt.Errorf(
+			"operator expression `%s` can not be serialized,"+
+				" its operator is a function",
+			x.name,
+		)
+	case Serializer:
+		data, err := x.Serialize(e)
+		if err != nil {
+			return expressionRecord{}, err
+		}
+		record.Type = x.SerializerName()
+		record.Data = data
+	default:
+		return expressionRecord{}, fmt.Errorf(
+			"expression `%s` of type %T can not be serialized,"+
+				" it does not implement nextroute.Serializer",
+			expression.Name(),
+			expression,
+		)
+	}
+	expressions, err := e.references(refs...)
+	if err != nil {
+		return expressionRecord{}, err
+	}
+	record.Expressions = expressions
+	return record, nil
+}
+
+func (e *modelEncoder) encodeComponent(component any) (componentRecord, error) {
+	record := componentRecord{}
+	var refs []ModelExpression
+	switch x := component.(type) {
+	case *attributesConstraintImpl:
+		record.Type = attributesConstraintType
+		record.Name = x.name
+		record.StopAttributes = x.stopAttributes
+		record.VehicleTypeAttributes = x.vehicleTypeAttributes
+	case *maximumDurationConstraintImpl:
+		record.Type = maximumDurationConstraintType
+		record.Name = x.name
+		refs = ModelExpressions{x.maximum}
+	case *maximumStopsConstraintImpl:
+		record.Type = maximumStopsConstraintType
+		record.Name = x.name
+		refs = ModelExpressions{x.maximumStops}
+	case *maximumTravelDurationConstraintImpl:
+		record.Type = maximumTravelDurationConstraintType
+		record.Name = x.name
+		refs = ModelExpressions{x.maximum}
+	case *maximumWaitStopConstraintImpl:
+		record.Type = maximumWaitStopConstraintType
+		record.Name = x.name
+		refs = ModelExpressions{x.maxima}
+	case *maximumWaitVehicleConstraintImpl:
+		record.Type = maximumWaitVehicleConstraintType
+		record.Name = x.name
+		refs = ModelExpressions{x.maxima}
+	case *successorConstraintImpl:
+		record.Type = successorConstraintType
+		record.Name = x.name
+		record.Successors = make(map[int][]int, len(x.disallowedSuccessors))
+		for stop, successors := range x.disallowedSuccessors {
+			for _, successor := range successors {
+				record.Successors[stop.Index()] = append(
+					record.Successors[stop.Index()],
+					successor.Index(),
+				)
+			}
+		}
+	case *noMixConstraintImpl:
+		record.Type = noMixConstraintType
+		record.Name = x.name
+		record.Insert = make(map[int]MixItem, len(x.insert))
+		for stop, item := range x.insert {
+			record.Insert[stop.Index()] = item
+		}
+		record.Remove = make(map[int]MixItem, len(x.remove))
+		for stop, item := range x.remove {
+			record.Remove[stop.Index()] = item
+		}
+	case *maximumImpl:
+		record.Type = maximumType
+		record.Name = x.name
+		record.Value = x.penaltyOffset
+		refs = ModelExpressions{x.expressions[0], x.maximum}
+	case *latestImpl:
+		record.Type = latestType
+		record.Name = x.name
+		record.TemporalReference = int(x.temporalReference)
+		refs = ModelExpressions{x.latest, x.latenessFactor}
+	case *clusterImpl:
+		record.Type = clusterType
+		record.Name = x.name
+		record.IncludeFirst = x.includeFirst
+		record.IncludeLast = x.includeLast
+	case *earlinessObjectiveImpl:
+		record.Type = earlinessObjectiveType
+		record.TemporalReference = int(x.temporalReference)
+		refs = ModelExpressions{x.targetTime, x.earlinessFactor}
+	case *expressionObjectiveImpl:
+		record.Type = expressionObjectiveType
+		refs = ModelExpressions{x.expression}
+	case *minStopsObjectiveImpl:
+		record.Type = minStopsObjectiveType
+		refs = ModelExpressions{x.minStops, x.minStopsPenalty}
+	case *balanceObjectiveImpl:
+		record.Type = balanceObjectiveType
+	case *travelDurationObjectiveImpl:
+		record.Type = travelDurationObjectiveType
+	case *unplannedObjectiveImpl:
+		record.Type = unplannedObjectiveType
+		refs = ModelExpressions{x.expression}
+	case *vehiclesObjectiveImpl:
+		record.Type = vehiclesObjectiveType
+		refs = ModelExpressions{x.expression}
+	case *vehiclesDurationObjectiveImpl:
+		record.Type = vehiclesDurationObjectiveType
+	case Serializer:
+		data, err := x.Serialize(e)
+		if err != nil {
+			return componentRecord{}, err
+		}
+		record.Type = x.SerializerName()
+		record.Data = data
+	default:
+		kind := "objective"
+		if _, ok := component.(ModelConstraint); ok {
+			kind = "constraint"
+		}
+		return componentRecord{}, fmt.Errorf(
+			"%s of type %T can not be serialized,"+
+				" it does not implement nextroute.Serializer",
+			kind,
+			component,
+		)
+	}
+	expressions, err := e.references(refs...)
+	if err != nil {
+		return componentRecord{}, err
+	}
+	record.Expressions = expressions
+	return record, nil
+}
+
+// expressionAs returns the referenced expression as the given expression
+// type, the zero value of the type if the reference is -1.
+func expressionAs[T any](d *modelDecoder, refs []int, position int) (T, error) {
+	var zero T
+	if position >= len(refs) {
+		return zero, fmt.Errorf("expression %d is missing", position)
+	}
+	expression, err := d.Expression(refs[position])
+	if err != nil || expression == nil {
+		return zero, err
+	}
+	typed, ok := expression.(T)
+	if !ok {
+		return zero, fmt.Errorf(
+			"expression `%s` of type %T is not a %v",
+			expression.Name(),
+			expression,
+			reflect.TypeOf((*T)(nil)).Elem(),
+		)
+	}
+	return typed, nil
+}
+
+func (d *modelDecoder) decodeExpression(record expressionRecord) (ModelExpression, error) {
+	index := NewModelExpressionIndex()
+	switch record.Type {
+	case constantExpressionType:
+		return &constantExpression{
+			index: index,
+			name:  record.Name,
+			value: record.Value,
+		}, nil
+	case fromExpressionType:
+		return &fromExpression{
+			name:              record.Name,
+			index:             index,
+			values:            nonNil(record.Values),
+			defaultValue:      record.DefaultValue,
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case toExpressionType:
+		return &toExpression{
+			name:              record.Name,
+			index:             index,
+			values:            nonNil(record.Values),
+			defaultValue:      record.DefaultValue,
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case vehicleTypeExpressionType:
+		return &vehicleTypeExpressionImpl{
+			name:              record.Name,
+			index:             index,
+			values:            nonNil(record.Values),
+			defaultValue:      record.DefaultValue,
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case vehicleTypeDistanceExpressionType:
+		values := make([]common.Distance, len(record.Values))
+		for idx, value := range record.Values {
+			values[idx] = common.NewDistance(value, common.Meters)
+		}
+		return &vehicleTypeDistanceExpressionImpl{
+			name:              record.Name,
+			index:             index,
+			values:            values,
+			defaultValue:      common.NewDistance(record.DefaultValue, common.Meters),
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case fromToExpressionType:
+		values := record.FromTo
+		if values == nil {
+			values = map[int]map[int]float64{}
+		}
+		return &fromToExpression{
+			name:              record.Name,
+			index:             index,
+			values:            values,
+			defaultValue:      record.DefaultValue,
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case vehicleTypeFromToExpressionType:
+		values := record.TypeFromTo
+		if values == nil {
+			values = map[int]map[int]map[int]float64{}
+		}
+		return &vehicleTypeFromToExpression{
+			name:              record.Name,
+			index:             index,
+			values:            values,
+			defaultValue:      record.DefaultValue,
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case distanceExpressionType:
+		expression, err := expressionAs[ModelExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &distanceExpression{
+			name:            record.Name,
+			index:           index,
+			modelExpression: expression,
+			unit:            common.DistanceUnit(record.Unit),
+		}, nil
+	case scaledDurationExpressionType:
+		expression, err := expressionAs[ModelExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &scaledDurationExpressionImpl{
+			name:       record.Name,
+			index:      index,
+			expression: expression,
+			multiplier: record.Value,
+		}, nil
+	case stopDurationExpressionType:
+		return &stopDurationExpressionImpl{
+			name:              record.Name,
+			index:             index,
+			values:            nonNilMap(record.ValuesByKey),
+			defaultValue:      record.DefaultValue,
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case vehicleTypeDurationExpressionType:
+		return &vehicleTypeDurationExpressionImpl{
+			name:              record.Name,
+			index:             index,
+			values:            nonNilMap(record.ValuesByKey),
+			defaultValue:      record.DefaultValue,
+			hasPositiveValues: record.HasPositive,
+			hasNegativeValues: record.HasNegative,
+		}, nil
+	case constantDurationExpressionType:
+		return &constantDurationExpressionImpl{
+			name:     record.Name,
+			index:    index,
+			duration: time.Duration(record.Value),
+		}, nil
+	case travelDurationExpressionType:
+		distance, err := expressionAs[DistanceExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &travelDurationExpression{
+			name:               record.Name,
+			index:              index,
+			distanceExpression: distance,
+			speed:              common.NewSpeed(record.Value, common.MetersPerSecond),
+		}, nil
+	case haversineExpressionType:
+		return &haversineExpression{
+			name:  record.Name,
+			index: index,
+		}, nil
+	case euclideanExpressionType, manhattanExpressionType:
+		distance := common.Euclidean
+		if record.Type == manhattanExpressionType {
+			distance = common.Manhattan
+		}
+		return &planarExpression{
+			name:     record.Name,
+			index:    index,
+			distance: distance,
+		}, nil
+	case measureByIndexExpressionType:
+		matrix, err := newByIndexMatrix(record.Keys, record.Matrix)
+		if err != nil {
+			return nil, err
+		}
+		return &measureByIndexExpression{
+			name:    record.Name,
+			index:   index,
+			measure: matrix,
+		}, nil
+	case measureByPointExpressionType:
+		positions := make(map[[2]float64]int, len(record.Points))
+		for idx, point := range record.Points {
+			positions[point] = idx
+		}
+		return &measureByPointExpression{
+			name:  record.Name,
+			index: index,
+			measure: &byPointMatrix{
+				positions: positions,
+				costs:     record.Matrix,
+			},
+		}, nil
+	case sumExpressionType:
+		expressions := make(ModelExpressions, len(record.Expressions))
+		for idx := range record.Expressions {
+			expression, err := expressionAs[ModelExpression](d, record.Expressions, idx)
+			if err != nil {
+				return nil, err
+			}
+			expressions[idx] = expression
+		}
+		return &sumExpressionImpl{
+			name:        record.Name,
+			index:       index,
+			expressions: expressions,
+		}, nil
+	case timeExpressionType:
+		expression, err := expressionAs[ModelExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &timeExpressionImpl{
+			name:       record.Name,
+			index:      index,
+			expression: expression,
+			epoch:      record.Time,
+		}, nil
+	case stopTimeExpressionType:
+		return &stopTimeExpressionImpl{
+			name:         record.Name,
+			index:        index,
+			defaultTime:  record.Time,
+			values:       record.Values,
+			hasValue:     record.HasValue,
+			defaultValue: record.DefaultValue,
+		}, nil
+	case timeDependentExpressionType:
+		defaultExpression, err := expressionAs[DurationExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		expression, err := NewTimeDependentDurationExpression(d.model, defaultExpression)
+		if err != nil {
+			return nil, err
+		}
+		for idx, interval := range record.Intervals {
+			intervalExpression, err := expressionAs[DurationExpression](
+				d,
+				record.Expressions,
+				idx+1,
+			)
+			if err != nil {
+				return nil, err
+			}
+			err = expression.SetExpression(
+				d.model.ValueToTime(interval[0]),
+				d.model.ValueToTime(interval[1]),
+				intervalExpression,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+		expression.SetName(record.Name)
+		expression.SetSatisfiesTriangleInequality(record.Triangular)
+		return expression, nil
+	case timeIndependentExpressionType:
+		expression, err := expressionAs[DurationExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &timeIndependentDurationExpressionImpl{
+			name:                        record.Name,
+			expression:                  expression,
+			satisfiesTriangleInequality: record.Triangular,
+		}, nil
+	case termExpressionType:
+		expression, err := expressionAs[ModelExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &termExpression{
+			name:       record.Name,
+			index:      index,
+			expression: expression,
+			factor:     record.Value,
+		}, nil
+	case composedExpressionType:
+		defaultExpression, err := expressionAs[ModelExpression](d, record.Expressions, 0)
+		if err != nil {
+			return nil, err
+		}
+		var expressions []ModelExpression
+		for idx := 1; idx < len(record.Expressions); idx++ {
+			expression, err := expressionAs[ModelExpression](d, record.Expressions, idx)
+			if err != nil {
+				return nil, err
+			}
+			expressions = append(expressions, expression)
+		}
+		return &composedPerVehicleTypeExpressionImpl{
+			name:              record.Name,
+			index:             index,
+			defaultExpression: defaultExpression,
+			expressions:       expressions,
+		}, nil
+	}
+
+	value, err := d.deserialize(record.Type, record.Data)
+	if err != nil {
+		return nil, err
+	}
+	expression, ok := value.(ModelExpression)
+	if !ok {
+		return nil, fmt.Errorf(
+			"deserializer `%s` returned %T, expected an expression",
+			record.Type,
+			value,
+		)
+	}
+	return expression, nil
+}
+
+func (d *modelDecoder) decodeComponent(record componentRecord) (any, error) {
+	refs := record.Expressions
+	switch record.Type {
+	case attributesConstraintType:
+		stopAttributes := record.StopAttributes
+		if stopAttributes == nil {
+			stopAttributes = make(map[int][]string)
+		}
+		vehicleTypeAttributes := record.VehicleTypeAttributes
+		if vehicleTypeAttributes == nil {
+			vehicleTypeAttributes = make(map[int][]string)
+		}
+		return &attributesConstraintImpl{
+			modelConstraintImpl:   newModelConstraintImpl(record.Name, ModelExpressions{}),
+			stopAttributes:        stopAttributes,
+			vehicleTypeAttributes: vehicleTypeAttributes,
+		}, nil
+	case maximumDurationConstraintType:
+		maximum, err := expressionAs[VehicleTypeDurationExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &maximumDurationConstraintImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			maximum:             maximum,
+		}, nil
+	case maximumStopsConstraintType:
+		maximumStops, err := expressionAs[VehicleTypeExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &maximumStopsConstraintImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			maximumStops:        maximumStops,
+		}, nil
+	case maximumTravelDurationConstraintType:
+		maximum, err := expressionAs[VehicleTypeDurationExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &maximumTravelDurationConstraintImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			maximum:             maximum,
+		}, nil
+	case maximumWaitStopConstraintType:
+		maxima, err := expressionAs[StopDurationExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &maximumWaitStopConstraintImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			maxima:              maxima,
+		}, nil
+	case maximumWaitVehicleConstraintType:
+		maxima, err := expressionAs[VehicleTypeDurationExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return &maximumWaitVehicleConstraintImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			maxima:              maxima,
+		}, nil
+	case successorConstraintType:
+		disallowedSuccessors := make(map[ModelStop]ModelStops, len(record.Successors))
+		for index, successors := range record.Successors {
+			stop, err := d.model.Stop(index)
+			if err != nil {
+				return nil, err
+			}
+			for _, successorIndex := range successors {
+				successor, err := d.model.Stop(successorIndex)
+				if err != nil {
+					return nil, err
+				}
+				disallowedSuccessors[stop] = append(disallowedSuccessors[stop], successor)
+			}
+		}
+		return &successorConstraintImpl{
+			modelConstraintImpl:  newModelConstraintImpl(record.Name, ModelExpressions{}),
+			disallowedSuccessors: disallowedSuccessors,
+		}, nil
+	case noMixConstraintType:
+		insert, err := d.mixItems(record.Insert)
+		if err != nil {
+			return nil, err
+		}
+		remove, err := d.mixItems(record.Remove)
+		if err != nil {
+			return nil, err
+		}
+		return &noMixConstraintImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			insert:              insert,
+			remove:              remove,
+		}, nil
+	case maximumType:
+		expression, err := expressionAs[ModelExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		maximum, err := expressionAs[VehicleTypeExpression](d, refs, 1)
+		if err != nil {
+			return nil, err
+		}
+		return &maximumImpl{
+			modelConstraintImpl: newModelConstraintImpl(
+				record.Name,
+				ModelExpressions{expression},
+			),
+			maximum:       maximum,
+			penaltyOffset: record.Value,
+		}, nil
+	case latestType:
+		latest, err := expressionAs[StopTimeExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		latenessFactor, err := expressionAs[StopExpression](d, refs, 1)
+		if err != nil {
+			return nil, err
+		}
+		return &latestImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			latest:              latest,
+			latenessFactor:      latenessFactor,
+			temporalReference:   TemporalReference(record.TemporalReference),
+		}, nil
+	case clusterType:
+		return &clusterImpl{
+			modelConstraintImpl: newModelConstraintImpl(record.Name, ModelExpressions{}),
+			includeFirst:        record.IncludeFirst,
+			includeLast:         record.IncludeLast,
+		}, nil
+	case earlinessObjectiveType:
+		targetTime, err := expressionAs[StopTimeExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		earlinessFactor, err := expressionAs[StopExpression](d, refs, 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewEarlinessObjective(
+			targetTime,
+			earlinessFactor,
+			TemporalReference(record.TemporalReference),
+		)
+	case expressionObjectiveType:
+		expression, err := expressionAs[ModelExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return NewExpressionObjective(expression), nil
+	case minStopsObjectiveType:
+		minStops, err := expressionAs[VehicleTypeExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		minStopsPenalty, err := expressionAs[VehicleTypeExpression](d, refs, 1)
+		if err != nil {
+			return nil, err
+		}
+		return NewMinStopsObjective(minStops, minStopsPenalty), nil
+	case balanceObjectiveType:
+		return NewStopBalanceObjective(), nil
+	case travelDurationObjectiveType:
+		return NewTravelDurationObjective(), nil
+	case unplannedObjectiveType:
+		expression, err := expressionAs[StopExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return NewUnPlannedObjective(expression), nil
+	case vehiclesObjectiveType:
+		expression, err := expressionAs[VehicleTypeExpression](d, refs, 0)
+		if err != nil {
+			return nil, err
+		}
+		return NewVehiclesObjective(expression), nil
+	case vehiclesDurationObjectiveType:
+		return NewVehiclesDurationObjective(), nil
+	}
+	return d.deserialize(record.Type, record.Data)
+}
+
+func (d *modelDecoder) deserialize(name string, data []byte) (any, error) {
+	deserialize, err := deserializer(name)
+	if err != nil {
+		return nil, err
+	}
+	return deserialize(d, data)
+}
+
+func (d *modelDecoder) mixItems(items map[int]MixItem) (map[ModelStop]MixItem, error) {
+	mixItems := make(map[ModelStop]MixItem, len(items))
+	for index, item := range items {
+		stop, err := d.model.Stop(index)
+		if err != nil {
+			return nil, err
+		}
+		mixItems[stop] = item
+	}
+	return mixItems, nil
+}
+
+func nonNil(values []float64) []float64 {
+	if values == nil {
+		return []float64{}
+	}
+	return values
+}
+
+func nonNilMap(values map[int]float64) map[int]float64 {
+	if values == nil {
+		return map[int]float64{}
+	}
+	return values
+}
+
+// measureCost returns the cost of a measure, false if the measure panics.
+func measureCost(cost func() float64) (value float64, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return cost(), true
+}
+
+// byIndexMatrix is the measure of a serialized measure by index expression,
+// the costs between the measure indices of the stops of the model.
+type byIndexMatrix struct {
+	// positions is the row and column of each measure index in the costs, -1
+	// if the measure index is not part of the serialized model. It is nil if
+	// the measure indices are the rows and columns.
+	positions []int
+	costs     [][]float64
+}
+
+// newByIndexMatrix returns the measure of the costs between the measure
+// indices in keys, in the order of the rows and columns of the costs.
+func newByIndexMatrix(keys []int, costs [][]float64) (*byIndexMatrix, error) {
+	dense := true
+	for idx, key := range keys {
+		if key < 0 {
+			return nil, fmt.Errorf("measure index %d is negative", key)
+		}
+		dense = dense && key == idx
+	}
+	if dense {
+		return &byIndexMatrix{costs: costs}, nil
+	}
+
+	positions := make([]int, slices.Max(keys)+1)
+	for idx := range positions {
+		positions[idx] = -1
+	}
+	for idx, key := range keys {
+		positions[key] = idx
+	}
+	return &byIndexMatrix{positions: positions, costs: costs}, nil
+}
+
+func (m *byIndexMatrix) Cost(from, to int) float64 {
+	if m.positions == nil {
+		return m.costs[from][to]
+	}
+	return m.costs[m.position(from)][m.position(to)]
+}
+
+func (m *byIndexMatrix) position(index int) int {
+	if index < 0 || index >= len(m.positions) || m.positions[index] < 0 {
+		panic(fmt.Sprintf("measure index %d is not part of the serialized model", index))
+	}
+	return m.positions[index]
+}
+
+// byPointMatrix is the measure of a serialized measure by point expression,
+// the costs between the locations of the stops of the model.
+type byPointMatrix struct {
+	positions map[[2]float64]int
+	costs     [][]float64
+}
+
+func (m *byPointMatrix) Cost(from, to measure.Point) float64 {
+	return m.costs[m.position(from)][m.position(to)]
+}
+
+func (m *byPointMatrix) position(point measure.Point) int {
+	position, ok := m.positions[[2]float64{point[0], point[1]}]
+	if !ok {
+		panic(fmt.Sprintf("point %v is not part of the serialized model", point))
+	}
+	return position
+}
@@ -0,0 +1,202 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/common"
+	"github.com/nextmv-io/sdk/measure"
+)
+
+type unserializableConstraint struct{}
+
+func (c unserializableConstraint) EstimateIsViolated(
+	nextroute.SolutionMoveStops,
+) (bool, nextroute.StopPositionsHint) {
+	return false, nil
+}
+
+func (c unserializableConstraint) String() string {
+	return "unserializable"
+}
+
+type serializableConstraint struct {
+	limit int
+}
+
+func (c *serializableConstraint) EstimateIsViolated(
+	nextroute.SolutionMoveStops,
+) (bool, nextroute.StopPositionsHint) {
+	return false, nil
+}
+
+func (c *serializableConstraint) String() string {
+	return "serializable"
+}
+
+func (c *serializableConstraint) SerializerName() string {
+	return "test.serializable_constraint"
+}
+
+func (c *serializableConstraint) Serialize(nextroute.ModelEncoder) ([]byte, error) {
+	return []byte(strconv.Itoa(c.limit)), nil
+}
+
+func init() {
+	nextroute.RegisterDeserializer(
+		"test.serializable_constraint",
+		func(_ nextroute.ModelDecoder, data []byte) (any, error) {
+			limit, err := strconv.Atoi(string(data))
+			if err != nil {
+				return nil, err
+			}
+			return &serializableConstraint{limit: limit}, nil
+		},
+	)
+}
+
+func TestModelSerialization(t *testing.T) {
+	model, err := createModel(singleVehiclePlanSequenceModel())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := model.AddConstraint(&serializableConstraint{limit: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+	if err := nextroute.WriteModel(&buffer, model); err != nil {
+		t.Fatal(err)
+	}
+	read, err := nextroute.ReadModel(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(read.Stops()) != len(model.Stops()) {
+		t.Fatalf("expected %v stops, got %v", len(model.Stops()), len(read.Stops()))
+	}
+	for idx, stop := range model.Stops() {
+		if got := read.Stops()[idx]; got.ID() != stop.ID() ||
+			got.Location() != stop.Location() {
+			t.Errorf("expected stop %v, got %v", stop, got)
+		}
+	}
+	if len(read.Vehicles()) != len(model.Vehicles()) ||
+		read.Vehicles()[0].ID() != model.Vehicles()[0].ID() {
+		t.Errorf("expected vehicles %v, got %v", model.Vehicles(), read.Vehicles())
+	}
+	if len(read.PlanUnits()) != len(model.PlanUnits()) {
+		t.Errorf(
+			"expected %v plan units, got %v",
+			len(model.PlanUnits()),
+			len(read.PlanUnits()),
+		)
+	}
+
+	constraints := read.Constraints()
+	if len(constraints) != 1 {
+		t.Fatalf("expected 1 constraint, got %v", constraints)
+	}
+	constraint, ok := constraints[0].(*serializableConstraint)
+	if !ok || constraint.limit != 3 {
+		t.Errorf("expected the serializable constraint with limit 3, got %v", constraints[0])
+	}
+}
+
+func TestModelSerializationSparseMeasureIndices(t *testing.T) {
+	model, err := nextroute.NewModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The stops use every other row and column of the matrix.
+	costs := [][]float64{
+		{0, 1, 2, 3, 4},
+		{5, 0, 6, 7, 8},
+		{9, 10, 0, 11, 12},
+		{13, 14, 15, 0, 16},
+		{17, 18, 19, 20, 0},
+	}
+	vehicleType, err := model.NewVehicleType(
+		nextroute.NewTimeIndependentDurationExpression(
+			nextroute.NewDurationExpression(
+				"travel",
+				nextroute.NewMeasureByIndexExpression(measure.Matrix(costs)),
+				common.Second,
+			),
+		),
+		nextroute.NewDurationExpression(
+			"duration",
+			nextroute.NewStopDurationExpression("service", 0),
+			common.Second,
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stops := make(nextroute.ModelStops, 3)
+	for idx := range stops {
+		location, err := common.NewLocation(7.6+0.01*float64(idx), 52.0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stops[idx], err = model.NewStop(location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stops[idx].SetMeasureIndex(2 * idx)
+	}
+	if _, err := model.NewVehicle(vehicleType, time.Now(), stops[0], stops[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, stop := range stops[1:] {
+		if _, err := model.NewPlanSingleStop(stop); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := nextroute.WriteModel(&buffer, model); err != nil {
+		t.Fatal(err)
+	}
+	read, err := nextroute.ReadModel(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readVehicleType := read.VehicleTypes()[0]
+	for _, from := range read.Stops() {
+		for _, to := range read.Stops() {
+			want := costs[2*from.Index()][2*to.Index()]
+			got := readVehicleType.TravelDurationExpression().Value(readVehicleType, from, to)
+			if got != want {
+				t.Errorf("expected cost %v from %v to %v, got %v", want, from.Index(), to.Index(), got)
+			}
+		}
+	}
+}
+
+func TestModelSerializationErrors(t *testing.T) {
+	model, err := createModel(singleVehiclePlanSequenceModel())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := model.AddConstraint(unserializableConstraint{}); err != nil {
+		t.Fatal(err)
+	}
+	err = nextroute.WriteModel(&bytes.Buffer{}, model)
+	if err == nil || !strings.Contains(err.Error(), "does not implement nextroute.Serializer") {
+		t.Errorf("expected an error for the custom constraint, got %v", err)
+	}
+
+	if _, err := nextroute.ReadModel(strings.NewReader("not a model file")); err == nil {
+		t.Error("expected an error reading an invalid model file")
+	}
+}
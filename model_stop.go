@@ -17,7 +17,7 @@ type ModelStop interface {
 	// ClosestStops returns a slice containing the closest stops to the
 	// invoking stop. The slice is sorted by increasing distance to the
 	// location. The slice first stop is the stop itself. The distance used
-	// is the common.Haversine distance between the stops. All the stops
+	// is the common.StraightLine distance between the stops. All the stops
 	// in the model are used in the slice. Slice with similar distance are
 	// sorted by their index (increasing).
 	ClosestStops() (ModelStops, error)
@@ -109,6 +109,18 @@ type stopImpl struct {
 	measureIndex      int
 	firstOrLast       bool
 	fixed             bool
+	quoting           bool
+	quotingWindows    bool
+}
+
+// isStopLocked returns true if the values of the stop in expressions can no
+// longer be set, that is if the model is locked and the stop is not a quoting
+// stop, see [SetQuotingStop].
+func isStopLocked(stop ModelStop) bool {
+	if s, ok := stop.(*stopImpl); ok && s.quoting {
+		return false
+	}
+	return stop.Model().IsLocked()
 }
 
 func (s *stopImpl) Model() Model {
@@ -231,6 +243,18 @@ func (s *stopImpl) SetWindows(windows [][2]time.Time) error {
 		return nil
 	}
 
+	return s.setWindows(windows)
+}
+
+// setWindows sets the windows of the stop, regardless of the model being
+// locked.
+func (s *stopImpl) setWindows(windows [][2]time.Time) error {
+	if len(windows) == 0 {
+		s.windows = nil
+		s.windowChecker = nil
+		return nil
+	}
+
 	for i, window := range windows {
 		startTime := window[0]
 		endTime := window[1]
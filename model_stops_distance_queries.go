@@ -26,8 +26,11 @@ type ModelStopsDistanceQueries interface {
 }
 
 // NewModelStopsDistanceQueries returns a new ModelStopsDistanceQueries.
-// All distances in this interface are calculated using the [common.Haversine]
-// formula.
+// All distances in this interface are calculated using the
+// [common.StraightLine] distance, the [common.Haversine] formula for
+// geographical locations and the [common.Euclidean] distance for planar
+// locations. The stops must either all have a planar location or all have a
+// geographical location.
 func NewModelStopsDistanceQueries(
 	stops ModelStops,
 ) (ModelStopsDistanceQueries, error) {
@@ -38,6 +41,14 @@ func NewModelStopsDistanceQueries(
 			return nil,
 				fmt.Errorf("stop %v has invalid location", stop.ID())
 		}
+		if stop.Location().IsPlanar() != stops[0].Location().IsPlanar() {
+			return nil,
+				fmt.Errorf(
+					"stop %v and stop %v mix planar and geographical locations",
+					stops[0].ID(),
+					stop.ID(),
+				)
+		}
 		present[stop] = struct{}{}
 		wrappers[i] = modelStopWrapper{stop: stop}
 	}
@@ -126,11 +137,17 @@ func (p modelStopWrapper) Compare(
 	d kdtree.Dim,
 ) float64 {
 	q := c.(modelStopWrapper)
+	// Planar coordinates are compared in kilometers, the unit of the
+	// distance, so the tree can prune on the difference of a coordinate.
+	scale := 1.0
+	if p.stop.Location().IsPlanar() {
+		scale = 0.001
+	}
 	switch d {
 	case 0:
-		return p.stop.Location().Longitude() - q.stop.Location().Longitude()
+		return (p.stop.Location().Longitude() - q.stop.Location().Longitude()) * scale
 	case 1:
-		return p.stop.Location().Latitude() - q.stop.Location().Latitude()
+		return (p.stop.Location().Latitude() - q.stop.Location().Latitude()) * scale
 	default:
 		panic("illegal dimension")
 	}
@@ -145,7 +162,7 @@ func (p modelStopWrapper) Distance(c kdtree.Comparable) float64 {
 	if !p.stop.Location().IsValid() || !q.stop.Location().IsValid() {
 		return 0.0
 	}
-	d, err := common.Haversine(p.stop.Location(), q.stop.Location())
+	d, err := common.StraightLine(p.stop.Location(), q.stop.Location())
 	if err != nil {
 		panic(err)
 	}
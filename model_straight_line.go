@@ -0,0 +1,19 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import "github.com/nextmv-io/nextroute/common"
+
+// straightLineDistance returns the haversine distance between geographical
+// locations and the euclidean distance between planar locations. Invalid
+// locations have a 0 distance.
+func straightLineDistance(from, to common.Location) common.Distance {
+	if !from.IsValid() || !to.IsValid() {
+		return common.NewDistance(0., common.Meters)
+	}
+	v, err := common.StraightLine(from, to)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
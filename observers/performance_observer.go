@@ -18,6 +18,7 @@ import (
 // of the model, and it's subsequent use.
 type PerformanceObserver interface {
 	nextroute.SolutionObserver
+	nextroute.SolutionObjectiveObserver
 
 	// Duration returns the duration since the creation of the
 	// PerformanceObserver.
@@ -26,13 +27,18 @@ type PerformanceObserver interface {
 	// Report creates a report of the performance of the model, and it's
 	// subsequent use.
 	Report() string
+
+	// PerformanceReport returns the report of the performance of the model,
+	// and it's subsequent use, as a JSON serializable value.
+	PerformanceReport() PerformanceReport
 }
 
 // NewPerformanceObserver returns a new performance observer. A performance
 // observer can be used to report the speed performance of moves.
 //
 // To use the observer, you need to register it with the model using the
-// AddSolutionObserver method.
+// AddSolutionObserver method. To report the estimates of each term of the
+// objective, register it with the AddSolutionObjectiveObserver method as well.
 func NewPerformanceObserver(model nextroute.Model) PerformanceObserver {
 	observer := performanceObserverImpl{
 		model:          model,
@@ -41,6 +47,7 @@ func NewPerformanceObserver(model nextroute.Model) PerformanceObserver {
 		objectiveData: objectiveData{
 			lastStartTimestamp: make(map[string]time.Time),
 		},
+		termData: make(map[nextroute.ModelObjectiveTerm]objectiveData),
 		solutionData: solutionData{
 			lastBestMoveStart: make(map[string]time.Time),
 			lastCopyStart:     make(map[string]time.Time),
@@ -62,6 +69,7 @@ type solutionData struct {
 	copies             int
 	cumulativeCopy     time.Duration
 	cumulativeBestMove time.Duration
+	bestMoveLatency    latencyHistogram
 	bestMoves          int
 	noBestMoves        int
 	moves              int
@@ -94,6 +102,7 @@ type performanceObserverImpl struct {
 	start           time.Time
 	constraintData  map[nextroute.ModelConstraint]constraintData
 	objectiveData   objectiveData
+	termData        map[nextroute.ModelObjectiveTerm]objectiveData
 	solutionData    solutionData
 }
 
@@ -162,9 +171,9 @@ func (p *performanceObserverImpl) OnBestMoveFound(move nextroute.SolutionMove) {
 	p.moveMutex.Lock()
 	defer p.moveMutex.Unlock()
 
-	p.solutionData.cumulativeBestMove += time.Since(
-		p.solutionData.lastBestMoveStart[p.routineName()],
-	)
+	latency := time.Since(p.solutionData.lastBestMoveStart[p.routineName()])
+	p.solutionData.cumulativeBestMove += latency
+	p.solutionData.bestMoveLatency.add(latency)
 
 	if !move.IsExecutable() {
 		p.solutionData.noBestMoves++
@@ -253,6 +262,36 @@ func (p *performanceObserverImpl) OnEstimatedDeltaObjectiveScore(_ float64) {
 	p.objectiveData.cumulativeEstimate += time.Since(p.objectiveData.lastStartTimestamp[p.routineName()])
 }
 
+func (p *performanceObserverImpl) OnEstimateDeltaObjectiveTermScore(
+	term nextroute.ModelObjectiveTerm,
+) {
+	p.objectiveMutex.Lock()
+	defer p.objectiveMutex.Unlock()
+
+	data, ok := p.termData[term]
+	if !ok {
+		data = objectiveData{
+			lastStartTimestamp: make(map[string]time.Time),
+		}
+	}
+	data.estimations++
+	data.lastStartTimestamp[p.routineName()] = time.Now()
+	p.termData[term] = data
+}
+
+func (p *performanceObserverImpl) OnEstimatedDeltaObjectiveTermScore(
+	term nextroute.ModelObjectiveTerm,
+	_ float64,
+) {
+	p.objectiveMutex.Lock()
+	defer p.objectiveMutex.Unlock()
+
+	if data, ok := p.termData[term]; ok {
+		data.cumulativeEstimate += time.Since(data.lastStartTimestamp[p.routineName()])
+		p.termData[term] = data
+	}
+}
+
 func (p *performanceObserverImpl) OnCheckConstraint(
 	constraint nextroute.ModelConstraint,
 	_ nextroute.CheckedAt,
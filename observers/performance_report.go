@@ -0,0 +1,229 @@
+// © 2019-present nextmv.io inc
+
+package observers
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+)
+
+// latencyBounds are the upper bounds of the buckets of a latency histogram.
+var latencyBounds = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// latencyHistogram counts latencies in the buckets of latencyBounds, the last
+// count is the number of latencies exceeding the largest bound.
+type latencyHistogram [8]int
+
+func (h *latencyHistogram) add(latency time.Duration) {
+	for idx, bound := range latencyBounds {
+		if latency <= bound {
+			h[idx]++
+			return
+		}
+	}
+	h[len(latencyBounds)]++
+}
+
+// PerformanceReport is the report of a performance observer. All durations
+// are in seconds.
+type PerformanceReport struct {
+	// Duration is the duration since the creation of the observer.
+	Duration float64 `json:"duration"`
+	// GoRoutines is the number of go routines seen checking constraints.
+	GoRoutines int `json:"go_routines"`
+	// Solution reports the creation, copying and planning of solutions.
+	Solution SolutionPerformance `json:"solution"`
+	// Objective reports the estimates of the objective.
+	Objective ObjectivePerformance `json:"objective"`
+	// Constraints reports the estimates and checks of each constraint of
+	// the model, in the order of the constraints of the model.
+	Constraints []ConstraintPerformance `json:"constraints"`
+}
+
+// SolutionPerformance reports the creation, copying and planning of
+// solutions.
+type SolutionPerformance struct {
+	// New is the number of solutions created.
+	New int `json:"new"`
+	// NewDuration is the cumulative duration of creating solutions.
+	NewDuration float64 `json:"new_duration"`
+	// Copies is the number of solutions copied.
+	Copies int `json:"copies"`
+	// CopyDuration is the cumulative duration of copying solutions.
+	CopyDuration float64 `json:"copy_duration"`
+	// BestMoves is the number of best moves requested.
+	BestMoves int `json:"best_moves"`
+	// NoBestMoves is the number of best moves requested for which no
+	// executable move was found.
+	NoBestMoves int `json:"no_best_moves"`
+	// BestMoveDuration is the cumulative duration of finding best moves.
+	BestMoveDuration float64 `json:"best_move_duration"`
+	// BestMoveLatency is the histogram of the durations of finding a best
+	// move.
+	BestMoveLatency LatencyHistogram `json:"best_move_latency"`
+	// Moves is the number of moves executed.
+	Moves int `json:"moves"`
+	// MovesFailed is the number of moves which failed to execute.
+	MovesFailed int `json:"moves_failed"`
+	// MoveDuration is the cumulative duration of executing moves.
+	MoveDuration float64 `json:"move_duration"`
+}
+
+// LatencyHistogram is a histogram of durations. Counts[i] is the number of
+// durations less than or equal to Bounds[i] and greater than the previous
+// bound, the last count is the number of durations greater than the last
+// bound.
+type LatencyHistogram struct {
+	// Bounds are the upper bounds of the buckets in seconds.
+	Bounds []float64 `json:"bounds"`
+	// Counts are the number of durations in each bucket, it has one more
+	// element than Bounds.
+	Counts []int `json:"counts"`
+}
+
+// ObjectivePerformance reports the estimates of the objective.
+type ObjectivePerformance struct {
+	// Estimates is the number of estimates of the delta score of moves.
+	Estimates int `json:"estimates"`
+	// EstimateDuration is the cumulative duration of the estimates.
+	EstimateDuration float64 `json:"estimate_duration"`
+	// Terms reports the estimates of each term of the objective, in the
+	// order of the terms of the objective. The terms are only reported if
+	// the observer is registered as a solution objective observer.
+	Terms []ObjectiveTermPerformance `json:"terms,omitempty"`
+}
+
+// ObjectiveTermPerformance reports the estimates of a term of the objective.
+type ObjectiveTermPerformance struct {
+	// Name is the name of the objective of the term.
+	Name string `json:"name"`
+	// Factor is the factor of the term.
+	Factor float64 `json:"factor"`
+	// Estimates is the number of estimates of the term.
+	Estimates int `json:"estimates"`
+	// EstimateDuration is the cumulative duration of the estimates.
+	EstimateDuration float64 `json:"estimate_duration"`
+}
+
+// ConstraintPerformance reports the estimates and checks of a constraint.
+type ConstraintPerformance struct {
+	// Name is the ID of the constraint if it has one, otherwise its string
+	// representation or type name.
+	Name string `json:"name"`
+	// Type is the type of the constraint.
+	Type string `json:"type"`
+	// Estimates is the number of estimates of whether a move violates the
+	// constraint.
+	Estimates int `json:"estimates"`
+	// EstimatedViolations is the number of estimates which found the move to
+	// violate the constraint.
+	EstimatedViolations int `json:"estimated_violations"`
+	// EstimateViolationRate is the fraction of the estimates which are
+	// violated.
+	EstimateViolationRate float64 `json:"estimate_violation_rate"`
+	// EstimateDuration is the cumulative duration of the estimates.
+	EstimateDuration float64 `json:"estimate_duration"`
+	// Checks is the number of checks of the constraint.
+	Checks int `json:"checks"`
+	// Violations is the number of checks of the constraint which are
+	// violated.
+	Violations int `json:"violations"`
+	// ViolationRate is the fraction of the checks which are violated.
+	ViolationRate float64 `json:"violation_rate"`
+	// CheckDuration is the cumulative duration of the checks.
+	CheckDuration float64 `json:"check_duration"`
+}
+
+func (p *performanceObserverImpl) PerformanceReport() PerformanceReport {
+	report := PerformanceReport{
+		Duration: p.Duration().Seconds(),
+	}
+
+	p.solutionMutex.Lock()
+	report.Solution.New = p.solutionData.new
+	report.Solution.NewDuration = p.solutionData.cumulativeNew.Seconds()
+	report.Solution.Copies = p.solutionData.copies
+	report.Solution.CopyDuration = p.solutionData.cumulativeCopy.Seconds()
+	p.solutionMutex.Unlock()
+
+	p.moveMutex.Lock()
+	report.Solution.BestMoves = p.solutionData.bestMoves
+	report.Solution.NoBestMoves = p.solutionData.noBestMoves
+	report.Solution.BestMoveDuration = p.solutionData.cumulativeBestMove.Seconds()
+	report.Solution.BestMoveLatency = LatencyHistogram{
+		Bounds: make([]float64, len(latencyBounds)),
+		Counts: make([]int, len(p.solutionData.bestMoveLatency)),
+	}
+	for idx, bound := range latencyBounds {
+		report.Solution.BestMoveLatency.Bounds[idx] = bound.Seconds()
+	}
+	copy(
+		report.Solution.BestMoveLatency.Counts,
+		p.solutionData.bestMoveLatency[:],
+	)
+	report.Solution.Moves = p.solutionData.moves
+	report.Solution.MovesFailed = p.solutionData.movesFailed
+	report.Solution.MoveDuration = p.solutionData.cumulativeMoves.Seconds()
+	p.moveMutex.Unlock()
+
+	p.objectiveMutex.Lock()
+	report.Objective.Estimates = p.objectiveData.estimations
+	report.Objective.EstimateDuration = p.objectiveData.cumulativeEstimate.Seconds()
+	if len(p.termData) > 0 {
+		terms := p.model.Objective().Terms()
+		report.Objective.Terms = make([]ObjectiveTermPerformance, len(terms))
+		for idx, term := range terms {
+			data := p.termData[term]
+			report.Objective.Terms[idx] = ObjectiveTermPerformance{
+				Name:             fmt.Sprintf("%v", term.Objective()),
+				Factor:           term.Factor(),
+				Estimates:        data.estimations,
+				EstimateDuration: data.cumulativeEstimate.Seconds(),
+			}
+		}
+	}
+	p.objectiveMutex.Unlock()
+
+	p.constraintMutex.Lock()
+	constraints := p.model.Constraints()
+	report.Constraints = make([]ConstraintPerformance, len(constraints))
+	for idx, constraint := range constraints {
+		data := p.constraintData[constraint]
+		if report.GoRoutines < len(data.lastStartTimestamp) {
+			report.GoRoutines = len(data.lastStartTimestamp)
+		}
+		report.Constraints[idx] = ConstraintPerformance{
+			Name:                  nextroute.ConstraintName(constraint),
+			Type:                  reflect.TypeOf(constraint).String(),
+			Estimates:             data.estimations,
+			EstimatedViolations:   data.estimatedViolations,
+			EstimateViolationRate: rate(data.estimatedViolations, data.estimations),
+			EstimateDuration:      data.cumulativeEstimate.Seconds(),
+			Checks:                data.checks,
+			Violations:            data.violated,
+			ViolationRate:         rate(data.violated, data.checks),
+			CheckDuration:         data.cumulativeCheck.Seconds(),
+		}
+	}
+	p.constraintMutex.Unlock()
+
+	return report
+}
+
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
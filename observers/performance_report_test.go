@@ -0,0 +1,90 @@
+// © 2019-present nextmv.io inc
+
+package observers_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/observers"
+	"github.com/nextmv-io/sdk/run"
+)
+
+func TestPerformanceReport(t *testing.T) {
+	model := newModel(t)
+	observer := observers.NewPerformanceObserver(model)
+	model.AddSolutionObserver(observer)
+	model.AddSolutionObjectiveObserver(observer)
+
+	solver := newSolver(t, model)
+	ctx := context.WithValue(context.Background(), run.Start, time.Now())
+	solutions, err := solver.Solve(
+		ctx,
+		nextroute.SolveOptions{Iterations: 50, Duration: time.Minute},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for solution := range solutions {
+		if solution.Error != nil {
+			t.Fatal(solution.Error)
+		}
+	}
+
+	report := observer.PerformanceReport()
+	if _, err := json.Marshal(report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Solution.BestMoves == 0 {
+		t.Error("expected best moves to be reported")
+	}
+	latencies := 0
+	for _, count := range report.Solution.BestMoveLatency.Counts {
+		latencies += count
+	}
+	if latencies != report.Solution.BestMoves {
+		t.Errorf(
+			"expected %v best move latencies, got %v",
+			report.Solution.BestMoves,
+			latencies,
+		)
+	}
+	if got := len(report.Solution.BestMoveLatency.Counts); got !=
+		len(report.Solution.BestMoveLatency.Bounds)+1 {
+		t.Errorf("expected one more count than bounds, got %v counts", got)
+	}
+
+	if len(report.Constraints) != len(model.Constraints()) {
+		t.Fatalf(
+			"expected %v constraints, got %v",
+			len(model.Constraints()),
+			len(report.Constraints),
+		)
+	}
+	for _, constraint := range report.Constraints {
+		if constraint.Name == "" || constraint.Estimates == 0 {
+			t.Errorf("expected estimates of constraint, got %+v", constraint)
+		}
+		if constraint.EstimateViolationRate < 0 || constraint.EstimateViolationRate > 1 {
+			t.Errorf("expected violation rate in [0, 1], got %+v", constraint)
+		}
+	}
+
+	terms := model.Objective().Terms()
+	if len(report.Objective.Terms) != len(terms) {
+		t.Fatalf(
+			"expected %v objective terms, got %v",
+			len(terms),
+			len(report.Objective.Terms),
+		)
+	}
+	for _, term := range report.Objective.Terms {
+		if term.Estimates == 0 {
+			t.Errorf("expected estimates of objective term, got %+v", term)
+		}
+	}
+}
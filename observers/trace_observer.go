@@ -0,0 +1,294 @@
+// © 2019-present nextmv.io inc
+
+package observers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/nextmv-io/nextroute"
+)
+
+// TraceEventType is the type of event of a trace.
+type TraceEventType string
+
+const (
+	// TraceEventStart is the event of the start solution of the solver.
+	TraceEventStart TraceEventType = "start"
+	// TraceEventReset is the event of the work solution being reset to
+	// another solution.
+	TraceEventReset TraceEventType = "reset"
+	// TraceEventPlan is the event of a move executed on the work solution.
+	TraceEventPlan TraceEventType = "plan"
+	// TraceEventUnPlan is the event of a plan unit unplanned from the work
+	// solution.
+	TraceEventUnPlan TraceEventType = "unplan"
+	// TraceEventBest is the event of a new best solution. The new best
+	// solution is the work solution at the time of the event.
+	TraceEventBest TraceEventType = "best"
+)
+
+// TraceEvent is an event of a trace written by a trace observer. Stops and
+// vehicles are identified by their index in the model.
+type TraceEvent struct {
+	// Type is the type of the event.
+	Type TraceEventType `json:"type"`
+	// Iteration is the iteration of the solver in which the event occurred.
+	Iteration int `json:"iteration"`
+	// Routes are the stops planned on each vehicle of the solution of a
+	// start or reset event, excluding the first and last stop of the vehicle.
+	Routes [][]int `json:"routes,omitempty"`
+	// Positions are the previous stop, the stop and the next stop of each
+	// stop planned by a plan event.
+	Positions [][3]int `json:"positions,omitempty"`
+	// Stops are the stops of the plan unit unplanned by an unplan event.
+	Stops []int `json:"stops,omitempty"`
+	// Score is the score of the work solution after the event.
+	Score float64 `json:"score"`
+}
+
+// TraceObserver is an observer recording a trace of the changes to the work
+// solution of a solver. The trace can be replayed with [Replay] to reproduce
+// the work solution after any event.
+type TraceObserver interface {
+	nextroute.SolutionObserver
+	nextroute.SolutionUnPlanObserver
+
+	// Register registers the solver to the trace observer.
+	Register(solver nextroute.Solver) error
+}
+
+// NewTraceObserver returns a new trace observer writing the trace to the
+// given writer. The trace is written as newline delimited JSON, one
+// [TraceEvent] per line. The first event is the start solution of the
+// solver, it is followed by each move executed on the work solution, each
+// plan unit unplanned from the work solution, each reset of the work solution
+// and each new best solution, in the order they occur. The writer is flushed
+// when the solver is done, it is not closed.
+//
+// To use the observer register it to the solver the following way:
+//
+//	solver, err := nextroute.NewSolver(model, solverOptions)
+//	traceObserver := observers.NewTraceObserver(file)
+//	err = traceObserver.Register(solver)
+func NewTraceObserver(w io.Writer) TraceObserver {
+	writer := bufio.NewWriter(w)
+	return &traceObserverImpl{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+type traceObserverImpl struct {
+	solver    nextroute.Solver
+	writer    *bufio.Writer
+	encoder   *json.Encoder
+	iteration int
+}
+
+func (t *traceObserverImpl) Register(solver nextroute.Solver) error {
+	if t.solver != nil {
+		return fmt.Errorf("trace observer already registered to a solver")
+	}
+	t.solver = solver
+
+	solver.SolveEvents().Start.Register(func(info nextroute.SolveInformation) {
+		t.iteration = info.Iteration()
+		t.writeSolution(TraceEventStart, info.Solver().WorkSolution())
+	})
+	solver.SolveEvents().Iterating.Register(func(info nextroute.SolveInformation) {
+		t.iteration = info.Iteration()
+	})
+	solver.SolveEvents().Reset.Register(func(
+		solution nextroute.Solution,
+		_ nextroute.SolveInformation,
+	) {
+		t.writeSolution(TraceEventReset, solution)
+	})
+	solver.SolveEvents().NewBestSolution.Register(func(info nextroute.SolveInformation) {
+		t.write(TraceEvent{
+			Type:      TraceEventBest,
+			Iteration: t.iteration,
+			Score:     info.Solver().BestSolution().Score(),
+		})
+	})
+	solver.SolveEvents().Done.Register(func(_ nextroute.SolveInformation) {
+		t.solver.Model().RemoveSolutionObserver(t)
+		t.solver.Model().RemoveSolutionUnPlanObserver(t)
+		if err := t.writer.Flush(); err != nil {
+			panic(err)
+		}
+	})
+
+	solver.Model().RemoveSolutionObserver(t)
+	solver.Model().AddSolutionObserver(t)
+
+	solver.Model().RemoveSolutionUnPlanObserver(t)
+	solver.Model().AddSolutionUnPlanObserver(t)
+
+	return nil
+}
+
+func (t *traceObserverImpl) write(event TraceEvent) {
+	if err := t.encoder.Encode(event); err != nil {
+		panic(err)
+	}
+}
+
+func (t *traceObserverImpl) writeSolution(
+	eventType TraceEventType,
+	solution nextroute.Solution,
+) {
+	routes := make([][]int, len(solution.Vehicles()))
+	for idx, solutionVehicle := range solution.Vehicles() {
+		solutionStops := solutionVehicle.SolutionStops()
+		routes[idx] = make([]int, 0, len(solutionStops)-2)
+		for _, solutionStop := range solutionStops[1 : len(solutionStops)-1] {
+			routes[idx] = append(routes[idx], solutionStop.ModelStop().Index())
+		}
+	}
+	t.write(TraceEvent{
+		Type:      eventType,
+		Iteration: t.iteration,
+		Routes:    routes,
+		Score:     solution.Score(),
+	})
+}
+
+func (t *traceObserverImpl) OnPlanSucceeded(move nextroute.SolutionMove) {
+	solution := move.PlanUnit().Solution()
+	if t.solver.WorkSolution() != solution {
+		return
+	}
+
+	stopPositions := move.(nextroute.SolutionMoveStops).StopPositions()
+	positions := make([][3]int, len(stopPositions))
+	for idx, stopPosition := range stopPositions {
+		positions[idx] = [3]int{
+			stopPosition.Previous().ModelStop().Index(),
+			stopPosition.Stop().ModelStop().Index(),
+			stopPosition.Next().ModelStop().Index(),
+		}
+	}
+	t.write(TraceEvent{
+		Type:      TraceEventPlan,
+		Iteration: t.iteration,
+		Positions: positions,
+		Score:     solution.Score(),
+	})
+}
+
+func (t *traceObserverImpl) OnUnPlanSucceeded(
+	planUnit nextroute.SolutionPlanStopsUnit,
+) {
+	solution := planUnit.Solution()
+	if t.solver.WorkSolution() != solution {
+		return
+	}
+
+	solutionStops := planUnit.SolutionStops()
+	stops := make([]int, len(solutionStops))
+	for idx, solutionStop := range solutionStops {
+		stops[idx] = solutionStop.ModelStop().Index()
+	}
+	t.write(TraceEvent{
+		Type:      TraceEventUnPlan,
+		Iteration: t.iteration,
+		Stops:     stops,
+		Score:     solution.Score(),
+	})
+}
+
+func (t *traceObserverImpl) OnUnPlan(_ nextroute.SolutionPlanStopsUnit) {
+}
+
+func (t *traceObserverImpl) OnUnPlanFailed(_ nextroute.SolutionPlanStopsUnit) {
+}
+
+func (t *traceObserverImpl) OnNewSolution(_ nextroute.Model) {
+}
+
+func (t *traceObserverImpl) OnNewSolutionCreated(_ nextroute.Solution) {
+}
+
+func (t *traceObserverImpl) OnCopySolution(_ nextroute.Solution) {
+}
+
+func (t *traceObserverImpl) OnCopiedSolution(_ nextroute.Solution) {
+}
+
+func (t *traceObserverImpl) OnCheckConstraint(
+	_ nextroute.ModelConstraint,
+	_ nextroute.CheckedAt,
+) {
+}
+
+func (t *traceObserverImpl) OnCheckedConstraint(
+	_ nextroute.ModelConstraint,
+	_ bool,
+) {
+}
+
+func (t *traceObserverImpl) OnSolutionConstraintChecked(
+	_ nextroute.ModelConstraint,
+	_ bool,
+) {
+}
+
+func (t *traceObserverImpl) OnStopConstraintChecked(
+	_ nextroute.SolutionStop,
+	_ nextroute.ModelConstraint,
+	_ bool,
+) {
+}
+
+func (t *traceObserverImpl) OnVehicleConstraintChecked(
+	_ nextroute.SolutionVehicle,
+	_ nextroute.ModelConstraint,
+	_ bool,
+) {
+}
+
+func (t *traceObserverImpl) OnEstimateIsViolated(
+	_ nextroute.ModelConstraint,
+) {
+}
+
+func (t *traceObserverImpl) OnEstimatedIsViolated(
+	_ nextroute.SolutionMove,
+	_ nextroute.ModelConstraint,
+	_ bool,
+	_ nextroute.StopPositionsHint,
+) {
+}
+
+func (t *traceObserverImpl) OnEstimateDeltaObjectiveScore() {
+}
+
+func (t *traceObserverImpl) OnEstimatedDeltaObjectiveScore(
+	_ float64,
+) {
+}
+
+func (t *traceObserverImpl) OnBestMove(
+	_ nextroute.Solution,
+) {
+}
+
+func (t *traceObserverImpl) OnBestMoveFound(
+	_ nextroute.SolutionMove,
+) {
+}
+
+func (t *traceObserverImpl) OnPlan(
+	_ nextroute.SolutionMove,
+) {
+}
+
+func (t *traceObserverImpl) OnPlanFailed(
+	_ nextroute.SolutionMove,
+	_ nextroute.ModelConstraint,
+) {
+}
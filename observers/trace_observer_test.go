@@ -0,0 +1,171 @@
+// © 2019-present nextmv.io inc
+
+package observers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/nextroute/factory"
+	"github.com/nextmv-io/nextroute/observers"
+	"github.com/nextmv-io/nextroute/schema"
+	"github.com/nextmv-io/sdk/run"
+)
+
+func newModel(t *testing.T) nextroute.Model {
+	data, err := os.ReadFile("../tests/golden/testdata/direct_precedence.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var input schema.Input
+	if err := json.Unmarshal(data, &input); err != nil {
+		t.Fatal(err)
+	}
+	penalty := 100000
+	for idx := range input.Stops {
+		input.Stops[idx].UnplannedPenalty = &penalty
+	}
+
+	options := factory.Options{}
+	options.Objectives.UnplannedPenalty = 1
+	options.Objectives.VehiclesDuration = 1
+	model, err := factory.NewModel(input, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model
+}
+
+func newSolver(t *testing.T, model nextroute.Model) nextroute.Solver {
+	parameter := nextroute.IntParameterOptions{
+		StartValue:               2,
+		DeltaAfterIterations:     10,
+		Delta:                    1,
+		MinValue:                 2,
+		MaxValue:                 4,
+		SnapBackAfterImprovement: true,
+		Zigzag:                   true,
+	}
+	solver, err := nextroute.NewSolver(model, nextroute.SolverOptions{
+		Unplan:  parameter,
+		Plan:    parameter,
+		Restart: parameter,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return solver
+}
+
+func routes(solution nextroute.Solution) [][]string {
+	routes := make([][]string, 0, len(solution.Vehicles()))
+	for _, solutionVehicle := range solution.Vehicles() {
+		route := make([]string, 0)
+		for _, solutionStop := range solutionVehicle.SolutionStops() {
+			route = append(route, solutionStop.ModelStop().ID())
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+func TestTraceObserverReplay(t *testing.T) {
+	model := newModel(t)
+	solver := newSolver(t, model)
+
+	var trace bytes.Buffer
+	if err := observers.NewTraceObserver(&trace).Register(solver); err != nil {
+		t.Fatal(err)
+	}
+
+	// The solver expects the start of the run in the context.
+	ctx := context.WithValue(context.Background(), run.Start, time.Now())
+	solutions, err := solver.Solve(
+		ctx,
+		nextroute.SolveOptions{Iterations: 200, Duration: time.Minute},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for solution := range solutions {
+		if solution.Error != nil {
+			t.Fatal(solution.Error)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(trace.String()), "\n")
+	lastBest := -1
+	counts := map[observers.TraceEventType]int{}
+	for idx, line := range lines {
+		var event observers.TraceEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		counts[event.Type]++
+		if event.Type == observers.TraceEventBest {
+			lastBest = idx
+		}
+	}
+	for _, eventType := range []observers.TraceEventType{
+		observers.TraceEventStart,
+		observers.TraceEventPlan,
+		observers.TraceEventUnPlan,
+		observers.TraceEventBest,
+	} {
+		if counts[eventType] == 0 {
+			t.Fatalf("expected %s events in the trace", eventType)
+		}
+	}
+
+	// Replaying all events reproduces the final work solution.
+	replayed, err := observers.Replay(
+		context.Background(),
+		newModel(t),
+		strings.NewReader(trace.String()),
+		-1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := routes(replayed), routes(solver.WorkSolution()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected work solution %v, got %v", want, got)
+	}
+
+	// Replaying up to the last new best event reproduces the best solution.
+	replayed, err = observers.Replay(
+		context.Background(),
+		newModel(t),
+		strings.NewReader(trace.String()),
+		lastBest,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := routes(replayed), routes(solver.BestSolution()); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected best solution %v, got %v", want, got)
+	}
+	if replayed.Score() != solver.BestSolution().Score() {
+		t.Errorf(
+			"expected best score %v, got %v",
+			solver.BestSolution().Score(),
+			replayed.Score(),
+		)
+	}
+
+	_, err = observers.Replay(
+		context.Background(),
+		newModel(t),
+		strings.NewReader(trace.String()),
+		len(lines),
+	)
+	if err == nil {
+		t.Error("expected replaying beyond the trace to fail")
+	}
+}
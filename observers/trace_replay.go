@@ -0,0 +1,238 @@
+// © 2019-present nextmv.io inc
+
+package observers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/nextmv-io/nextroute"
+)
+
+// Replay re-applies the events of a trace written by a trace observer to
+// reproduce the work solution of the solver right after the event with the
+// given sequence number. The sequence number is the zero based line of the
+// event in the trace, a negative sequence number replays all events. The
+// model must be created from the same input and options as the model of the
+// traced solver.
+//
+// The score of the replayed solution is compared to the score recorded after
+// each event, an error is returned as soon as the replay diverges from the
+// trace.
+func Replay(
+	ctx context.Context,
+	model nextroute.Model,
+	trace io.Reader,
+	sequence int,
+) (nextroute.Solution, error) {
+	decoder := json.NewDecoder(trace)
+
+	var solution nextroute.Solution
+	for index := 0; sequence < 0 || index <= sequence; index++ {
+		var event TraceEvent
+		if err := decoder.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				if sequence >= 0 {
+					return nil, fmt.Errorf(
+						"trace has %d events, event %d does not exist",
+						index,
+						sequence,
+					)
+				}
+				break
+			}
+			return nil, fmt.Errorf("event %d: %w", index, err)
+		}
+
+		var err error
+		solution, err = replayEvent(ctx, model, solution, event)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", index, err)
+		}
+		if !sameScore(solution.Score(), event.Score) {
+			return nil, fmt.Errorf(
+				"event %d: replayed score %v differs from traced score %v",
+				index,
+				solution.Score(),
+				event.Score,
+			)
+		}
+	}
+
+	if solution == nil {
+		return nil, fmt.Errorf("trace has no events")
+	}
+	return solution, nil
+}
+
+func replayEvent(
+	ctx context.Context,
+	model nextroute.Model,
+	solution nextroute.Solution,
+	event TraceEvent,
+) (nextroute.Solution, error) {
+	switch event.Type {
+	case TraceEventStart, TraceEventReset:
+		return replayRoutes(model, event.Routes)
+	case TraceEventBest:
+		if solution == nil {
+			return nil, fmt.Errorf("trace does not start with a solution")
+		}
+		return solution, nil
+	case TraceEventPlan:
+		if solution == nil {
+			return nil, fmt.Errorf("trace does not start with a solution")
+		}
+		return solution, replayPlan(ctx, solution, event.Positions)
+	case TraceEventUnPlan:
+		if solution == nil {
+			return nil, fmt.Errorf("trace does not start with a solution")
+		}
+		return solution, replayUnPlan(solution, event.Stops)
+	default:
+		return nil, fmt.Errorf("unknown event type `%s`", event.Type)
+	}
+}
+
+func replayRoutes(
+	model nextroute.Model,
+	routes [][]int,
+) (nextroute.Solution, error) {
+	if len(routes) != len(model.Vehicles()) {
+		return nil, fmt.Errorf(
+			"trace has %d routes, model has %d vehicles",
+			len(routes),
+			len(model.Vehicles()),
+		)
+	}
+	solutionRoutes := make(nextroute.SolutionRoutes, len(routes))
+	for idx, route := range routes {
+		stops := make(nextroute.ModelStops, len(route))
+		for i, index := range route {
+			stop, err := model.Stop(index)
+			if err != nil {
+				return nil, err
+			}
+			stops[i] = stop
+		}
+		solutionRoutes[idx] = nextroute.SolutionRoute{
+			Vehicle: model.Vehicles()[idx],
+			Stops:   stops,
+		}
+	}
+
+	solution, infeasibilities, err := nextroute.NewRoutesSolution(
+		model,
+		solutionRoutes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(infeasibilities) > 0 {
+		return nil, errors.New(infeasibilities[0].String())
+	}
+	return solution, nil
+}
+
+func replayPlan(
+	ctx context.Context,
+	solution nextroute.Solution,
+	positions [][3]int,
+) error {
+	if len(positions) == 0 {
+		return fmt.Errorf("plan event has no positions")
+	}
+	stopPositions := make(nextroute.StopPositions, len(positions))
+	for idx, position := range positions {
+		var solutionStops [3]nextroute.SolutionStop
+		for i, index := range position {
+			solutionStop, err := solutionStop(solution, index)
+			if err != nil {
+				return err
+			}
+			solutionStops[i] = solutionStop
+		}
+		stopPosition, err := nextroute.NewStopPosition(
+			solutionStops[0],
+			solutionStops[1],
+			solutionStops[2],
+		)
+		if err != nil {
+			return err
+		}
+		stopPositions[idx] = stopPosition
+	}
+
+	move, err := nextroute.NewMoveStops(
+		stopPositions[0].Stop().PlanStopsUnit(),
+		stopPositions,
+	)
+	if err != nil {
+		return err
+	}
+	planned, err := move.Execute(ctx)
+	if err != nil {
+		return err
+	}
+	if !planned {
+		return fmt.Errorf("move %v could not be executed", move)
+	}
+	return nil
+}
+
+func replayUnPlan(solution nextroute.Solution, stops []int) error {
+	if len(stops) == 0 {
+		return fmt.Errorf("unplan event has no stops")
+	}
+	solutionStop, err := solutionStop(solution, stops[0])
+	if err != nil {
+		return err
+	}
+	unplanned, err := solutionStop.PlanStopsUnit().UnPlan()
+	if err != nil {
+		return err
+	}
+	if !unplanned {
+		return fmt.Errorf(
+			"plan unit of stop `%s` could not be unplanned",
+			solutionStop.ModelStop().ID(),
+		)
+	}
+	return nil
+}
+
+func solutionStop(
+	solution nextroute.Solution,
+	index int,
+) (nextroute.SolutionStop, error) {
+	stop, err := solution.Model().Stop(index)
+	if err != nil {
+		return nextroute.SolutionStop{}, err
+	}
+	if stop.HasPlanStopsUnit() {
+		return solution.SolutionStop(stop), nil
+	}
+	// The first and last stop of a vehicle are not part of a plan unit.
+	for _, solutionVehicle := range solution.Vehicles() {
+		if solutionVehicle.First().ModelStop().Index() == index {
+			return solutionVehicle.First(), nil
+		}
+		if solutionVehicle.Last().ModelStop().Index() == index {
+			return solutionVehicle.Last(), nil
+		}
+	}
+	return nextroute.SolutionStop{}, fmt.Errorf(
+		"stop `%s` is not part of the solution",
+		stop.ID(),
+	)
+}
+
+// sameScore returns true if the scores are equal up to floating point
+// rounding.
+func sameScore(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
@@ -0,0 +1,153 @@
+// © 2019-present nextmv.io inc
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SolutionDiffOutput lists the differences between two solutions, from the
+// first solution to the second.
+type SolutionDiffOutput struct {
+	// MovedStops are the stops planned on a different vehicle.
+	MovedStops []MovedStopOutput `json:"moved_stops"`
+	// SequenceChanges are the routes in which the stops planned on the same
+	// vehicle in both solutions are visited in a different order.
+	SequenceChanges []SequenceChangeOutput `json:"sequence_changes"`
+	// ArrivalShifts are the stops whose arrival time shifted by more than the
+	// threshold.
+	ArrivalShifts []ArrivalShiftOutput `json:"arrival_shifts"`
+	// NewlyUnplanned are the IDs of the stops which are unplanned in the
+	// second solution and planned in the first.
+	NewlyUnplanned []string `json:"newly_unplanned"`
+	// NewlyPlanned are the IDs of the stops which are planned in the second
+	// solution and unplanned in the first.
+	NewlyPlanned []string `json:"newly_planned"`
+	// Objective is the change of the objective value.
+	Objective ObjectiveDeltaOutput `json:"objective"`
+	// ObjectiveTerms are the changes of the values of the objective terms.
+	ObjectiveTerms []ObjectiveDeltaOutput `json:"objective_terms"`
+}
+
+// MovedStopOutput is a stop planned on a different vehicle.
+type MovedStopOutput struct {
+	// StopID is the ID of the stop.
+	StopID string `json:"stop_id"`
+	// FromVehicleID is the ID of the vehicle in the first solution.
+	FromVehicleID string `json:"from_vehicle_id"`
+	// ToVehicleID is the ID of the vehicle in the second solution.
+	ToVehicleID string `json:"to_vehicle_id"`
+}
+
+// SequenceChangeOutput is a route in which the stops planned on the vehicle
+// in both solutions are visited in a different order.
+type SequenceChangeOutput struct {
+	// VehicleID is the ID of the vehicle.
+	VehicleID string `json:"vehicle_id"`
+	// From is the order of the stops in the first solution.
+	From []string `json:"from"`
+	// To is the order of the stops in the second solution.
+	To []string `json:"to"`
+}
+
+// ArrivalShiftOutput is a stop whose arrival time shifted.
+type ArrivalShiftOutput struct {
+	// StopID is the ID of the stop.
+	StopID string `json:"stop_id"`
+	// VehicleID is the ID of the vehicle in the second solution.
+	VehicleID string `json:"vehicle_id"`
+	// From is the arrival time in the first solution.
+	From time.Time `json:"from"`
+	// To is the arrival time in the second solution.
+	To time.Time `json:"to"`
+	// Shift is the shift of the arrival time in seconds, positive if the
+	// stop is reached later.
+	Shift int `json:"shift"`
+}
+
+// ObjectiveDeltaOutput is the change of the value of an objective.
+type ObjectiveDeltaOutput struct {
+	// Name is the name of the objective.
+	Name string `json:"name"`
+	// From is the value in the first solution.
+	From float64 `json:"from"`
+	// To is the value in the second solution.
+	To float64 `json:"to"`
+	// Delta is the change of the value.
+	Delta float64 `json:"delta"`
+}
+
+// String returns a human-readable summary of the differences, one line per
+// difference.
+func (d SolutionDiffOutput) String() string {
+	var builder strings.Builder
+	objective := func(delta ObjectiveDeltaOutput) string {
+		return fmt.Sprintf(
+			"%s: %.2f -> %.2f (%+.2f)\n",
+			delta.Name,
+			delta.From,
+			delta.To,
+			delta.Delta,
+		)
+	}
+
+	builder.WriteString(objective(d.Objective))
+	for _, term := range d.ObjectiveTerms {
+		builder.WriteString("  " + objective(term))
+	}
+	if len(d.MovedStops) > 0 {
+		builder.WriteString("moved stops:\n")
+		for _, moved := range d.MovedStops {
+			fmt.Fprintf(
+				&builder,
+				"  %s: %s -> %s\n",
+				moved.StopID,
+				moved.FromVehicleID,
+				moved.ToVehicleID,
+			)
+		}
+	}
+	if len(d.SequenceChanges) > 0 {
+		builder.WriteString("sequence changes:\n")
+		for _, change := range d.SequenceChanges {
+			fmt.Fprintf(
+				&builder,
+				"  %s: %s -> %s\n",
+				change.VehicleID,
+				strings.Join(change.From, ", "),
+				strings.Join(change.To, ", "),
+			)
+		}
+	}
+	if len(d.ArrivalShifts) > 0 {
+		builder.WriteString("arrival shifts:\n")
+		for _, shift := range d.ArrivalShifts {
+			sign := "+"
+			if shift.Shift < 0 {
+				sign = "-"
+			}
+			duration := time.Duration(shift.Shift) * time.Second
+			if duration < 0 {
+				duration = -duration
+			}
+			fmt.Fprintf(
+				&builder,
+				"  %s on %s: %s%v\n",
+				shift.StopID,
+				shift.VehicleID,
+				sign,
+				duration,
+			)
+		}
+	}
+	if len(d.NewlyUnplanned) > 0 {
+		fmt.Fprintf(&builder, "newly unplanned: %s\n", strings.Join(d.NewlyUnplanned, ", "))
+	}
+	if len(d.NewlyPlanned) > 0 {
+		fmt.Fprintf(&builder, "newly planned: %s\n", strings.Join(d.NewlyPlanned, ", "))
+	}
+
+	return builder.String()
+}
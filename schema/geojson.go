@@ -0,0 +1,44 @@
+// © 2019-present nextmv.io inc
+
+package schema
+
+// GeoJSON types of the geometries and objects used in the GeoJSON output.
+const (
+	// GeoJSONFeatureCollection is the type of a feature collection.
+	GeoJSONFeatureCollection = "FeatureCollection"
+	// GeoJSONFeature is the type of a feature.
+	GeoJSONFeature = "Feature"
+	// GeoJSONPoint is the type of a point geometry.
+	GeoJSONPoint = "Point"
+	// GeoJSONLineString is the type of a line string geometry.
+	GeoJSONLineString = "LineString"
+)
+
+// FeatureCollection represents a solution as a GeoJSON feature collection.
+type FeatureCollection struct {
+	// Type is always "FeatureCollection".
+	Type string `json:"type"`
+	// Features are the routes, the planned stops and the unplanned stops of
+	// the solution.
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	// Type is always "Feature".
+	Type string `json:"type"`
+	// Geometry is the geometry of the feature.
+	Geometry Geometry `json:"geometry"`
+	// Properties are the properties of the feature.
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry.
+type Geometry struct {
+	// Type is the type of the geometry, "Point" or "LineString".
+	Type string `json:"type"`
+	// Coordinates are the coordinates of the geometry as longitude and
+	// latitude, a single position for a point and a slice of positions for a
+	// line string.
+	Coordinates any `json:"coordinates"`
+}
@@ -4,6 +4,7 @@
 package schema
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/nextmv-io/sdk/measure"
@@ -24,8 +25,18 @@ type Input struct {
 	// The latter allows to pass time dependent matrices by either scaling a
 	// default matrix or by passing a matrix per time frame.
 	DurationMatrix any `json:"duration_matrix,omitempty"`
+	// DurationMatrixFile path of a binary file holding the matrix of
+	// durations in seconds between stops, instead of the duration matrix.
+	// See the matrixfile package for the supported formats. Matrix files are
+	// only read if the model options enable them, the paths of all matrix
+	// files of the input are relative to the matrix files directory of the
+	// model options.
+	DurationMatrixFile string `json:"duration_matrix_file,omitempty"`
 	// DistanceMatrix matrix of distances in meters between stops.
 	DistanceMatrix *[][]float64 `json:"distance_matrix,omitempty"`
+	// DistanceMatrixFile path of a binary file holding the matrix of
+	// distances in meters between stops, instead of the distance matrix.
+	DistanceMatrixFile string `json:"distance_matrix_file,omitempty"`
 	// DurationGroups duration in seconds added when approaching the group.
 	DurationGroups *[]DurationGroup `json:"duration_groups,omitempty"`
 	// Vehicles to route.
@@ -43,6 +54,9 @@ type TimeDependentMatrix struct {
 	VehicleIDs []string `json:"vehicle_ids,omitempty"`
 	// DefaultMatrix is the default duration matrix used for undefined time frames
 	DefaultMatrix [][]float64 `json:"default_matrix"`
+	// DefaultMatrixFile path of a binary file holding the default duration
+	// matrix, instead of the default matrix.
+	DefaultMatrixFile string `json:"default_matrix_file,omitempty"`
 
 	// MatrixTimeFrames contains time-dependent matrices or scaling factors
 	MatrixTimeFrames []MatrixTimeFrame `json:"matrix_time_frames,omitempty"`
@@ -59,6 +73,10 @@ type MatrixTimeFrame struct {
 	// Matrix is the full duration matrix for this time frame
 	Matrix [][]float64 `json:"matrix,omitempty"`
 
+	// MatrixFile path of a binary file holding the full duration matrix for
+	// this time frame, instead of the matrix
+	MatrixFile string `json:"matrix_file,omitempty"`
+
 	// ScalingFactor is applied to the default matrix during this time frame
 	ScalingFactor *float64 `json:"scaling_factor,omitempty"`
 }
@@ -250,16 +268,45 @@ type MixItem struct {
 	Quantity int `json:"quantity"`
 }
 
-// Location represents a geographical location.
+// Location represents a geographical location or, if it has x and y
+// coordinates, a planar location.
 type Location struct {
 	// Lon longitude of the location.
-	Lon float64 `json:"lon" minimum:"-180" maximum:"180"`
+	Lon float64 `json:"lon,omitempty" minimum:"-180" maximum:"180"`
 	// Lat latitude of the location.
-	Lat float64 `json:"lat" minimum:"-90" maximum:"90"`
+	Lat float64 `json:"lat,omitempty" minimum:"-90" maximum:"90"`
+	// X coordinate of a planar location in meters.
+	X *float64 `json:"x,omitempty"`
+	// Y coordinate of a planar location in meters.
+	Y *float64 `json:"y,omitempty"`
+}
+
+// IsPlanar returns true if the location has an x or y coordinate.
+func (l Location) IsPlanar() bool {
+	return l.X != nil || l.Y != nil
+}
+
+// MarshalJSON marshals the x and y coordinates of a planar location and the
+// longitude and latitude of a geographical location.
+func (l Location) MarshalJSON() ([]byte, error) {
+	if l.IsPlanar() {
+		return json.Marshal(struct {
+			X *float64 `json:"x,omitempty"`
+			Y *float64 `json:"y,omitempty"`
+		}{l.X, l.Y})
+	}
+	return json.Marshal(struct {
+		Lon float64 `json:"lon"`
+		Lat float64 `json:"lat"`
+	}{l.Lon, l.Lat})
 }
 
-// ToPoint converts a schema.Location to a measure.Point.
+// ToPoint converts a schema.Location to a measure.Point, the x and y
+// coordinates of a planar location.
 func (l Location) ToPoint() measure.Point {
+	if l.X != nil && l.Y != nil {
+		return measure.Point{*l.X, *l.Y}
+	}
 	return measure.Point{l.Lon, l.Lat}
 }
 
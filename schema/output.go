@@ -18,6 +18,101 @@ type SolutionOutput struct {
 	Objective ObjectiveOutput `json:"objective"`
 	// Check is the check of the solution.
 	Check *schema.Output `json:"check,omitempty"`
+	// Pool is the position of the solution in a pool of diverse solutions.
+	Pool *SolutionPoolOutput `json:"pool,omitempty"`
+	// Scenario compares the solution of a scenario with the solution of the
+	// base input.
+	Scenario *ScenarioOutput `json:"scenario,omitempty"`
+	// Evaluation describes how evaluated routes were scored, only set when
+	// routes are evaluated instead of solved.
+	Evaluation *EvaluationOutput `json:"evaluation,omitempty"`
+	// Violations is the list of stops of evaluated routes which were dropped
+	// and of the constraints the evaluated routes violate.
+	Violations []ViolationOutput `json:"violations,omitempty"`
+	// UnplannedExplanations explains for each unplanned stop why it is not
+	// planned.
+	UnplannedExplanations []UnplannedExplanationOutput `json:"unplanned_explanations,omitempty"`
+	// Unplannable is the list of stops which the analysis of the input before
+	// solving proved can not be planned.
+	Unplannable []UnplannableStopOutput `json:"unplannable,omitempty"`
+}
+
+// UnplannableStopOutput is a stop which provably can not be planned.
+type UnplannableStopOutput struct {
+	// StopID is the ID of the stop.
+	StopID string `json:"stop_id"`
+	// Reason is the kind of reason for which the stop can not be planned.
+	Reason string `json:"reason"`
+	// Message describes why the stop can not be planned.
+	Message string `json:"message"`
+}
+
+// UnplannedExplanationOutput explains why a stop is not planned.
+type UnplannedExplanationOutput struct {
+	// StopID is the ID of the unplanned stop.
+	StopID string `json:"stop_id"`
+	// Summary is a human-readable summary of the explanation.
+	Summary string `json:"summary"`
+	// Vehicles explains for each vehicle why the stop is not planned on it.
+	Vehicles []VehicleExplanationOutput `json:"vehicles"`
+}
+
+// VehicleExplanationOutput explains why a stop is not planned on a vehicle.
+type VehicleExplanationOutput struct {
+	// VehicleID is the ID of the vehicle.
+	VehicleID string `json:"vehicle_id"`
+	// Constraints are the IDs of the constraints which rejected the insertion
+	// of the stop on the vehicle, most rejections first.
+	Constraints []string `json:"constraints,omitempty"`
+	// DeltaObjective is the change of the objective by the best insertion of
+	// the stop on the vehicle if the insertion is feasible.
+	DeltaObjective *float64 `json:"delta_objective,omitempty"`
+}
+
+// EvaluationOutput describes how evaluated routes were scored.
+type EvaluationOutput struct {
+	// AsSubmitted is true if every stop of the routes is planned as
+	// submitted, the solution is then exactly the submitted plan.
+	AsSubmitted bool `json:"as_submitted"`
+	// DroppedStops is the number of stops which were dropped from the routes
+	// before scoring because they can not be part of any solution, see the
+	// violations. The routes, arrival times and objective of the solution are
+	// those of the routes without these stops.
+	DroppedStops int `json:"dropped_stops"`
+	// ConstraintViolations is the number of violations of constraints by the
+	// stops, the vehicles and the solution as submitted, see the violations.
+	ConstraintViolations int `json:"constraint_violations"`
+}
+
+// ViolationOutput is a stop of an evaluated route or a warm start which was
+// dropped from its route, or a violation of a constraint by an evaluated
+// route.
+type ViolationOutput struct {
+	// StopID is the ID of the stop, empty if the route of the vehicle or the
+	// solution as a whole violates the constraint.
+	StopID string `json:"stop_id,omitempty"`
+	// VehicleID is the ID of the vehicle of the route, empty if the solution
+	// as a whole violates the constraint.
+	VehicleID string `json:"vehicle_id,omitempty"`
+	// Constraint is the ID of the violated constraint, empty if the stop was
+	// dropped from the route before planning.
+	Constraint string `json:"constraint,omitempty"`
+	// Reason describes why the stop was dropped from the route or violates
+	// its route if no constraint is violated.
+	Reason string `json:"reason,omitempty"`
+	// Dropped is true if the stop is not part of the solution.
+	Dropped bool `json:"dropped,omitempty"`
+}
+
+// SolutionPoolOutput is the position of a solution in a ranked pool of
+// diverse solutions.
+type SolutionPoolOutput struct {
+	// Rank is the rank of the solution in the pool, 1 is the best solution.
+	Rank int `json:"rank"`
+	// Similarities is the similarity of the solution to each solution in the
+	// pool in order of rank. A similarity of 1 means all stops are assigned
+	// to the same vehicles, 0 means all stops are assigned differently.
+	Similarities []float64 `json:"similarities"`
 }
 
 // StopOutput is the basic struct for a stop.
@@ -98,10 +193,24 @@ type ObjectiveOutput struct {
 	Base float64 `json:"base,omitempty"`
 	// Value is the value of the objective after the factor is applied.
 	Value float64 `json:"value"`
+	// Priority is the priority level of a lexicographic objective term, lower
+	// priorities are more important.
+	Priority int `json:"priority,omitempty"`
+	// Tiers is the value of each tier of a lexicographic objective, ordered
+	// from the most to the least important tier.
+	Tiers []ObjectiveTierOutput `json:"tiers,omitempty"`
 	// CustomData is the custom data of the objective.
 	CustomData any `json:"custom_data,omitempty"`
 }
 
+// ObjectiveTierOutput represents a tier of a lexicographic objective as JSON.
+type ObjectiveTierOutput struct {
+	// Priority is the priority level of the tier.
+	Priority int `json:"priority"`
+	// Value is the sum of the values of the objective terms in the tier.
+	Value float64 `json:"value"`
+}
+
 // CustomResultStatistics is an example of custom result statistics that can be
 // added to the output and used in experiments.
 type CustomResultStatistics struct {
@@ -129,4 +238,7 @@ type CustomResultStatistics struct {
 	// MinStopsInRoute is the minimum number of stops in a vehicle's route in
 	// the solution. The start and end stops of the vehicle are not considered.
 	MinStopsInVehicle int `json:"min_stops_in_vehicle"`
+	// WarmStartViolations are the stops of the warm start which were dropped
+	// or could not be planned as part of their route.
+	WarmStartViolations []ViolationOutput `json:"warm_start_violations,omitempty"`
 }
@@ -0,0 +1,15 @@
+// © 2019-present nextmv.io inc
+
+package schema
+
+// VehicleProgress is the actual progress of a vehicle executing its route.
+type VehicleProgress struct {
+	// VehicleID is the ID of the vehicle.
+	VehicleID string `json:"vehicle_id"`
+	// CompletedStops are the IDs of the stops the vehicle has completed, in
+	// the order in which they were completed.
+	CompletedStops []string `json:"completed_stops,omitempty"`
+	// Location is the current location of the vehicle. If it is not set, the
+	// vehicle is at the location of its last completed stop.
+	Location *Location `json:"location,omitempty"`
+}
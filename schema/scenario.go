@@ -0,0 +1,36 @@
+// © 2019-present nextmv.io inc
+
+package schema
+
+// Scenario is a modification of an input to analyze what happens if, for
+// example, a vehicle breaks down or a vehicle is added.
+type Scenario struct {
+	// Name of the scenario.
+	Name string `json:"name"`
+	// RemoveVehicles are the IDs of the vehicles removed from the input.
+	RemoveVehicles []string `json:"remove_vehicles,omitempty"`
+	// AddVehicles are the vehicles added to the input.
+	AddVehicles []Vehicle `json:"add_vehicles,omitempty"`
+	// Capacities are the new capacities of the vehicles by vehicle ID.
+	Capacities map[string]any `json:"capacities,omitempty"`
+	// EndTimeShifts are the durations in seconds by which the end times of
+	// the vehicles are shifted by vehicle ID. A negative duration ends the
+	// vehicle earlier.
+	EndTimeShifts map[string]int `json:"end_time_shifts,omitempty"`
+}
+
+// ScenarioOutput compares the solution of a scenario with the solution of the
+// base input.
+type ScenarioOutput struct {
+	// Name is the name of the scenario.
+	Name string `json:"name"`
+	// Objective is the objective of the solution of the scenario.
+	Objective ObjectiveOutput `json:"objective"`
+	// DeltaObjective is the change of the objective value compared to the
+	// solution of the base input.
+	DeltaObjective float64 `json:"delta_objective"`
+	// ActivatedVehicles is the number of vehicles used in the solution.
+	ActivatedVehicles int `json:"activated_vehicles"`
+	// UnplannedStops is the number of stops not planned in the solution.
+	UnplannedStops int `json:"unplanned_stops"`
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"reflect"
 	"slices"
 	"strings"
 	"sync"
@@ -58,6 +57,10 @@ type Solution interface {
 
 	// Score returns the score of the solution.
 	Score() float64
+	// Scores returns the score of each tier of a lexicographic objective
+	// ordered by ModelObjectiveSum.Priorities. If the objective is not
+	// lexicographic, the only element is the score of the solution.
+	Scores() []float64
 
 	// SetRandom sets the random number generator of the solution. Returns an
 	// error if the random number generator is nil.
@@ -326,27 +329,24 @@ func reportInfeasibleInitialSolution(
 	move SolutionMoveStops,
 	constraint ModelConstraint,
 ) string {
-	stopIDs := common.MapSlice(
-		move.StopPositions(),
-		func(stopPosition StopPosition) []string {
-			return []string{stopPosition.Stop().ModelStop().ID()}
-		})
-
-	name := reflect.TypeOf(constraint).Name()
-	stringer, ok := constraint.(fmt.Stringer)
-	if ok {
-		name = stringer.String()
-	}
-	identifier, ok := constraint.(Identifier)
-	if ok {
-		name = identifier.ID()
-	}
-
-	return fmt.Sprintf("infeasible initial solution: vehicle `%v` violates constraint `%v` for stops [%v]",
-		move.Vehicle().ModelVehicle().ID(),
-		name,
-		strings.Join(stopIDs, ", "),
-	)
+	return newRouteInfeasibility(move, constraint).String()
+}
+
+// newRouteInfeasibility returns the infeasibility of the stops of the move
+// violating the constraint.
+func newRouteInfeasibility(
+	move SolutionMoveStops,
+	constraint ModelConstraint,
+) RouteInfeasibility {
+	return RouteInfeasibility{
+		Vehicle: move.Vehicle().ModelVehicle(),
+		Stops: common.Map(
+			move.StopPositions(),
+			func(stopPosition StopPosition) ModelStop {
+				return stopPosition.Stop().ModelStop()
+			}),
+		Constraint: constraint,
+	}
 }
 
 func (s *solutionImpl) addInitialSolution(m Model) error {
@@ -367,206 +367,250 @@ func (s *solutionImpl) addInitialSolution(m Model) error {
 			)
 		}
 
-		initialModelStops := modelVehicle.Stops()
-
-		if len(initialModelStops) == 0 {
-			continue
+		if _, err := s.addRoute(
+			solutionVehicle,
+			modelVehicle.Stops(),
+			solutionObserver,
+		); err != nil {
+			return err
 		}
+	}
 
-		planUnits := common.UniqueDefined(
-			common.Map(
-				initialModelStops,
-				func(modelStop ModelStop) SolutionPlanStopsUnit {
-					return s.SolutionStop(modelStop).PlanStopsUnit()
-				}),
-			func(planUnit SolutionPlanStopsUnit) int {
-				return planUnit.ModelPlanStopsUnit().Index()
-			},
-		)
+	return nil
+}
+
+// addRoute plans the given stops in the given order on the vehicle. Stops
+// which are already planned on the vehicle are kept and used as anchors for
+// the stops to plan. Plan units which can not be planned because they violate
+// a constraint are left unplanned and returned as infeasibilities. An error
+// is returned if a fixed plan unit can not be planned.
+func (s *solutionImpl) addRoute(
+	solutionVehicle SolutionVehicle,
+	initialModelStops ModelStops,
+	solutionObserver InitialSolutionObserver,
+) (RouteInfeasibilities, error) {
+	model := s.model.(*modelImpl)
+	modelVehicle := solutionVehicle.ModelVehicle()
+	infeasibilities := make(RouteInfeasibilities, 0)
+
+	if len(initialModelStops) == 0 {
+		return infeasibilities, nil
+	}
+
+	planUnits := common.UniqueDefined(
+		common.Map(
+			initialModelStops,
+			func(modelStop ModelStop) SolutionPlanStopsUnit {
+				return s.SolutionStop(modelStop).PlanStopsUnit()
+			}),
+		func(planUnit SolutionPlanStopsUnit) int {
+			return planUnit.ModelPlanStopsUnit().Index()
+		},
+	)
 
-		infeasiblePlanUnits := map[SolutionPlanUnit]bool{}
-		allPlanUnits := map[SolutionPlanUnit]bool{}
+	infeasiblePlanUnits := map[SolutionPlanUnit]bool{}
+	allPlanUnits := map[SolutionPlanUnit]bool{}
 
-	PlanUnitLoop:
-		for _, planUnit := range planUnits {
-			stopPositions := make(StopPositions, 0, len(planUnit.SolutionStops()))
-			previousStop := solutionVehicle.First()
+PlanUnitLoop:
+	for _, planUnit := range planUnits {
+		stopPositions := make(StopPositions, 0, len(planUnit.SolutionStops()))
+		previousStop := solutionVehicle.First()
 
-			solutionPlanUnit := s.unwrapRootPlanUnit(planUnit)
-			allPlanUnits[solutionPlanUnit] = true
+		solutionPlanUnit := s.unwrapRootPlanUnit(planUnit)
+		allPlanUnits[solutionPlanUnit] = true
 
-		ModelStopLoop:
-			for modelStopIdx, modelStop := range initialModelStops {
-				if len(stopPositions) == len(planUnit.SolutionStops()) {
-					break
-				}
-				planUnitsUnit, hasPlanUnitsUnit := planUnit.ModelPlanUnit().PlanUnitsUnit()
-				if hasPlanUnitsUnit && planUnitsUnit.PlanOneOf() {
-					solutionPlanUnitsUnit := s.solutionPlanUnitsUnit(planUnitsUnit)
-					if solutionPlanUnitsUnit.IsPlanned() {
-						return fmt.Errorf(
-							"infeasible initial solution: stop %v on vehicle %v is part of one-of plan unit [%v]"+
-								" which is already planned",
-							modelStop.ID(),
-							modelVehicle.ID(),
-							strings.Join(
-								common.MapSlice(
-									planUnitsUnit.PlanUnits(),
-									func(stop ModelPlanUnit) []string {
-										return []string{fmt.Sprintf("%v", stop)}
-									}),
-								", ",
-							),
-						)
-					}
-				}
-				solutionStop := s.SolutionStop(modelStop)
-				if solutionStop.IsPlanned() {
-					previousStop = solutionStop
-				}
-				if modelStop.PlanStopsUnit().Index() == planUnit.ModelPlanStopsUnit().Index() {
-					for nextIdx := modelStopIdx + 1; nextIdx < len(initialModelStops); nextIdx++ {
-						nextModelStop := initialModelStops[nextIdx]
-						nextSolutionStop := s.SolutionStop(nextModelStop)
-						if nextSolutionStop.IsPlanned() ||
-							nextModelStop.PlanStopsUnit().Index() == planUnit.ModelPlanStopsUnit().Index() {
-							stopPositions = append(
-								stopPositions,
-								newStopPosition(
-									previousStop,
-									solutionStop,
-									nextSolutionStop,
-								),
-							)
-							previousStop = solutionStop
-							continue ModelStopLoop
-						}
-						if nextSolutionStop.IsPlanned() {
-							previousStop = solutionStop
-						}
-					}
-					stopPositions = append(
-						stopPositions,
-						newStopPosition(
-							previousStop,
-							solutionStop,
-							solutionVehicle.Last(),
+	ModelStopLoop:
+		for modelStopIdx, modelStop := range initialModelStops {
+			if len(stopPositions) == len(planUnit.SolutionStops()) {
+				break
+			}
+			planUnitsUnit, hasPlanUnitsUnit := planUnit.ModelPlanUnit().PlanUnitsUnit()
+			if hasPlanUnitsUnit && planUnitsUnit.PlanOneOf() {
+				solutionPlanUnitsUnit := s.solutionPlanUnitsUnit(planUnitsUnit)
+				if solutionPlanUnitsUnit.IsPlanned() {
+					return nil, fmt.Errorf(
+						"infeasible initial solution: stop %v on vehicle %v is part of one-of plan unit [%v]"+
+							" which is already planned",
+						modelStop.ID(),
+						modelVehicle.ID(),
+						strings.Join(
+							common.MapSlice(
+								planUnitsUnit.PlanUnits(),
+								func(stop ModelPlanUnit) []string {
+									return []string{fmt.Sprintf("%v", stop)}
+								}),
+							", ",
 						),
 					)
 				}
 			}
-			move, err := newMoveStops(planUnit, stopPositions, false)
-			if err != nil {
-				return err
+			solutionStop := s.SolutionStop(modelStop)
+			if solutionStop.IsPlanned() {
+				previousStop = solutionStop
 			}
-
-			for _, constraint := range model.constraints {
-				if filterConstraint(constraint, false) {
-					continue
-				}
-
-				isViolated, hint := constraint.EstimateIsViolated(move)
-
-				if hint == nil {
-					return newErrorOnNilHint(constraint)
-				}
-
-				s.Model().OnEstimatedIsViolated(move, constraint, isViolated, hint)
-
-				if isViolated {
-					if solutionPlanUnit.IsFixed() {
-						return fmt.Errorf(
-							reportInfeasibleInitialSolution(
-								move,
-								constraint,
+			if modelStop.PlanStopsUnit().Index() == planUnit.ModelPlanStopsUnit().Index() {
+				for nextIdx := modelStopIdx + 1; nextIdx < len(initialModelStops); nextIdx++ {
+					nextModelStop := initialModelStops[nextIdx]
+					nextSolutionStop := s.SolutionStop(nextModelStop)
+					if nextSolutionStop.IsPlanned() ||
+						nextModelStop.PlanStopsUnit().Index() == planUnit.ModelPlanStopsUnit().Index() {
+						stopPositions = append(
+							stopPositions,
+							newStopPosition(
+								previousStop,
+								solutionStop,
+								nextSolutionStop,
 							),
 						)
+						previousStop = solutionStop
+						continue ModelStopLoop
+					}
+					if nextSolutionStop.IsPlanned() {
+						previousStop = solutionStop
 					}
-					infeasiblePlanUnits[solutionPlanUnit] = true
-					continue PlanUnitLoop
 				}
+				stopPositions = append(
+					stopPositions,
+					newStopPosition(
+						previousStop,
+						solutionStop,
+						solutionVehicle.Last(),
+					),
+				)
 			}
+		}
+		move, err := newMoveStops(planUnit, stopPositions, false)
+		if err != nil {
+			return nil, err
+		}
 
-			index, err := move.(*solutionMoveStopsImpl).attach()
-			if err != nil {
-				return err
+		for _, constraint := range model.constraints {
+			if filterConstraint(constraint, false) {
+				continue
 			}
-			constraint, _, err := s.isFeasible(index, false)
-			if err != nil {
-				return err
+
+			isViolated, hint := constraint.EstimateIsViolated(move)
+
+			if hint == nil {
+				return nil, newErrorOnNilHint(constraint)
 			}
-			if constraint != nil {
-				if planUnit.IsFixed() {
-					return fmt.Errorf(
+
+			s.Model().OnEstimatedIsViolated(move, constraint, isViolated, hint)
+
+			if isViolated {
+				if solutionPlanUnit.IsFixed() {
+					return nil, fmt.Errorf(
 						reportInfeasibleInitialSolution(
 							move,
-							solutionObserver.Constraint(),
+							constraint,
 						),
 					)
 				}
-				for _, position := range move.(*solutionMoveStopsImpl).stopPositions {
-					position.Stop().detach()
-				}
 				infeasiblePlanUnits[solutionPlanUnit] = true
-				continue
+				infeasibilities = append(
+					infeasibilities,
+					newRouteInfeasibility(move, constraint),
+				)
+				continue PlanUnitLoop
 			}
 		}
 
-		constraint, index, err := s.isFeasible(solutionVehicle.First().Index(), true)
-		for ; constraint != nil; constraint, index, err = s.isFeasible(solutionVehicle.First().Index(), true) {
-			if err != nil {
-				return err
+		index, err := move.(*solutionMoveStopsImpl).attach()
+		if err != nil {
+			return nil, err
+		}
+		constraint, _, err := s.isFeasible(index, false)
+		if err != nil {
+			return nil, err
+		}
+		if constraint != nil {
+			if planUnit.IsFixed() {
+				return nil, fmt.Errorf(
+					reportInfeasibleInitialSolution(
+						move,
+						solutionObserver.Constraint(),
+					),
+				)
 			}
+			for _, position := range move.(*solutionMoveStopsImpl).stopPositions {
+				position.Stop().detach()
+			}
+			infeasiblePlanUnits[solutionPlanUnit] = true
+			infeasibilities = append(
+				infeasibilities,
+				newRouteInfeasibility(
+					move,
+					solutionObserver.Constraint(),
+				),
+			)
+			continue
+		}
+	}
 
+	constraint, index, err := s.isFeasible(solutionVehicle.First().Index(), true)
+	for ; constraint != nil; constraint, index, err = s.isFeasible(solutionVehicle.First().Index(), true) {
+		if err != nil {
+			return nil, err
+		}
+
+		if index == solutionVehicle.First().Index() {
+			return nil, fmt.Errorf("infeasible initial solution at start of vehicle: %v", constraint)
+		}
+
+		for index == solutionVehicle.Last().Index() ||
+			s.unwrapRootPlanUnit(s.stopToPlanUnit[index]).IsFixed() {
+			index = s.previous[index]
 			if index == solutionVehicle.First().Index() {
-				return fmt.Errorf("infeasible initial solution at start of vehicle: %v", constraint)
+				return nil, fmt.Errorf(
+					"no feasible route from start to end found for vehicle %v"+
+						" due to constraint %v, no further stops to remove",
+					solutionVehicle.ModelVehicle().ID(),
+					constraint)
 			}
+		}
 
-			for index == solutionVehicle.Last().Index() ||
-				s.unwrapRootPlanUnit(s.stopToPlanUnit[index]).IsFixed() {
-				index = s.previous[index]
-				if index == solutionVehicle.First().Index() {
-					return fmt.Errorf(
-						"no feasible route from start to end found for vehicle %v"+
-							" due to constraint %v, no further stops to remove",
-						solutionVehicle.ModelVehicle().ID(),
-						constraint)
+		removedStops := make(ModelStops, 0)
+		for _, solutionPlanUnit := range s.unwrapRootPlanUnit(s.stopToPlanUnit[index]).PlannedPlanStopsUnits() {
+			if solutionPlanUnit.IsPlanned() {
+				for _, solutionStop := range solutionPlanUnit.SolutionStops() {
+					removedStops = append(removedStops, solutionStop.ModelStop())
+					solutionStop.detach()
 				}
 			}
+		}
+		infeasibilities = append(
+			infeasibilities,
+			RouteInfeasibility{
+				Vehicle:    modelVehicle,
+				Stops:      removedStops,
+				Constraint: constraint,
+			},
+		)
 
-			for _, solutionPlanUnit := range s.unwrapRootPlanUnit(s.stopToPlanUnit[index]).PlannedPlanStopsUnits() {
-				if solutionPlanUnit.IsPlanned() {
-					for _, solutionStop := range solutionPlanUnit.SolutionStops() {
-						solutionStop.detach()
-					}
-				}
-			}
+		infeasiblePlanUnits[s.unwrapRootPlanUnit(s.stopToPlanUnit[index])] = true
+	}
 
-			infeasiblePlanUnits[s.unwrapRootPlanUnit(s.stopToPlanUnit[index])] = true
+	for solutionPlanUnit := range allPlanUnits {
+		if _, ok := infeasiblePlanUnits[solutionPlanUnit]; ok {
+			continue
 		}
 
-		for solutionPlanUnit := range allPlanUnits {
-			if _, ok := infeasiblePlanUnits[solutionPlanUnit]; ok {
-				continue
-			}
-
-			s.unPlannedPlanUnits.remove(solutionPlanUnit)
+		s.unPlannedPlanUnits.remove(solutionPlanUnit)
 
-			if solutionPlanUnit.IsFixed() {
-				s.fixedPlanUnits.add(solutionPlanUnit)
-			} else {
-				s.plannedPlanUnits.add(solutionPlanUnit)
-			}
+		if solutionPlanUnit.IsFixed() {
+			s.fixedPlanUnits.add(solutionPlanUnit)
+		} else {
+			s.plannedPlanUnits.add(solutionPlanUnit)
 		}
+	}
 
-		// Make sure all constraints and objectives are up-to-date
-		_, _, err = s.isFeasible(solutionVehicle.First().Index(), true)
-		if err != nil {
-			return err
-		}
+	// Make sure all constraints and objectives are up-to-date
+	_, _, err = s.isFeasible(solutionVehicle.First().Index(), true)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return infeasibilities, nil
 }
 
 type solutionImpl struct {
@@ -944,8 +988,7 @@ func (s *solutionImpl) checkConstraintsAndEstimateDeltaScore(
 
 	s.model.OnEstimateDeltaObjectiveScore()
 
-	objectiveEstimate := 0.0
-	objectiveEstimate = s.Model().Objective().EstimateDeltaValue(m)
+	objectiveEstimate := s.estimateObjectiveDeltaValue(m)
 
 	s.model.OnEstimatedDeltaObjectiveScore(objectiveEstimate)
 
@@ -998,13 +1041,42 @@ func (s *solutionImpl) estimateDeltaScore(
 ) (deltaScore float64) {
 	s.model.OnEstimateDeltaObjectiveScore()
 
-	objectiveEstimate := s.model.(*modelImpl).objective.EstimateDeltaValue(m)
+	objectiveEstimate := s.estimateObjectiveDeltaValue(m)
 
 	s.model.OnEstimatedDeltaObjectiveScore(objectiveEstimate)
 
 	return objectiveEstimate
 }
 
+// estimateObjectiveDeltaValue returns the estimated delta value of the
+// objective. If the objective is lexicographic, the estimated delta value of
+// each tier is set on the move as well, each term is estimated once for both.
+// The terms are estimated one by one, in the same order as the objective sum
+// does, if solution objective observers have to be notified.
+func (s *solutionImpl) estimateObjectiveDeltaValue(m SolutionMoveStops) float64 {
+	model := s.model.(*modelImpl)
+	objective, isSum := model.objective.(*modelObjectiveSumImpl)
+	move, isMove := m.(*solutionMoveStopsImpl)
+	lexicographic := isSum && isMove && objective.IsLexicographic()
+	if !lexicographic && len(model.objectiveObservers) == 0 {
+		return model.objective.EstimateDeltaValue(m)
+	}
+	if lexicographic {
+		move.values = objective.zeroDeltaValues(move.values)
+	}
+	objectiveEstimate := 0.0
+	for idx, term := range model.objective.Terms() {
+		model.OnEstimateDeltaObjectiveTermScore(term)
+		termEstimate := term.Factor() * term.Objective().EstimateDeltaValue(m)
+		model.OnEstimatedDeltaObjectiveTermScore(term, termEstimate)
+		objectiveEstimate += termEstimate
+		if lexicographic {
+			move.values[objective.tiers[idx]] += termEstimate
+		}
+	}
+	return objectiveEstimate
+}
+
 func (s *solutionImpl) ConstraintData(constraint ModelConstraint) any {
 	return s.constraintSolutionData[constraint]
 }
@@ -1024,6 +1096,14 @@ func (s *solutionImpl) Score() float64 {
 	return s.scores[s.model.Objective()]
 }
 
+func (s *solutionImpl) Scores() []float64 {
+	objective, ok := s.model.(*modelImpl).objective.(*modelObjectiveSumImpl)
+	if !ok || !objective.IsLexicographic() {
+		return []float64{s.Score()}
+	}
+	return objective.tierValues(s)
+}
+
 func (s *solutionImpl) FixedPlanUnits() ImmutableSolutionPlanUnitCollection {
 	return &s.fixedPlanUnits
 }
@@ -1168,6 +1248,18 @@ func (s *solutionImpl) isFeasible(index int, includeTemporal bool) (
 	violatedConstraint ModelConstraint,
 	violatedIndex int,
 	err error,
+) {
+	return s.propagate(index, includeTemporal, true)
+}
+
+// propagate updates the values of the stops of the vehicle from the stop at
+// the index onwards, the data of the constraints and objectives and the
+// scores. If check is true, it stops at and returns the first constraint that
+// is not feasible, otherwise no constraint is checked.
+func (s *solutionImpl) propagate(index int, includeTemporal bool, check bool) (
+	violatedConstraint ModelConstraint,
+	violatedIndex int,
+	err error,
 ) {
 	model := s.model.(*modelImpl)
 	vehicle := s.model.Vehicle(s.vehicleIndices[s.inVehicle[index]]).(*modelVehicleImpl)
@@ -1256,6 +1348,10 @@ func (s *solutionImpl) isFeasible(index int, includeTemporal bool) (
 
 		index = next
 
+		if !check {
+			continue
+		}
+
 		for _, constraint := range model.constraintMap[AtEachStop] {
 			if filterConstraint(constraint, includeTemporal) {
 				continue
@@ -1276,7 +1372,7 @@ func (s *solutionImpl) isFeasible(index int, includeTemporal bool) (
 		}
 	}
 	for _, constraint := range model.constraintMap[AtEachSolution] {
-		if filterConstraint(constraint, includeTemporal) {
+		if !check || filterConstraint(constraint, includeTemporal) {
 			continue
 		}
 		if s.isSolutionNotFeasible(constraint) {
@@ -0,0 +1,248 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/nextmv-io/nextroute/common"
+)
+
+// NewSavingsSolution returns a solution for the given model using the
+// savings heuristic.
+func NewSavingsSolution(ctx context.Context, model Model) (Solution, error) {
+	solution, err := NewSolution(model)
+	if err != nil {
+		return nil, err
+	}
+	return SavingsSolutionConstruction(ctx, solution)
+}
+
+// SavingsSolutionConstruction returns a solution by building the routes of
+// the empty vehicles one at a time using the sequential variant of the
+// savings heuristic of Clarke and Wright.
+//
+// A route is seeded with the unplanned plan unit furthest away from the start
+// and end of the vehicle. The route is extended with the unplanned plan unit
+// that has the largest saving when joined to the first or last stop of the
+// route. The saving of joining plan unit u to the last stop b of a route
+// starting at first and ending at last is defined as
+// d(b, last) + d(first, u) - d(b, u), for the first stop a it is
+// d(u, last) + d(first, a) - d(u, a). Distances are measured using the travel
+// duration expression of the vehicle type. A plan unit is only added if the
+// best move for the vehicle is an improvement, which makes sure plan units,
+// time windows, compatibility and all other constraints are respected. When
+// no more plan units can be added to the vehicle, the next empty vehicle is
+// populated. The remaining plan units are added to the solution at the best
+// possible position.
+func SavingsSolutionConstruction(ctx context.Context, s Solution) (Solution, error) {
+	solution := s.Copy()
+
+	emptyVehicles := common.Filter(
+		solution.Vehicles(),
+		func(vehicle SolutionVehicle) bool {
+			return vehicle.IsEmpty()
+		},
+	)
+
+LoopVehicles:
+	for _, vehicle := range emptyVehicles {
+		select {
+		case <-ctx.Done():
+			break LoopVehicles
+		default:
+			if err := savingsPopulateVehicle(ctx, vehicle); err != nil {
+				return nil, err
+			}
+			if solution.UnPlannedPlanUnits().Size() == 0 {
+				break LoopVehicles
+			}
+		}
+	}
+
+	unplannedPlanUnits := slices.Clone(
+		solution.UnPlannedPlanUnits().SolutionPlanUnits(),
+	)
+
+LoopUnplannedPlanUnits:
+	for _, unplannedPlanUnit := range unplannedPlanUnits {
+		select {
+		case <-ctx.Done():
+			break LoopUnplannedPlanUnits
+		default:
+			m := solution.BestMove(ctx, unplannedPlanUnit)
+			if m.IsImprovement() {
+				_, err := m.Execute(ctx)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return solution, nil
+}
+
+type savingsCandidate struct {
+	planUnit SolutionPlanUnit
+	saving   float64
+}
+
+// savingsPopulateVehicle seeds the given empty vehicle and extends it in
+// order of descending savings until no more plan units can be added.
+func savingsPopulateVehicle(
+	ctx context.Context,
+	vehicle SolutionVehicle,
+) error {
+	solution := vehicle.First().Solution()
+	vehicleType := vehicle.ModelVehicle().VehicleType()
+	travel := vehicleType.TravelDurationExpression()
+	first := vehicle.First().ModelStop()
+	last := vehicle.Last().ModelStop()
+
+	distance := func(from, to ModelStop) float64 {
+		return travel.Value(vehicleType, from, to)
+	}
+
+	// distanceFrom returns the smallest distance from stop to any of the
+	// stops of the plan unit.
+	distanceFrom := func(stop ModelStop, stops ModelStops) float64 {
+		minimum := math.MaxFloat64
+		for _, to := range stops {
+			minimum = math.Min(minimum, distance(stop, to))
+		}
+		return minimum
+	}
+
+	// distanceTo returns the smallest distance from any of the stops of the
+	// plan unit to stop.
+	distanceTo := func(stops ModelStops, stop ModelStop) float64 {
+		minimum := math.MaxFloat64
+		for _, from := range stops {
+			minimum = math.Min(minimum, distance(from, stop))
+		}
+		return minimum
+	}
+
+	unplannedStops := func() ([]SolutionPlanUnit, []ModelStops) {
+		planUnits := slices.Clone(
+			solution.UnPlannedPlanUnits().SolutionPlanUnits(),
+		)
+		stops := make([]ModelStops, len(planUnits))
+		for idx, planUnit := range planUnits {
+			stops[idx] = planUnitModelStops(planUnit.ModelPlanUnit())
+		}
+		return planUnits, stops
+	}
+
+	planUnits, stops := unplannedStops()
+	candidates := make([]savingsCandidate, 0, len(planUnits))
+
+	// Seed the route with the plan unit furthest away from the vehicle.
+	for idx, planUnit := range planUnits {
+		if len(stops[idx]) == 0 {
+			continue
+		}
+		candidates = append(candidates, savingsCandidate{
+			planUnit: planUnit,
+			saving: distanceFrom(first, stops[idx]) +
+				distanceTo(stops[idx], last),
+		})
+	}
+
+	planned, err := savingsPlanBestCandidate(ctx, vehicle, candidates)
+	if err != nil || !planned {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			solutionStops := vehicle.SolutionStops()
+			front := solutionStops[1].ModelStop()
+			back := solutionStops[len(solutionStops)-2].ModelStop()
+
+			planUnits, stops = unplannedStops()
+			candidates = candidates[:0]
+
+			for idx, planUnit := range planUnits {
+				if len(stops[idx]) == 0 {
+					continue
+				}
+				appendSaving := distance(back, last) +
+					distanceFrom(first, stops[idx]) -
+					distanceFrom(back, stops[idx])
+				prependSaving := distanceTo(stops[idx], last) +
+					distance(first, front) -
+					distanceTo(stops[idx], front)
+				candidates = append(candidates, savingsCandidate{
+					planUnit: planUnit,
+					saving:   math.Max(appendSaving, prependSaving),
+				})
+			}
+
+			planned, err := savingsPlanBestCandidate(ctx, vehicle, candidates)
+			if err != nil || !planned {
+				return err
+			}
+		}
+	}
+}
+
+// savingsPlanBestCandidate plans the candidate with the largest saving that
+// can be planned on the vehicle. Returns true if a candidate has been planned.
+func savingsPlanBestCandidate(
+	ctx context.Context,
+	vehicle SolutionVehicle,
+	candidates []savingsCandidate,
+) (bool, error) {
+	slices.SortStableFunc(candidates, func(a, b savingsCandidate) int {
+		if a.saving > b.saving {
+			return -1
+		}
+		if a.saving < b.saving {
+			return 1
+		}
+		return 0
+	})
+
+	for _, candidate := range candidates {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		default:
+			m := vehicle.BestMove(ctx, candidate.planUnit)
+			if !m.IsImprovement() {
+				continue
+			}
+			planned, err := m.Execute(ctx)
+			if err != nil {
+				return false, err
+			}
+			if planned {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// planUnitModelStops returns all the stops of the given plan unit. For a plan
+// unit of plan units it returns the stops of all the plan units it consists
+// of.
+func planUnitModelStops(planUnit ModelPlanUnit) ModelStops {
+	if planStopsUnit, ok := planUnit.(ModelPlanStopsUnit); ok {
+		return planStopsUnit.Stops()
+	}
+	stops := make(ModelStops, 0)
+	if planUnitsUnit, ok := planUnit.(ModelPlanUnitsUnit); ok {
+		for _, childPlanUnit := range planUnitsUnit.PlanUnits() {
+			stops = append(stops, planUnitModelStops(childPlanUnit)...)
+		}
+	}
+	return stops
+}
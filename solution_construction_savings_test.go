@@ -0,0 +1,94 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nextmv-io/nextroute"
+)
+
+func TestSavingsPlansAllStops(t *testing.T) {
+	input := singleVehiclePlanSingleStopsModel()
+	input.Vehicles = append(input.Vehicles, vehicles("truck", depot(), 1)...)
+	input.PlanSequences = planPairSequences()
+	model, err := createModel(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = model.Objective().NewTerm(
+		1.0,
+		nextroute.NewUnPlannedObjective(
+			nextroute.NewStopExpression("unplanned", 1000.0),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solution, err := nextroute.NewSavingsSolution(context.Background(), model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if solution.UnPlannedPlanUnits().Size() != 0 {
+		t.Errorf(
+			"expected all plan units to be planned, got %v unplanned",
+			solution.UnPlannedPlanUnits().Size(),
+		)
+	}
+}
+
+func TestSavingsRespectsMaximumStops(t *testing.T) {
+	input := singleVehiclePlanSingleStopsModel()
+	input.Vehicles = append(input.Vehicles, vehicles("truck", depot(), 1)...)
+	model, err := createModel(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = model.Objective().NewTerm(
+		1.0,
+		nextroute.NewUnPlannedObjective(
+			nextroute.NewStopExpression("unplanned", 1000.0),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maximumStops, err := nextroute.NewMaximumStopsConstraint(
+		nextroute.NewVehicleTypeValueExpression("maximum_stops", 2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = model.AddConstraint(maximumStops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solution, err := nextroute.NewSavingsSolution(context.Background(), model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if solution.UnPlannedPlanUnits().Size() != 0 {
+		t.Errorf(
+			"expected all plan units to be planned, got %v unplanned",
+			solution.UnPlannedPlanUnits().Size(),
+		)
+	}
+
+	for _, vehicle := range solution.Vehicles() {
+		if vehicle.NumberOfStops() > 2 {
+			t.Errorf(
+				"expected at most 2 stops on vehicle %v, got %v",
+				vehicle.ModelVehicle().ID(),
+				vehicle.NumberOfStops(),
+			)
+		}
+	}
+}
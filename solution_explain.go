@@ -0,0 +1,283 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// UnplannedExplanation explains why a plan unit of a solution is unplanned.
+type UnplannedExplanation struct {
+	// PlanUnit is the unplanned plan unit.
+	PlanUnit ModelPlanUnit
+	// Vehicles explains for each vehicle of the solution why the plan unit is
+	// not planned on it.
+	Vehicles []VehicleUnplannedExplanation
+}
+
+// UnplannedExplanations is a slice of unplanned explanations.
+type UnplannedExplanations []UnplannedExplanation
+
+// VehicleUnplannedExplanation explains why a plan unit is not planned on a
+// vehicle.
+type VehicleUnplannedExplanation struct {
+	// Vehicle is the vehicle the plan unit is not planned on.
+	Vehicle ModelVehicle
+	// Constraints are the names of the constraints which rejected the
+	// insertion of the plan unit on the vehicle, ordered by the number of
+	// rejected insertion positions, most rejections first.
+	Constraints []string
+	// Feasible is true if the best insertion of the plan unit on the vehicle
+	// is feasible.
+	Feasible bool
+	// DeltaScore is the change of the score of the solution by the best
+	// insertion of the plan unit on the vehicle, zero if not feasible.
+	DeltaScore float64
+}
+
+// ExplainUnplanned explains for each unplanned plan unit of the solution why
+// it is not planned on each of the vehicles. For each vehicle the best
+// insertion of the plan unit is evaluated on a copy of the solution: either
+// the constraints which rejected the insertion are named, using the
+// [Identifier] of the constraint if implemented, or the change of the score
+// is given if the insertion is feasible but does not improve the solution.
+// Stops explaining when the context is done and returns the explanations
+// found so far.
+func ExplainUnplanned(
+	ctx context.Context,
+	solution Solution,
+) (UnplannedExplanations, error) {
+	copied := solution.Copy()
+	model := copied.Model()
+
+	observer := &explainObserver{rejections: map[string]int{}}
+	model.AddSolutionObserver(observer)
+	defer model.RemoveSolutionObserver(observer)
+
+	planUnits := copied.UnPlannedPlanUnits().SolutionPlanUnits()
+	explanations := make(UnplannedExplanations, 0, len(planUnits))
+	for _, planUnit := range planUnits {
+		select {
+		case <-ctx.Done():
+			return explanations, nil
+		default:
+		}
+
+		explanation := UnplannedExplanation{
+			PlanUnit: planUnit.ModelPlanUnit(),
+			Vehicles: make([]VehicleUnplannedExplanation, 0, len(copied.Vehicles())),
+		}
+		for _, vehicle := range copied.Vehicles() {
+			vehicleExplanation, err := explainVehicle(ctx, observer, vehicle, planUnit)
+			if err != nil {
+				return nil, err
+			}
+			explanation.Vehicles = append(explanation.Vehicles, vehicleExplanation)
+		}
+		explanations = append(explanations, explanation)
+	}
+
+	return explanations, nil
+}
+
+// explainVehicle explains why the plan unit is not planned on the vehicle. If
+// the best insertion improves the solution it is executed to make sure it is
+// feasible and un-planned again afterward.
+func explainVehicle(
+	ctx context.Context,
+	observer *explainObserver,
+	vehicle SolutionVehicle,
+	planUnit SolutionPlanUnit,
+) (VehicleUnplannedExplanation, error) {
+	explanation := VehicleUnplannedExplanation{
+		Vehicle: vehicle.ModelVehicle(),
+	}
+
+	observer.reset()
+	move := vehicle.BestMove(ctx, planUnit)
+	if !move.IsExecutable() {
+		explanation.Constraints = observer.rejectedBy()
+		return explanation, nil
+	}
+
+	if move.IsImprovement() {
+		planned, err := move.Execute(ctx)
+		if err != nil {
+			return explanation, err
+		}
+		if !planned {
+			explanation.Constraints = observer.rejectedBy()
+			return explanation, nil
+		}
+		if _, err := planUnit.UnPlan(); err != nil {
+			return explanation, err
+		}
+	}
+
+	explanation.Feasible = true
+	explanation.DeltaScore = move.Value()
+	return explanation, nil
+}
+
+// String returns a human-readable summary of the explanation, the number of
+// vehicles on which each constraint rejected the plan unit and the smallest
+// change of the score of a feasible insertion.
+func (e UnplannedExplanation) String() string {
+	rejections := map[string]int{}
+	constraints := make([]string, 0)
+	feasible := 0
+	var best *VehicleUnplannedExplanation
+	noPosition := 0
+	for idx, vehicle := range e.Vehicles {
+		switch {
+		case vehicle.Feasible:
+			feasible++
+			if best == nil || vehicle.DeltaScore < best.DeltaScore {
+				best = &e.Vehicles[idx]
+			}
+		case len(vehicle.Constraints) == 0:
+			noPosition++
+		default:
+			for _, constraint := range vehicle.Constraints {
+				if _, ok := rejections[constraint]; !ok {
+					constraints = append(constraints, constraint)
+				}
+				rejections[constraint]++
+			}
+		}
+	}
+
+	slices.SortStableFunc(constraints, func(a, b string) int {
+		return rejections[b] - rejections[a]
+	})
+
+	parts := make([]string, 0, len(constraints)+2)
+	for _, constraint := range constraints {
+		parts = append(parts, fmt.Sprintf(
+			"rejected by constraint `%v` on %v",
+			constraint,
+			pluralVehicles(rejections[constraint]),
+		))
+	}
+	if noPosition > 0 {
+		parts = append(parts, fmt.Sprintf(
+			"no insertion position on %v",
+			pluralVehicles(noPosition),
+		))
+	}
+	if best != nil {
+		parts = append(parts, fmt.Sprintf(
+			"feasible on %v, best insertion on vehicle `%v` changes the objective by %.2f",
+			pluralVehicles(feasible),
+			best.Vehicle.ID(),
+			best.DeltaScore,
+		))
+	}
+	if len(parts) == 0 {
+		return "no vehicles"
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func pluralVehicles(n int) string {
+	if n == 1 {
+		return "1 vehicle"
+	}
+	return fmt.Sprintf("%v vehicles", n)
+}
+
+// explainObserver counts the insertion positions rejected by each constraint
+// and records the constraint which failed executing a move.
+type explainObserver struct {
+	rejections map[string]int
+	order      []string
+}
+
+func (o *explainObserver) reset() {
+	o.rejections = map[string]int{}
+	o.order = o.order[:0]
+}
+
+// rejectedBy returns the names of the constraints which rejected a position
+// or failed a move since the last reset, most rejections first.
+func (o *explainObserver) rejectedBy() []string {
+	names := slices.Clone(o.order)
+	slices.SortStableFunc(names, func(a, b string) int {
+		return o.rejections[b] - o.rejections[a]
+	})
+	return names
+}
+
+func (o *explainObserver) reject(constraint ModelConstraint) {
+	name := ConstraintName(constraint)
+	if _, ok := o.rejections[name]; !ok {
+		o.order = append(o.order, name)
+	}
+	o.rejections[name]++
+}
+
+func (o *explainObserver) OnNewSolution(_ Model) {
+}
+
+func (o *explainObserver) OnNewSolutionCreated(_ Solution) {
+}
+
+func (o *explainObserver) OnCopySolution(_ Solution) {
+}
+
+func (o *explainObserver) OnCopiedSolution(_ Solution) {
+}
+
+func (o *explainObserver) OnCheckConstraint(_ ModelConstraint, _ CheckedAt) {
+}
+
+func (o *explainObserver) OnSolutionConstraintChecked(_ ModelConstraint, _ bool) {
+}
+
+func (o *explainObserver) OnStopConstraintChecked(_ SolutionStop, _ ModelConstraint, _ bool) {
+}
+
+func (o *explainObserver) OnVehicleConstraintChecked(_ SolutionVehicle, _ ModelConstraint, _ bool) {
+}
+
+func (o *explainObserver) OnEstimateIsViolated(_ ModelConstraint) {
+}
+
+func (o *explainObserver) OnEstimatedIsViolated(
+	_ SolutionMove,
+	constraint ModelConstraint,
+	isViolated bool,
+	_ StopPositionsHint,
+) {
+	if isViolated {
+		o.reject(constraint)
+	}
+}
+
+func (o *explainObserver) OnEstimateDeltaObjectiveScore() {
+}
+
+func (o *explainObserver) OnEstimatedDeltaObjectiveScore(_ float64) {
+}
+
+func (o *explainObserver) OnBestMove(_ Solution) {
+}
+
+func (o *explainObserver) OnBestMoveFound(_ SolutionMove) {
+}
+
+func (o *explainObserver) OnPlan(_ SolutionMove) {
+}
+
+func (o *explainObserver) OnPlanFailed(_ SolutionMove, constraint ModelConstraint) {
+	if constraint != nil {
+		o.reject(constraint)
+	}
+}
+
+func (o *explainObserver) OnPlanSucceeded(_ SolutionMove) {
+}
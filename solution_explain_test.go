@@ -0,0 +1,97 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nextmv-io/nextroute"
+)
+
+func TestExplainUnplanned(t *testing.T) {
+	model, err := createModel(singleVehiclePlanSingleStopsModel())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maximumStops := nextroute.NewVehicleTypeValueExpression(
+		"maximum stops",
+		1,
+	)
+	cnstr, err := nextroute.NewMaximumStopsConstraint(maximumStops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = model.AddConstraint(cnstr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solution, err := nextroute.NewSolution(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	explanations, err := nextroute.ExplainUnplanned(context.Background(), solution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(explanations) != 3 {
+		t.Fatalf("expected 3 explanations, got %v", len(explanations))
+	}
+	for _, explanation := range explanations {
+		if len(explanation.Vehicles) != 1 || !explanation.Vehicles[0].Feasible {
+			t.Errorf("expected plan unit to be feasible on the vehicle, got %v", explanation)
+		}
+	}
+
+	planUnit := solution.SolutionPlanStopsUnit(model.PlanStopsUnits()[0])
+	position, err := nextroute.NewStopPosition(
+		solution.Vehicles()[0].First(),
+		planUnit.SolutionStops()[0],
+		solution.Vehicles()[0].Last(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err := nextroute.NewMoveStops(
+		planUnit,
+		[]nextroute.StopPosition{position},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned, err := move.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !planned {
+		t.Fatal("expected move to be planned")
+	}
+
+	explanations, err = nextroute.ExplainUnplanned(context.Background(), solution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(explanations) != 2 {
+		t.Fatalf("expected 2 explanations, got %v", len(explanations))
+	}
+	for _, explanation := range explanations {
+		vehicle := explanation.Vehicles[0]
+		if vehicle.Feasible || len(vehicle.Constraints) != 1 {
+			t.Errorf("expected plan unit to be rejected by one constraint, got %v", vehicle)
+		}
+		if !strings.HasPrefix(explanation.String(), "rejected by constraint") {
+			t.Errorf("unexpected summary %q", explanation.String())
+		}
+	}
+
+	if solution.PlannedPlanUnits().Size() != 1 {
+		t.Errorf(
+			"expected explaining to leave the solution unchanged, got %v planned plan units",
+			solution.PlannedPlanUnits().Size(),
+		)
+	}
+}
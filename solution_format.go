@@ -15,11 +15,61 @@ import (
 	"github.com/nextmv-io/sdk/run/statistics"
 )
 
+// Types of the format of the solutions in the output, see
+// [FormatOptions].Type.
+const (
+	// FormatTypeJSON formats each solution as JSON.
+	FormatTypeJSON = "json"
+	// FormatTypeGeoJSON formats each solution as a GeoJSON feature
+	// collection.
+	FormatTypeGeoJSON = "geojson"
+)
+
 // FormatOptions are the options that influence the format of the output.
 type FormatOptions struct {
+	Type    string `json:"type" usage:"format of the solutions, json or geojson" default:"json"`
 	Disable struct {
 		Progression bool `json:"progression" usage:"disable the progression series"`
 	} `json:"disable"`
+	Explain struct {
+		Unplanned bool `json:"unplanned" usage:"explain why stops are unplanned"`
+	} `json:"explain"`
+}
+
+// Validate returns an error if the type of the format is unknown. An empty
+// type is the default JSON format.
+func (o FormatOptions) Validate() error {
+	switch o.Type {
+	case "", FormatTypeJSON, FormatTypeGeoJSON:
+		return nil
+	}
+	return fmt.Errorf(
+		"unknown format type `%s`, expected %s or %s",
+		o.Type,
+		FormatTypeJSON,
+		FormatTypeGeoJSON,
+	)
+}
+
+// ToFormatOptions returns the [FormatOptions] of the options, the field
+// Format of the options if it is of type FormatOptions. Returns the zero
+// value if the options have no such field.
+func ToFormatOptions(options any) FormatOptions {
+	r := reflect.ValueOf(options)
+	if r.Kind() == reflect.Pointer && r.IsNil() {
+		return FormatOptions{}
+	}
+	r = reflect.Indirect(r)
+	if r.Kind() != reflect.Struct {
+		return FormatOptions{}
+	}
+	f := r.FieldByName("Format")
+	if f.IsValid() && f.CanInterface() {
+		if format, ok := f.Interface().(FormatOptions); ok {
+			return format
+		}
+	}
+	return FormatOptions{}
 }
 
 // Format formats a solution in basic format using the map function
@@ -102,14 +152,8 @@ func Format(
 		Value:    &lastProgressionValue,
 	}
 
-	r := reflect.ValueOf(options)
-	f := reflect.Indirect(r).FieldByName("Format")
-	if f.IsValid() && f.CanInterface() {
-		if format, ok := f.Interface().(FormatOptions); ok {
-			if format.Disable.Progression {
-				return output
-			}
-		}
+	if ToFormatOptions(options).Disable.Progression {
+		return output
 	}
 
 	output.Statistics.SeriesData = &statistics.SeriesData{
@@ -123,5 +167,27 @@ func Format(
 		DataPoints: iterationsSeriesData,
 	})
 
+	// A series of the value of each term of the objective, the terms are
+	// recorded with each improvement.
+	for idx, term := range solution.Model().Objective().Terms() {
+		termSeriesData := make([]statistics.DataPoint, 0, len(progressionValues))
+		for _, progressionEntry := range progressionValues {
+			if idx >= len(progressionEntry.Terms) {
+				continue
+			}
+			termSeriesData = append(termSeriesData, statistics.DataPoint{
+				X: statistics.Float64(progressionEntry.ElapsedSeconds),
+				Y: statistics.Float64(progressionEntry.Terms[idx]),
+			})
+		}
+		if len(termSeriesData) == 0 {
+			continue
+		}
+		output.Statistics.SeriesData.Custom = append(output.Statistics.SeriesData.Custom, statistics.Series{
+			Name:       fmt.Sprintf("%v", term.Objective()),
+			DataPoints: termSeriesData,
+		})
+	}
+
 	return output
 }
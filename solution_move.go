@@ -26,16 +26,18 @@ type SolutionMove interface {
 	// IsImprovement returns true if the move is estimated to be executable and
 	// the move has a an estimated delta objective value less than zero, false
 	// if the move is not executable or the move has a value of zero or greater
-	// than zero.
+	// than zero. If the objective is lexicographic, the move is an
+	// improvement if the first tier with a non-zero delta value decreases.
 	IsImprovement() bool
 
 	// PlanUnit returns the [SolutionPlanUnit] that is affected by the move.
 	PlanUnit() SolutionPlanUnit
 
 	// TakeBest returns the best move between the given move and the
-	// current move. The best move is the move with the lowest score. If
-	// the scores are equal, a random uniform distribution is used to
-	// determine the move to use.
+	// current move. The best move is the move with the lowest score, if the
+	// objective is lexicographic the move with the lexicographically lowest
+	// Values. If the scores are equal, a random uniform distribution is used
+	// to determine the move to use.
 	TakeBest(that SolutionMove) SolutionMove
 
 	// Value returns the score of the move. The score is the difference
@@ -45,6 +47,12 @@ type SolutionMove interface {
 	// using Solution.Score after the move has been executed.
 	Value() float64
 
+	// Values returns the estimated delta value of each tier of a
+	// lexicographic objective ordered by ModelObjectiveSum.Priorities. Returns
+	// nil if the objective is not lexicographic, in which case Value is used
+	// to compare moves.
+	Values() []float64
+
 	// ValueSeen returns the number of times the value of this move has been
 	// seen by the estimates. A tie-breaker is a mechanism used to resolve
 	// situations where multiple moves have the same value. In cases where the
@@ -103,6 +111,10 @@ func (m solutionMoveImpl) Value() float64 {
 	return math.Inf(1)
 }
 
+func (m solutionMoveImpl) Values() []float64 {
+	return nil
+}
+
 func (m solutionMoveImpl) ValueSeen() int {
 	return 0
 }
@@ -121,10 +133,10 @@ func takeBestInPlace(best, that SolutionMove) SolutionMove {
 	if !best.IsExecutable() {
 		return tryReplaceBy(best, that, that.ValueSeen())
 	}
-	if best.Value() < that.Value() {
+	switch compareMoves(best, that) {
+	case -1:
 		return best
-	}
-	if best.Value() > that.Value() {
+	case 1:
 		return tryReplaceBy(best, that, that.ValueSeen())
 	}
 	if best.PlanUnit().Solution().Random().Intn(best.ValueSeen()+that.ValueSeen()) == 0 {
@@ -154,6 +166,7 @@ func tryReplaceBy(dst, src SolutionMove, newValueSeen int) SolutionMove {
 	case *solutionMoveStopsImpl:
 		m2 := *m
 		m2.stopPositions = slices.Clone(m.stopPositions)
+		m2.values = slices.Clone(m.values)
 		m2.valueSeen = newValueSeen
 		return &m2
 	case solutionMoveUnitsImpl:
@@ -91,7 +91,10 @@ type solutionMoveStopsImpl struct {
 	stopPositions []StopPosition
 	valueSeen     int
 	value         float64
-	allowed       bool
+	// values holds the delta value of each tier, only set if the objective
+	// is lexicographic.
+	values  []float64
+	allowed bool
 }
 
 // reset resets the move to its initial state.
@@ -100,6 +103,7 @@ func (m *solutionMoveStopsImpl) reset() {
 	m.stopPositions = m.stopPositions[:0]
 	m.allowed = false
 	m.value = 0.0
+	m.values = nil
 	m.valueSeen = 1
 }
 
@@ -108,6 +112,9 @@ func (m *solutionMoveStopsImpl) reset() {
 func (m *solutionMoveStopsImpl) replaceBy(newStop *solutionMoveStopsImpl, newValueSeen int) {
 	m.reset()
 	m.value = newStop.value
+	if newStop.values != nil {
+		m.values = append(make([]float64, 0, len(newStop.values)), newStop.values...)
+	}
 	m.valueSeen = newValueSeen
 	m.allowed = newStop.allowed
 	m.stopPositions = append(m.stopPositions, newStop.stopPositions...)
@@ -260,6 +267,10 @@ func (m *solutionMoveStopsImpl) Value() float64 {
 	return m.value
 }
 
+func (m *solutionMoveStopsImpl) Values() []float64 {
+	return m.values
+}
+
 func (m *solutionMoveStopsImpl) ValueSeen() int {
 	return m.valueSeen
 }
@@ -291,7 +302,7 @@ func (m *solutionMoveStopsImpl) IsExecutable() bool {
 }
 
 func (m *solutionMoveStopsImpl) IsImprovement() bool {
-	return m.IsExecutable() && m.value < 0
+	return m.IsExecutable() && isImprovementValue(m.value, m.values)
 }
 
 func (m *solutionMoveStopsImpl) TakeBest(that SolutionMove) SolutionMove {
@@ -301,10 +312,10 @@ func (m *solutionMoveStopsImpl) TakeBest(that SolutionMove) SolutionMove {
 	if !m.IsExecutable() {
 		return that
 	}
-	if m.value > that.Value() {
+	switch compareMoves(m, that) {
+	case 1:
 		return that
-	}
-	if m.value < that.Value() {
+	case -1:
 		return m
 	}
 	if m.planUnit.solution().random.Intn(m.ValueSeen()+that.ValueSeen()) == 0 {
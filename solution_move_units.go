@@ -21,8 +21,17 @@ func newSolutionMoveUnits(
 	}
 
 	value := 0.0
+	var values []float64
 	for _, move := range moves {
 		value += move.Value()
+		if moveValues := move.Values(); moveValues != nil {
+			if values == nil {
+				values = make([]float64, len(moveValues))
+			}
+			for idx, moveValue := range moveValues {
+				values[idx] += moveValue
+			}
+		}
 	}
 
 	return solutionMoveUnitsImpl{
@@ -30,6 +39,7 @@ func newSolutionMoveUnits(
 		planUnit:  planUnit,
 		moves:     moves,
 		value:     value,
+		values:    values,
 		valueSeen: 1,
 		allowed:   true,
 	}
@@ -49,6 +59,7 @@ type solutionMoveUnitsImpl struct {
 	moves     SolutionMoves
 	valueSeen int
 	value     float64
+	values    []float64
 	allowed   bool
 }
 
@@ -109,6 +120,10 @@ func (m solutionMoveUnitsImpl) Value() float64 {
 	return m.value
 }
 
+func (m solutionMoveUnitsImpl) Values() []float64 {
+	return m.values
+}
+
 func (m solutionMoveUnitsImpl) ValueSeen() int {
 	return m.valueSeen
 }
@@ -126,7 +141,7 @@ func (m solutionMoveUnitsImpl) IsExecutable() bool {
 }
 
 func (m solutionMoveUnitsImpl) IsImprovement() bool {
-	return m.IsExecutable() && m.value < 0
+	return m.IsExecutable() && isImprovementValue(m.value, m.values)
 }
 
 func (m solutionMoveUnitsImpl) TakeBest(that SolutionMove) SolutionMove {
@@ -136,10 +151,10 @@ func (m solutionMoveUnitsImpl) TakeBest(that SolutionMove) SolutionMove {
 	if !m.IsExecutable() {
 		return that
 	}
-	if m.value > that.Value() {
+	switch compareMoves(m, that) {
+	case 1:
 		return that
-	}
-	if m.value < that.Value() {
+	case -1:
 		return m
 	}
 	if m.solution.random.Intn(m.ValueSeen()+that.ValueSeen()) == 0 {
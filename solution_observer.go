@@ -92,11 +92,27 @@ type SolutionUnPlanObserver interface {
 // SolutionUnPlanObservers is a slice of SolutionUnPlanObserver.
 type SolutionUnPlanObservers []SolutionUnPlanObserver
 
+// SolutionObjectiveObserver is an interface that can be implemented to observe
+// the estimation of each term of the objective.
+type SolutionObjectiveObserver interface {
+	// OnEstimateDeltaObjectiveTermScore is called when the delta score of
+	// the term is going to be estimated.
+	OnEstimateDeltaObjectiveTermScore(term ModelObjectiveTerm)
+	// OnEstimatedDeltaObjectiveTermScore is called when the delta score of
+	// the term has been estimated, the estimate includes the factor of the
+	// term.
+	OnEstimatedDeltaObjectiveTermScore(term ModelObjectiveTerm, estimate float64)
+}
+
+// SolutionObjectiveObservers is a slice of SolutionObjectiveObserver.
+type SolutionObjectiveObservers []SolutionObjectiveObserver
+
 // SolutionObserved is an interface that can be implemented to observe the
 // solution manipulation process.
 type SolutionObserved interface {
 	SolutionObserver
 	SolutionUnPlanObserver
+	SolutionObjectiveObserver
 
 	// AddSolutionObserver adds the given solution observer to the solution
 	// observed.
@@ -106,6 +122,11 @@ type SolutionObserved interface {
 	// solution observed.
 	AddSolutionUnPlanObserver(observer SolutionUnPlanObserver)
 
+	// AddSolutionObjectiveObserver adds the given solution objective observer
+	// to the solution observed. The terms of the objective are only estimated
+	// one by one if a solution objective observer has been added.
+	AddSolutionObjectiveObserver(observer SolutionObjectiveObserver)
+
 	// RemoveSolutionObserver remove the given solution observer from the
 	// solution observed.
 	RemoveSolutionObserver(observer SolutionObserver)
@@ -114,16 +135,24 @@ type SolutionObserved interface {
 	// from the solution observed.
 	RemoveSolutionUnPlanObserver(observer SolutionUnPlanObserver)
 
+	// RemoveSolutionObjectiveObserver remove the given solution objective
+	// observer from the solution observed.
+	RemoveSolutionObjectiveObserver(observer SolutionObjectiveObserver)
+
 	// SolutionObservers returns the solution observers.
 	SolutionObservers() SolutionObservers
 
 	// SolutionUnPlanObservers returns the solution un-plan observers.
 	SolutionUnPlanObservers() SolutionUnPlanObservers
+
+	// SolutionObjectiveObservers returns the solution objective observers.
+	SolutionObjectiveObservers() SolutionObjectiveObservers
 }
 
 type solutionObservedImpl struct {
-	observers       SolutionObservers
-	unplanObservers SolutionUnPlanObservers
+	observers          SolutionObservers
+	unplanObservers    SolutionUnPlanObservers
+	objectiveObservers SolutionObjectiveObservers
 }
 
 func (s *solutionObservedImpl) AddSolutionObserver(observer SolutionObserver) {
@@ -149,6 +178,40 @@ func (s *solutionObservedImpl) SolutionUnPlanObservers() SolutionUnPlanObservers
 	return observers
 }
 
+func (s *solutionObservedImpl) AddSolutionObjectiveObserver(observer SolutionObjectiveObserver) {
+	s.objectiveObservers = append(s.objectiveObservers, observer)
+}
+
+func (s *solutionObservedImpl) RemoveSolutionObjectiveObserver(observer SolutionObjectiveObserver) {
+	for i := 0; i < len(s.objectiveObservers); i++ {
+		if s.objectiveObservers[i] == observer {
+			s.objectiveObservers = append(s.objectiveObservers[:i], s.objectiveObservers[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *solutionObservedImpl) SolutionObjectiveObservers() SolutionObjectiveObservers {
+	observers := make(SolutionObjectiveObservers, len(s.objectiveObservers))
+	copy(observers, s.objectiveObservers)
+	return observers
+}
+
+func (s *solutionObservedImpl) OnEstimateDeltaObjectiveTermScore(term ModelObjectiveTerm) {
+	for _, observer := range s.objectiveObservers {
+		observer.OnEstimateDeltaObjectiveTermScore(term)
+	}
+}
+
+func (s *solutionObservedImpl) OnEstimatedDeltaObjectiveTermScore(
+	term ModelObjectiveTerm,
+	estimate float64,
+) {
+	for _, observer := range s.objectiveObservers {
+		observer.OnEstimatedDeltaObjectiveTermScore(term, estimate)
+	}
+}
+
 func (s *solutionObservedImpl) RemoveSolutionObserver(observer SolutionObserver) {
 	for i := 0; i < len(s.observers); i++ {
 		if s.observers[i] == observer {
@@ -0,0 +1,292 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nextmv-io/nextroute/common"
+)
+
+// InsertionQuote is a feasible insertion of an unplanned plan unit into a
+// solution.
+type InsertionQuote struct {
+	// Vehicle is the vehicle the plan unit is inserted on.
+	Vehicle ModelVehicle
+	// Stops are the stops of the plan unit as planned by the insertion, in
+	// the order in which they are visited.
+	Stops []QuotedStop
+	// DeltaScore is the change of the score of the solution by the
+	// insertion.
+	DeltaScore float64
+}
+
+// QuotedStop is a stop of a plan unit as planned by an insertion quote.
+type QuotedStop struct {
+	// Stop is the quoted stop.
+	Stop ModelStop
+	// Previous is the stop visited before the quoted stop.
+	Previous ModelStop
+	// Next is the stop visited after the quoted stop.
+	Next ModelStop
+	// Arrival is the arrival time at the quoted stop.
+	Arrival time.Time
+	// Start is the start of the service of the quoted stop.
+	Start time.Time
+	// End is the end of the service of the quoted stop.
+	End time.Time
+}
+
+// InsertionQuotes is a slice of insertion quotes.
+type InsertionQuotes []InsertionQuote
+
+// Within returns the quotes for which the service of all quoted stops starts
+// within the given window, inclusive.
+func (q InsertionQuotes) Within(start, end time.Time) InsertionQuotes {
+	quotes := make(InsertionQuotes, 0, len(q))
+	for _, quote := range q {
+		within := true
+		for _, stop := range quote.Stops {
+			if stop.Start.Before(start) || stop.Start.After(end) {
+				within = false
+				break
+			}
+		}
+		if within {
+			quotes = append(quotes, quote)
+		}
+	}
+	return quotes
+}
+
+// SetQuotingStop sets the location and the windows of a spare stop of a
+// locked model. It is the scoped way to quote stops which are not part of the
+// model with [QuoteInsertions]: the model is created once with a spare stop,
+// which is set to each stop to quote in turn. The spare stop must be the only
+// stop of its plan unit, must not be fixed and must be unplanned in all
+// solutions of the model while it is set. Expressions defined per stop, such
+// as the duration of a stop, are not changed by setting the stop, but once a
+// stop is set its values in these expressions can be set although the model
+// is locked. Setting a stop is not safe for concurrent use with the solutions
+// of the model.
+//
+// The location, the windows and the values of the stop in expressions are
+// read when a solution is evaluated, so travel durations, arrival times and
+// the constraints and objectives on them follow the set stop. Data the model
+// computed from the stop when it was locked is not updated: the closest stops
+// of the other stops, used to unplan neighbours while solving, still contain
+// the spare stop at its original location, and data of constraints and
+// objectives derived from properties of the stop which are not set, such as
+// its quantities, compatibility attributes or unplanned penalty, stays that
+// of the spare stop. Whether stops can incur waiting time is also decided
+// when the model is locked, therefore windows can only be set on a stop which
+// had windows when it was first set.
+func SetQuotingStop(
+	stop ModelStop,
+	location common.Location,
+	windows [][2]time.Time,
+) error {
+	stopImpl, ok := stop.(*stopImpl)
+	if !ok {
+		return fmt.Errorf("can not set quoting stop of type %T", stop)
+	}
+	if !stopImpl.model.IsLocked() {
+		return fmt.Errorf(
+			"can not set quoting stop %s, the model is not locked",
+			stop.ID(),
+		)
+	}
+	if !stop.HasPlanStopsUnit() ||
+		stop.IsFixed() ||
+		len(stop.PlanStopsUnit().Stops()) != 1 {
+		return fmt.Errorf(
+			"can not set quoting stop %s, it must be the only stop of its "+
+				"plan unit and must not be fixed",
+			stop.ID(),
+		)
+	}
+	if location.IsPlanar() != stopImpl.location.IsPlanar() {
+		return fmt.Errorf(
+			"can not set quoting stop %s, the location must be of the same "+
+				"kind as the location of the stop",
+			stop.ID(),
+		)
+	}
+
+	if !stopImpl.quoting {
+		stopImpl.quotingWindows = stopImpl.windowChecker != nil
+	}
+	if len(windows) > 0 && !stopImpl.quotingWindows {
+		return fmt.Errorf(
+			"can not set windows on quoting stop %s, the stop had no "+
+				"windows when the model was locked",
+			stop.ID(),
+		)
+	}
+
+	if err := stopImpl.setWindows(windows); err != nil {
+		return err
+	}
+	stopImpl.location = location
+	stopImpl.closest = nil
+	stopImpl.quoting = true
+
+	return nil
+}
+
+// QuoteInsertions returns the feasible insertions of the unplanned plan unit
+// into the solution, across all vehicles, ordered by the change of the score,
+// smallest first. The insertions the constraints allow are ranked by their
+// estimated change of the score, as [Solution.BestMove] does, and executed in
+// that order on a copy of the solution to obtain the exact change of the
+// score and the times of the quoted stops, until limit insertions are found.
+// A limit of zero or less executes all allowed insertions. The solution
+// itself is not changed. Stops quoting when the context is done and returns
+// the quotes found so far.
+func QuoteInsertions(
+	ctx context.Context,
+	solution Solution,
+	planUnit SolutionPlanStopsUnit,
+	limit int,
+) (InsertionQuotes, error) {
+	if planUnit.IsPlanned() {
+		return nil, fmt.Errorf(
+			"can not quote plan unit %v, it is already planned",
+			planUnit.ModelPlanStopsUnit().Index(),
+		)
+	}
+
+	copied := solution.Copy()
+	copiedPlanUnit := copied.SolutionPlanStopsUnit(planUnit.ModelPlanStopsUnit())
+	score := copied.Score()
+
+	candidates := make([]quoteCandidate, 0)
+	for _, vehicle := range copied.Vehicles() {
+		candidates = append(
+			candidates,
+			allowedPositions(ctx, vehicle, copiedPlanUnit)...,
+		)
+	}
+	slices.SortStableFunc(candidates, func(a, b quoteCandidate) int {
+		return compareScores(a.estimate, b.estimate)
+	})
+
+	quotes := make(InsertionQuotes, 0)
+	for _, candidate := range candidates {
+		if (limit > 0 && len(quotes) >= limit) || ctx.Err() != nil {
+			break
+		}
+		move, err := NewMoveStops(copiedPlanUnit, candidate.positions)
+		if err != nil {
+			return nil, err
+		}
+		if !move.IsExecutable() {
+			continue
+		}
+		planned, err := move.Execute(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !planned {
+			continue
+		}
+
+		quote := InsertionQuote{
+			Vehicle:    candidate.vehicle,
+			Stops:      make([]QuotedStop, 0, len(candidate.positions)),
+			DeltaScore: copied.Score() - score,
+		}
+		for _, stop := range copiedPlanUnit.SolutionStops() {
+			quote.Stops = append(quote.Stops, QuotedStop{
+				Stop:     stop.ModelStop(),
+				Previous: stop.Previous().ModelStop(),
+				Next:     stop.Next().ModelStop(),
+				Arrival:  stop.Arrival(),
+				Start:    stop.Start(),
+				End:      stop.End(),
+			})
+		}
+		slices.SortFunc(quote.Stops, func(a, b QuotedStop) int {
+			return a.Start.Compare(b.Start)
+		})
+		quotes = append(quotes, quote)
+
+		if _, err := copiedPlanUnit.UnPlan(); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortStableFunc(quotes, func(a, b InsertionQuote) int {
+		return compareScores(a.DeltaScore, b.DeltaScore)
+	})
+
+	return quotes, nil
+}
+
+// compareScores compares two changes of the score, smallest first.
+func compareScores(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// quoteCandidate is an insertion the constraints allow with its estimated
+// change of the score.
+type quoteCandidate struct {
+	vehicle   ModelVehicle
+	positions StopPositions
+	estimate  float64
+}
+
+// allowedPositions returns the insertions of the plan unit on the vehicle the
+// constraints allow.
+func allowedPositions(
+	ctx context.Context,
+	vehicle SolutionVehicle,
+	planUnit SolutionPlanStopsUnit,
+) []quoteCandidate {
+	planUnitImpl := planUnit.(*solutionPlanStopsUnitImpl)
+	container := NewPreAllocatedMoveContainer(planUnit)
+
+	candidates := make([]quoteCandidate, 0)
+	quit := make(chan struct{})
+	defer close(quit)
+	for sequence := range SequenceGeneratorChannel(planUnit, quit) {
+		skipVehicle := false
+		SolutionMoveStopsGenerator(
+			vehicle,
+			planUnitImpl,
+			func(move SolutionMoveStops) {
+				estimate, allowed, hint := vehicle.solution.checkConstraintsAndEstimateDeltaScore(move)
+				if hint.SkipVehicle() {
+					skipVehicle = true
+					return
+				}
+				if allowed {
+					candidates = append(candidates, quoteCandidate{
+						vehicle:   vehicle.ModelVehicle(),
+						positions: slices.Clone(move.StopPositions()),
+						estimate:  estimate,
+					})
+				}
+			},
+			sequence,
+			container,
+			func() bool {
+				return skipVehicle || ctx.Err() != nil
+			},
+		)
+		if skipVehicle || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return candidates
+}
@@ -0,0 +1,501 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nextmv-io/nextroute/common"
+)
+
+// SolutionRoute is a sequence of stops to plan on a vehicle.
+type SolutionRoute struct {
+	// Vehicle is the vehicle to plan the stops on.
+	Vehicle ModelVehicle
+	// Stops are the stops to plan on the vehicle in order of visit, the
+	// first and last stop of the vehicle are not part of the route.
+	Stops ModelStops
+}
+
+// SolutionRoutes is a slice of solution routes.
+type SolutionRoutes []SolutionRoute
+
+// RouteInfeasibility describes stops of a route which could not be planned.
+type RouteInfeasibility struct {
+	// Vehicle is the vehicle of the route.
+	Vehicle ModelVehicle
+	// Stops are the stops which could not be planned.
+	Stops ModelStops
+	// Constraint is the constraint violated by planning the stops, nil if
+	// the stops were dropped from the route before planning.
+	Constraint ModelConstraint
+	// Reason describes why the stops were dropped from the route, empty if
+	// the stops violate the constraint.
+	Reason string
+}
+
+// RouteInfeasibilities is a slice of route infeasibilities.
+type RouteInfeasibilities []RouteInfeasibility
+
+// ConstraintName returns the name of the violated constraint, empty if no
+// constraint is violated.
+func (r RouteInfeasibility) ConstraintName() string {
+	if r.Constraint == nil {
+		return ""
+	}
+	return ConstraintName(r.Constraint)
+}
+
+func (r RouteInfeasibility) String() string {
+	stopIDs := strings.Join(
+		common.Map(r.Stops, func(stop ModelStop) string {
+			return stop.ID()
+		}),
+		", ",
+	)
+	if r.Constraint == nil {
+		return fmt.Sprintf(
+			"infeasible initial solution: stops [%v] on vehicle `%v` %v",
+			stopIDs,
+			r.Vehicle.ID(),
+			r.Reason,
+		)
+	}
+	return fmt.Sprintf(
+		"infeasible initial solution: vehicle `%v` violates constraint `%v` for stops [%v]",
+		r.Vehicle.ID(),
+		r.ConstraintName(),
+		stopIDs,
+	)
+}
+
+// Strings returns the description of each route infeasibility.
+func (r RouteInfeasibilities) Strings() []string {
+	return common.Map(r, func(infeasibility RouteInfeasibility) string {
+		return infeasibility.String()
+	})
+}
+
+// NewRoutesSolution returns a new solution of the model in which the given
+// routes are planned. Plan units planned by the initial stops of the model
+// which are not fixed are unplanned first, fixed stops stay planned and are
+// used as anchors if they are part of a route.
+//
+// Stops which can not be part of the solution are dropped: stops that appear
+// more than once, stops of a plan unit that is already planned on another
+// vehicle, stops of a plan unit of which not all stops are on the route and
+// stops of a one-of plan unit of which another plan unit is already planned.
+// Plan units which violate a constraint are left unplanned. The dropped stops
+// and the infeasible plan units are returned. An error is returned if a fixed
+// plan unit can not be planned.
+func NewRoutesSolution(
+	model Model,
+	routes SolutionRoutes,
+) (Solution, RouteInfeasibilities, error) {
+	solution, err := NewSolution(model)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := solution.(*solutionImpl)
+
+	if err := s.unplanInitialStops(); err != nil {
+		return nil, nil, err
+	}
+
+	solutionObserver := newInitialSolutionObserver()
+	model.AddSolutionObserver(solutionObserver)
+	defer model.RemoveSolutionObserver(solutionObserver)
+
+	infeasibilities := make(RouteInfeasibilities, 0)
+	for _, route := range routes {
+		solutionVehicle, ok := s.solutionVehicle(route.Vehicle)
+		if !ok {
+			return nil, nil, fmt.Errorf(
+				"vehicle %v not found in solution",
+				route.Vehicle.ID(),
+			)
+		}
+		stops, dropped := s.filterRoute(solutionVehicle, route.Stops)
+		infeasibilities = append(infeasibilities, dropped...)
+
+		reports, err := s.addRoute(solutionVehicle, stops, solutionObserver)
+		if err != nil {
+			return nil, nil, err
+		}
+		infeasibilities = append(infeasibilities, reports...)
+	}
+
+	return solution, infeasibilities, nil
+}
+
+// RouteViolation is a violation of a constraint by a solution in which the
+// routes are planned as submitted, see [NewSubmittedSolution].
+type RouteViolation struct {
+	// Vehicle is the vehicle of the route, nil if the solution as a whole
+	// violates the constraint.
+	Vehicle ModelVehicle
+	// Stop is the stop which violates the constraint, nil if the route of the
+	// vehicle or the solution as a whole violates the constraint.
+	Stop ModelStop
+	// Constraint is the violated constraint, nil if the stops of a plan unit
+	// are visited in an order the plan unit does not allow.
+	Constraint ModelConstraint
+	// Reason describes the violation if no constraint is violated.
+	Reason string
+}
+
+// RouteViolations is a slice of route violations.
+type RouteViolations []RouteViolation
+
+// ConstraintName returns the name of the violated constraint, empty if no
+// constraint is violated.
+func (r RouteViolation) ConstraintName() string {
+	if r.Constraint == nil {
+		return ""
+	}
+	return ConstraintName(r.Constraint)
+}
+
+// NewSubmittedSolution returns a new solution of the model in which the given
+// routes are planned as submitted, without checking any constraint. Plan
+// units planned by the initial stops of the model which are not fixed are
+// unplanned first, fixed stops stay planned and are used as anchors if they
+// are part of a route. The values, such as the arrival times, and the score of
+// the solution are those of the submitted routes.
+//
+// Every constraint is checked on every stop, every vehicle and the solution,
+// and all violations are returned, one for each stop or vehicle and each
+// constraint it violates. Constraints which can only be estimated are
+// estimated for each plan unit as if it were inserted at its position in the
+// submitted routes.
+//
+// Stops which can not be part of any solution are dropped and returned as
+// route infeasibilities, see [NewRoutesSolution] for which stops are dropped.
+func NewSubmittedSolution(
+	model Model,
+	routes SolutionRoutes,
+) (Solution, RouteViolations, RouteInfeasibilities, error) {
+	solution, err := NewSolution(model)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s := solution.(*solutionImpl)
+
+	if err := s.unplanInitialStops(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	violations := make(RouteViolations, 0)
+	infeasibilities := make(RouteInfeasibilities, 0)
+	for _, route := range routes {
+		solutionVehicle, ok := s.solutionVehicle(route.Vehicle)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf(
+				"vehicle %v not found in solution",
+				route.Vehicle.ID(),
+			)
+		}
+		stops, dropped := s.filterRoute(solutionVehicle, route.Stops)
+		infeasibilities = append(infeasibilities, dropped...)
+
+		disallowed, err := s.attachRoute(solutionVehicle, stops)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		violations = append(violations, disallowed...)
+	}
+
+	checked, err := s.violations()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return solution, append(violations, checked...), infeasibilities, nil
+}
+
+// unplanInitialStops unplans the plan units planned by the initial stops of
+// the model which are not fixed.
+func (s *solutionImpl) unplanInitialStops() error {
+	plannedPlanUnits := slices.Clone(s.PlannedPlanUnits().SolutionPlanUnits())
+	for _, planUnit := range plannedPlanUnits {
+		if _, err := planUnit.UnPlan(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachRoute plans the stops in the given order on the vehicle without
+// checking any constraint. Stops which are already planned on the vehicle
+// stay at their position and the stops following them on the route are
+// planned after them. The stops of plan units which are visited in an order
+// their plan unit does not allow are returned as violations.
+func (s *solutionImpl) attachRoute(
+	solutionVehicle SolutionVehicle,
+	stops ModelStops,
+) (RouteViolations, error) {
+	violations := make(RouteViolations, 0)
+	if len(stops) == 0 {
+		return violations, nil
+	}
+
+	planUnits := make([]*solutionPlanStopsUnitImpl, 0, len(stops))
+	visits := map[*solutionPlanStopsUnitImpl][]SolutionStop{}
+	previous := solutionVehicle.First().Index()
+	for _, stop := range stops {
+		solutionStop := s.SolutionStop(stop)
+		if !solutionStop.IsPlanned() {
+			solutionStop.attach(previous)
+			planUnit := s.solutionPlanStopsUnit(stop.PlanStopsUnit())
+			if _, ok := visits[planUnit]; !ok {
+				planUnits = append(planUnits, planUnit)
+			}
+			visits[planUnit] = append(visits[planUnit], solutionStop)
+		}
+		previous = solutionStop.Index()
+	}
+
+	for _, planUnit := range planUnits {
+		planUnit.solutionStops = visits[planUnit]
+
+		modelStops := common.Map(planUnit.solutionStops, func(stop SolutionStop) ModelStop {
+			return stop.ModelStop()
+		})
+		allowed, err := planUnit.ModelPlanStopsUnit().DirectedAcyclicGraph().IsAllowed(modelStops)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			for _, stop := range modelStops {
+				violations = append(violations, RouteViolation{
+					Vehicle: solutionVehicle.ModelVehicle(),
+					Stop:    stop,
+					Reason:  "is visited in an order its plan unit does not allow",
+				})
+			}
+		}
+
+		rootPlanUnit := s.unwrapRootPlanUnit(planUnit)
+		if s.unPlannedPlanUnits.SolutionPlanUnit(rootPlanUnit.ModelPlanUnit()) == nil {
+			continue
+		}
+		s.unPlannedPlanUnits.remove(rootPlanUnit)
+		if rootPlanUnit.IsFixed() {
+			s.fixedPlanUnits.add(rootPlanUnit)
+		} else {
+			s.plannedPlanUnits.add(rootPlanUnit)
+		}
+	}
+
+	if _, _, err := s.propagate(solutionVehicle.First().Index(), true, false); err != nil {
+		return nil, err
+	}
+
+	return violations, nil
+}
+
+// violations checks every constraint on every stop, every vehicle and the
+// solution and returns all violations. Constraints which can not be checked
+// are estimated for each planned plan unit, see estimatedViolations.
+func (s *solutionImpl) violations() (RouteViolations, error) {
+	model := s.model.(*modelImpl)
+	violations := make(RouteViolations, 0)
+	for _, solutionVehicle := range s.solutionVehicles {
+		modelVehicle := solutionVehicle.ModelVehicle()
+		for _, solutionStop := range solutionVehicle.SolutionStops()[1:] {
+			for _, constraint := range model.constraintMap[AtEachStop] {
+				if !s.isStopNotFeasible(constraint, solutionStop) {
+					continue
+				}
+				violation := RouteViolation{
+					Vehicle:    modelVehicle,
+					Stop:       solutionStop.ModelStop(),
+					Constraint: constraint,
+				}
+				// The end of the vehicle is not a stop of the route, its
+				// violations are violations of the route.
+				if solutionStop.IsLast() {
+					violation.Stop = nil
+				}
+				violations = append(violations, violation)
+			}
+		}
+		for _, constraint := range model.constraintMap[AtEachVehicle] {
+			if s.isVehicleNotFeasible(constraint, solutionVehicle.Index()) {
+				violations = append(violations, RouteViolation{
+					Vehicle:    modelVehicle,
+					Constraint: constraint,
+				})
+			}
+		}
+	}
+	for _, constraint := range model.constraintMap[AtEachSolution] {
+		if s.isSolutionNotFeasible(constraint) {
+			violations = append(violations, RouteViolation{
+				Constraint: constraint,
+			})
+		}
+	}
+
+	estimated, err := s.estimatedViolations()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(violations, estimated...), nil
+}
+
+// estimatedViolations estimates the constraints which implement no violation
+// check for each planned plan unit, as if the plan unit were inserted at its
+// position in a copy of the solution without it. The stops of plan units
+// which would not be inserted are returned as violations.
+func (s *solutionImpl) estimatedViolations() (RouteViolations, error) {
+	constraints := common.Filter(s.model.Constraints(), func(constraint ModelConstraint) bool {
+		_, stopCheck := constraint.(SolutionStopViolationCheck)
+		_, vehicleCheck := constraint.(SolutionVehicleViolationCheck)
+		_, solutionCheck := constraint.(SolutionViolationCheck)
+		return !stopCheck && !vehicleCheck && !solutionCheck
+	})
+
+	violations := make(RouteViolations, 0)
+	if len(constraints) == 0 {
+		return violations, nil
+	}
+
+	for _, solutionVehicle := range s.solutionVehicles {
+		planUnits := common.UniqueDefined(
+			common.Map(
+				solutionVehicle.SolutionStops()[1:solutionVehicle.NumberOfStops()+1],
+				func(solutionStop SolutionStop) SolutionPlanStopsUnit {
+					return solutionStop.PlanStopsUnit()
+				},
+			),
+			func(planUnit SolutionPlanStopsUnit) int {
+				return planUnit.ModelPlanStopsUnit().Index()
+			},
+		)
+		for _, planUnit := range planUnits {
+			modelStops := common.Map(planUnit.SolutionStops(), func(stop SolutionStop) ModelStop {
+				return stop.ModelStop()
+			})
+			allowed, err := planUnit.ModelPlanStopsUnit().DirectedAcyclicGraph().IsAllowed(modelStops)
+			if err != nil {
+				return nil, err
+			}
+			// The plan unit is reported by attachRoute and can not be moved.
+			if !allowed {
+				continue
+			}
+
+			move, err := s.Copy().(*solutionImpl).removedMove(planUnit.ModelPlanStopsUnit())
+			if err != nil {
+				return nil, err
+			}
+
+			for _, constraint := range constraints {
+				isViolated, _ := constraint.EstimateIsViolated(move)
+				if !isViolated {
+					continue
+				}
+				for _, stop := range modelStops {
+					violations = append(violations, RouteViolation{
+						Vehicle:    solutionVehicle.ModelVehicle(),
+						Stop:       stop,
+						Constraint: constraint,
+					})
+				}
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// removedMove unplans the stops of the planned plan unit from the solution
+// and returns the move which plans them back at their positions.
+func (s *solutionImpl) removedMove(modelPlanUnit ModelPlanStopsUnit) (SolutionMoveStops, error) {
+	planUnit := s.solutionPlanStopsUnit(modelPlanUnit)
+	stops := planUnit.SolutionStops()
+	positions := make(StopPositions, len(stops))
+	for i, stop := range stops {
+		positions[i] = newStopPosition(stop.Previous(), stop, stop.Next())
+	}
+
+	first := stops[0].Vehicle().First().Index()
+	for _, stop := range stops {
+		stop.detach()
+	}
+	if _, _, err := s.propagate(first, true, false); err != nil {
+		return nil, err
+	}
+
+	return newMoveStops(planUnit, positions, false)
+}
+
+// filterRoute returns the stops of the route which can be planned on the
+// vehicle and the dropped stops.
+func (s *solutionImpl) filterRoute(
+	solutionVehicle SolutionVehicle,
+	stops ModelStops,
+) (ModelStops, RouteInfeasibilities) {
+	dropped := make(RouteInfeasibilities, 0)
+	drop := func(stop ModelStop, reason string) {
+		dropped = append(dropped, RouteInfeasibility{
+			Vehicle: solutionVehicle.ModelVehicle(),
+			Stops:   ModelStops{stop},
+			Reason:  reason,
+		})
+	}
+
+	seen := make(map[int]bool, len(stops))
+	stopsInRoute := map[int]int{}
+	for _, stop := range stops {
+		if seen[stop.Index()] {
+			continue
+		}
+		seen[stop.Index()] = true
+		stopsInRoute[stop.PlanStopsUnit().Index()]++
+	}
+
+	filtered := make(ModelStops, 0, len(stops))
+	kept := make(map[int]bool, len(stops))
+	oneOfInRoute := map[int]int{}
+	for _, stop := range stops {
+		if kept[stop.Index()] {
+			drop(stop, "appears more than once")
+			continue
+		}
+		solutionStop := s.SolutionStop(stop)
+		if solutionStop.IsPlanned() {
+			if solutionStop.VehicleIndex() != solutionVehicle.Index() {
+				drop(stop, fmt.Sprintf(
+					"is already planned on vehicle `%v`",
+					solutionStop.Vehicle().ModelVehicle().ID(),
+				))
+				continue
+			}
+			kept[stop.Index()] = true
+			filtered = append(filtered, stop)
+			continue
+		}
+		planUnit := stop.PlanStopsUnit()
+		if stopsInRoute[planUnit.Index()] != planUnit.NumberOfStops() {
+			drop(stop, "is part of a plan unit of which not all stops are on the route")
+			continue
+		}
+		if planUnitsUnit, ok := planUnit.PlanUnitsUnit(); ok && planUnitsUnit.PlanOneOf() {
+			other, inRoute := oneOfInRoute[planUnitsUnit.Index()]
+			if s.solutionPlanUnitsUnit(planUnitsUnit).IsPlanned() ||
+				(inRoute && other != planUnit.Index()) {
+				drop(stop, "is part of a one-of plan unit which is already planned")
+				continue
+			}
+			oneOfInRoute[planUnitsUnit.Index()] = planUnit.Index()
+		}
+		kept[stop.Index()] = true
+		filtered = append(filtered, stop)
+	}
+	return filtered, dropped
+}
@@ -330,7 +330,10 @@ func (v SolutionVehicle) bestMovePlanStopsUnit(
 	planUnit *solutionPlanStopsUnitImpl,
 	preAllocatedMoveContainer *PreAllocatedMoveContainer,
 ) SolutionMove {
-	if planUnit.ModelPlanStopsUnit().NumberOfStops() == 1 {
+	// The single stop fast path compares scalar values only, a lexicographic
+	// objective requires the tier values of the moves.
+	if planUnit.ModelPlanStopsUnit().NumberOfStops() == 1 &&
+		!v.solution.model.Objective().IsLexicographic() {
 		return v.bestMovePlanSingleStop(ctx, planUnit, preAllocatedMoveContainer)
 	}
 
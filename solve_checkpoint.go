@@ -0,0 +1,395 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checkpointVersion is the version of the checkpoint file format.
+const checkpointVersion = 1
+
+// CheckpointOptions configure writing checkpoints of the best solution during
+// a solve of the parallel solver and resuming a solve from a checkpoint.
+type CheckpointOptions struct {
+	Path     string        `json:"path" usage:"file to periodically write a checkpoint of the best solution to, empty disables checkpoints" default:""`
+	Interval time.Duration `json:"interval" usage:"minimum duration between two checkpoints, a checkpoint is only written if the best solution improved" default:"10s"`
+	Resume   string        `json:"resume" usage:"checkpoint file to resume the solve from, the duration and iterations of the checkpoint count towards the limits of the solve" default:""`
+}
+
+// Checkpoint is a snapshot of a solve of the parallel solver. It holds the
+// assignment of the best solution found and the state of the solve. A solve
+// can be resumed from a checkpoint using [CheckpointOptions].Resume or by
+// passing the solution created by [Checkpoint.Solution] as a start solution.
+type Checkpoint struct {
+	// Version is the version of the checkpoint file format.
+	Version int `json:"version"`
+	// Created is the time the checkpoint has been created.
+	Created time.Time `json:"created"`
+	// ElapsedSeconds is the total duration of the solve in seconds,
+	// including the duration of the solves it has been resumed from.
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	// Iterations is the total number of iterations of the solve, including
+	// the iterations of the solves it has been resumed from.
+	Iterations int `json:"iterations"`
+	// Score is the score of the best solution.
+	Score float64 `json:"score"`
+	// Scores are the tier scores of the best solution, only set if the
+	// objective is lexicographic.
+	Scores []float64 `json:"scores,omitempty"`
+	// Vehicles are the routes of the best solution.
+	Vehicles []CheckpointVehicle `json:"vehicles"`
+	// Operators are the parameter values of the solve operators of the
+	// solver which most recently found an improving solution. When resuming,
+	// the values are restored into the solve operators of the first cycle of
+	// runs, see [Checkpoint.RestoreOperators].
+	Operators []CheckpointOperator `json:"operators,omitempty"`
+}
+
+// CheckpointVehicle is the route of a vehicle in a checkpoint.
+type CheckpointVehicle struct {
+	// Index is the index of the vehicle in the model.
+	Index int `json:"index"`
+	// ID is the ID of the vehicle.
+	ID string `json:"id"`
+	// Stops are the stops planned on the vehicle in order of visit,
+	// excluding the first and last stop of the vehicle.
+	Stops []CheckpointStop `json:"stops"`
+}
+
+// CheckpointStop is a stop on a route in a checkpoint.
+type CheckpointStop struct {
+	// Index is the index of the stop in the model.
+	Index int `json:"index"`
+	// ID is the ID of the stop.
+	ID string `json:"id"`
+}
+
+// CheckpointOperator holds the parameter values of a solve operator.
+type CheckpointOperator struct {
+	// Operator is the type of the solve operator.
+	Operator string `json:"operator"`
+	// Parameters are the values of the parameters of the solve operator.
+	Parameters []int `json:"parameters"`
+}
+
+// NewCheckpoint returns a checkpoint of the given solution after the given
+// number of iterations and elapsed duration of the solve.
+func NewCheckpoint(
+	solution Solution,
+	iterations int,
+	elapsed time.Duration,
+) Checkpoint {
+	checkpoint := Checkpoint{
+		Version:        checkpointVersion,
+		Created:        time.Now(),
+		ElapsedSeconds: elapsed.Seconds(),
+		Iterations:     iterations,
+		Score:          solution.Score(),
+		Vehicles:       make([]CheckpointVehicle, 0, len(solution.Vehicles())),
+	}
+	if solution.Model().Objective().IsLexicographic() {
+		checkpoint.Scores = solution.Scores()
+	}
+	for _, vehicle := range solution.Vehicles() {
+		stops := make([]CheckpointStop, 0, vehicle.NumberOfStops())
+		for _, stop := range vehicle.SolutionStops() {
+			if stop.IsFirst() || stop.IsLast() {
+				continue
+			}
+			stops = append(stops, CheckpointStop{
+				Index: stop.ModelStop().Index(),
+				ID:    stop.ModelStop().ID(),
+			})
+		}
+		checkpoint.Vehicles = append(checkpoint.Vehicles, CheckpointVehicle{
+			Index: vehicle.ModelVehicle().Index(),
+			ID:    vehicle.ModelVehicle().ID(),
+			Stops: stops,
+		})
+	}
+	return checkpoint
+}
+
+// checkpointOperators returns the parameter values of the solve operators.
+func checkpointOperators(solveOperators SolveOperators) []CheckpointOperator {
+	operators := make([]CheckpointOperator, 0, len(solveOperators))
+	for _, solveOperator := range solveOperators {
+		parameters := make([]int, 0, len(solveOperator.Parameters()))
+		for _, parameter := range solveOperator.Parameters() {
+			parameters = append(parameters, parameter.Value())
+		}
+		operators = append(operators, CheckpointOperator{
+			Operator:   fmt.Sprintf("%T", solveOperator),
+			Parameters: parameters,
+		})
+	}
+	return operators
+}
+
+// RestoreOperators restores the parameter values of the checkpoint into the
+// given solve operators. Operators are matched by position and type, the
+// values of an operator are only restored if the operator has the same type
+// and number of parameters as in the checkpoint. Parameters which are not a
+// [RestorableSolveParameter] keep their value. Returns the number of
+// restored parameters.
+func (c Checkpoint) RestoreOperators(solveOperators SolveOperators) int {
+	restored := 0
+	for idx, checkpointOperator := range c.Operators {
+		if idx >= len(solveOperators) {
+			break
+		}
+		solveOperator := solveOperators[idx]
+		parameters := solveOperator.Parameters()
+		if fmt.Sprintf("%T", solveOperator) != checkpointOperator.Operator ||
+			len(parameters) != len(checkpointOperator.Parameters) {
+			continue
+		}
+		for p, parameter := range parameters {
+			if restorable, ok := parameter.(RestorableSolveParameter); ok {
+				restorable.Restore(checkpointOperator.Parameters[p])
+				restored++
+			}
+		}
+	}
+	return restored
+}
+
+// Elapsed returns the total duration of the solve.
+func (c Checkpoint) Elapsed() time.Duration {
+	return time.Duration(c.ElapsedSeconds * float64(time.Second))
+}
+
+// Solution returns a new solution of the model with the routes of the
+// checkpoint planned. The model must be the model the checkpoint has been
+// created for, an error is returned if a vehicle or stop of the checkpoint
+// does not match the model.
+func (c Checkpoint) Solution(model Model) (Solution, error) {
+	routes := make(SolutionRoutes, 0, len(c.Vehicles))
+	for _, checkpointVehicle := range c.Vehicles {
+		if checkpointVehicle.Index < 0 ||
+			checkpointVehicle.Index >= len(model.Vehicles()) {
+			return nil, fmt.Errorf(
+				"checkpoint vehicle `%v` index %v does not exist in the model",
+				checkpointVehicle.ID,
+				checkpointVehicle.Index,
+			)
+		}
+		vehicle := model.Vehicle(checkpointVehicle.Index)
+		if vehicle.ID() != checkpointVehicle.ID {
+			return nil, fmt.Errorf(
+				"checkpoint vehicle `%v` at index %v does not match model vehicle `%v`",
+				checkpointVehicle.ID,
+				checkpointVehicle.Index,
+				vehicle.ID(),
+			)
+		}
+		stops := make(ModelStops, 0, len(checkpointVehicle.Stops))
+		for _, checkpointStop := range checkpointVehicle.Stops {
+			stop, err := model.Stop(checkpointStop.Index)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"checkpoint stop `%v` on vehicle `%v`: %w",
+					checkpointStop.ID,
+					checkpointVehicle.ID,
+					err,
+				)
+			}
+			if stop.ID() != checkpointStop.ID {
+				return nil, fmt.Errorf(
+					"checkpoint stop `%v` at index %v does not match model stop `%v`",
+					checkpointStop.ID,
+					checkpointStop.Index,
+					stop.ID(),
+				)
+			}
+			stops = append(stops, stop)
+		}
+		routes = append(routes, SolutionRoute{
+			Vehicle: vehicle,
+			Stops:   stops,
+		})
+	}
+	solution, _, err := NewRoutesSolution(model, routes)
+	return solution, err
+}
+
+// WriteCheckpoint writes the checkpoint as JSON to the file at the given
+// path. The file is replaced atomically, a partially written checkpoint
+// never replaces a previous checkpoint.
+func WriteCheckpoint(path string, checkpoint Checkpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ReadCheckpoint reads a checkpoint written by [WriteCheckpoint] from the file
+// at the given path.
+func ReadCheckpoint(path string) (Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("reading checkpoint: %w", err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return Checkpoint{}, fmt.Errorf("reading checkpoint %v: %w", path, err)
+	}
+	if checkpoint.Version != checkpointVersion {
+		return Checkpoint{}, fmt.Errorf(
+			"reading checkpoint %v: unsupported version %v, expected %v",
+			path,
+			checkpoint.Version,
+			checkpointVersion,
+		)
+	}
+	return checkpoint, nil
+}
+
+// checkpointWriter periodically writes a checkpoint of the best solution of
+// the parallel solver.
+type checkpointWriter struct {
+	lastWrite  time.Time
+	start      time.Time
+	err        error
+	solution   Solution
+	iterations func() int
+	operators  []CheckpointOperator
+	resumed    Checkpoint
+	options    CheckpointOptions
+	mutex      sync.Mutex
+	dirty      bool
+	closed     bool
+}
+
+func newCheckpointWriter(
+	options CheckpointOptions,
+	resumed Checkpoint,
+	start time.Time,
+	iterations func() int,
+) *checkpointWriter {
+	return &checkpointWriter{
+		options:    options,
+		resumed:    resumed,
+		start:      start,
+		iterations: iterations,
+		operators:  resumed.Operators,
+	}
+}
+
+// enabled returns true if checkpoints are written.
+func (w *checkpointWriter) enabled() bool {
+	return w.options.Path != ""
+}
+
+// run writes the latest best solution every interval until the context is
+// done.
+func (w *checkpointWriter) run(ctx context.Context) {
+	if !w.enabled() {
+		return
+	}
+	interval := w.options.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.mutex.Lock()
+				if w.dirty && !w.closed && w.err == nil {
+					w.err = w.write()
+				}
+				w.mutex.Unlock()
+			}
+		}
+	}()
+}
+
+// update sets the best solution to write with the next checkpoint.
+func (w *checkpointWriter) update(solution Solution) {
+	if !w.enabled() {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.solution = solution
+	w.dirty = true
+}
+
+// setOperators sets the solve operators of the solver which most recently
+// found an improving solution.
+func (w *checkpointWriter) setOperators(solveOperators SolveOperators) {
+	operators := checkpointOperators(solveOperators)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.operators = operators
+}
+
+// flush writes the best solution immediately.
+func (w *checkpointWriter) flush() error {
+	if !w.enabled() {
+		return nil
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.write()
+	return w.err
+}
+
+// close writes the final checkpoint and stops writing checkpoints. Returns
+// the first error that occurred writing a checkpoint.
+func (w *checkpointWriter) close() error {
+	err := w.flush()
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.closed = true
+	return err
+}
+
+func (w *checkpointWriter) write() error {
+	if w.solution == nil {
+		return nil
+	}
+	checkpoint := NewCheckpoint(
+		w.solution,
+		w.resumed.Iterations+w.iterations(),
+		w.resumed.Elapsed()+time.Since(w.start),
+	)
+	checkpoint.Operators = w.operators
+	if err := WriteCheckpoint(w.options.Path, checkpoint); err != nil {
+		return err
+	}
+	w.lastWrite = time.Now()
+	w.dirty = false
+	return nil
+}
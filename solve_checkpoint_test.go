@@ -0,0 +1,159 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/sdk/run"
+)
+
+func TestCheckpoint(t *testing.T) {
+	input := singleVehiclePlanSingleStopsModel()
+	input.Vehicles = append(input.Vehicles, vehicles("truck", depot(), 1)...)
+	input.PlanSequences = planPairSequences()
+	model, err := createModel(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = model.Objective().NewTerm(
+		1.0,
+		nextroute.NewUnPlannedObjective(
+			nextroute.NewStopExpression("unplanned", 1000.0),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solution, err := nextroute.NewSavingsSolution(context.Background(), model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	err = nextroute.WriteCheckpoint(
+		path,
+		nextroute.NewCheckpoint(solution, 100, 2*time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint, err := nextroute.ReadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.Iterations != 100 || checkpoint.Elapsed() != 2*time.Second {
+		t.Errorf(
+			"expected 100 iterations and 2s elapsed, got %v and %v",
+			checkpoint.Iterations,
+			checkpoint.Elapsed(),
+		)
+	}
+
+	restored, err := checkpoint.Solution(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if distance := nextroute.AssignmentDistance(solution, restored); distance != 0 {
+		t.Errorf("expected restored solution to have distance 0, got %v", distance)
+	}
+	if restored.Score() != solution.Score() {
+		t.Errorf(
+			"expected restored score %v, got %v",
+			solution.Score(),
+			restored.Score(),
+		)
+	}
+
+	// Resuming a solve which exhausted its iterations returns the solution
+	// of the checkpoint and writes a new checkpoint.
+	solver, err := nextroute.NewParallelSolver(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumedPath := filepath.Join(t.TempDir(), "resumed.json")
+	ctx := context.WithValue(context.Background(), run.Start, time.Now())
+	solutions, err := solver.Solve(ctx, nextroute.ParallelSolveOptions{
+		Iterations:     100,
+		Duration:       time.Minute,
+		ParallelRuns:   1,
+		StartSolutions: 0,
+		Checkpoint: nextroute.CheckpointOptions{
+			Path:     resumedPath,
+			Interval: time.Second,
+			Resume:   path,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := solutions.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Score() != solution.Score() {
+		t.Errorf(
+			"expected resumed score %v, got %v",
+			solution.Score(),
+			last.Score(),
+		)
+	}
+
+	resumed, err := nextroute.ReadCheckpoint(resumedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Iterations != 100 {
+		t.Errorf("expected 100 iterations in resumed checkpoint, got %v", resumed.Iterations)
+	}
+	if resumed.Elapsed() < 2*time.Second {
+		t.Errorf("expected at least 2s elapsed in resumed checkpoint, got %v", resumed.Elapsed())
+	}
+}
+
+func TestCheckpointRestoreOperators(t *testing.T) {
+	parameter, err := nextroute.NewSolveParameter(2, 10, 1, 1, 8, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unplan, err := nextroute.NewSolveOperatorUnPlan(parameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := nextroute.NewSolveOperatorPlan(nextroute.NewConstSolveParameter(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint := nextroute.Checkpoint{
+		Operators: []nextroute.CheckpointOperator{
+			{Operator: fmt.Sprintf("%T", unplan), Parameters: []int{12}},
+			{Operator: fmt.Sprintf("%T", plan), Parameters: []int{5}},
+		},
+	}
+	restored := checkpoint.RestoreOperators(nextroute.SolveOperators{unplan, plan})
+	if restored != 1 {
+		t.Errorf("expected 1 restored parameter, got %v", restored)
+	}
+	// The value is limited to the maximum of the parameter.
+	if value := unplan.Parameters()[0].Value(); value != 8 {
+		t.Errorf("expected restored value 8, got %v", value)
+	}
+	if value := plan.Parameters()[0].Value(); value != 3 {
+		t.Errorf("expected constant value 3, got %v", value)
+	}
+
+	// Operators of a different type are not restored.
+	restored = checkpoint.RestoreOperators(nextroute.SolveOperators{plan, unplan})
+	if restored != 0 {
+		t.Errorf("expected no restored parameters, got %v", restored)
+	}
+}
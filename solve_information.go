@@ -9,6 +9,8 @@ import (
 // SolveInformation contains information about the current solve.
 type SolveInformation interface {
 	// DeltaScore returns the delta score of the last executed solve operator.
+	// For a lexicographic objective it is the delta of the most important
+	// tier that changed.
 	DeltaScore() float64
 
 	// Iteration returns the current iteration.
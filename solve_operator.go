@@ -65,6 +65,32 @@ type InterestedInStartSolve interface {
 // SolveOperators is a slice of solve-operators.
 type SolveOperators []SolveOperator
 
+// SolveOperatorName returns the name of the solve-operator used to report it,
+// such as `plan` or `unplan`. The names of the solve-operators of this
+// package do not change between releases, other solve-operators are named by
+// their type.
+func SolveOperatorName(solveOperator SolveOperator) string {
+	switch solveOperator.(type) {
+	case *solveOperatorPlanImpl:
+		return "plan"
+	case *solveOperatorUnPlanImpl:
+		return "unplan"
+	case *solveOperatorUnPlanUnitsImpl:
+		return "unplan_units"
+	case *solveOperatorUnPlanLocationImpl:
+		return "unplan_location"
+	case *solveOperatorUnPlanVehiclesImpl:
+		return "unplan_vehicles"
+	case *solveOperatorRestartImpl:
+		return "restart"
+	case *solveOperatorAndImpl:
+		return "and"
+	case *solveOperatorOrImpl:
+		return "or"
+	}
+	return fmt.Sprintf("%T", solveOperator)
+}
+
 // NewSolveOperator returns a new solve operator.
 func NewSolveOperator(
 	probability float64,
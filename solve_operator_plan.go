@@ -84,7 +84,7 @@ Loop:
 			}
 
 			if move.IsExecutable() {
-				if move.Value() <= 0 {
+				if compareMoveValue(move) <= 0 {
 					_, err := move.Execute(ctx)
 					if err != nil {
 						return err
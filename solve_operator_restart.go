@@ -58,7 +58,10 @@ func (d *solveOperatorRestartImpl) Execute(
 	_ context.Context,
 	solveRunInformation SolveInformation,
 ) error {
-	if solveRunInformation.Solver().WorkSolution().Score() == solveRunInformation.Solver().BestSolution().Score() {
+	if compareSolutions(
+		solveRunInformation.Solver().WorkSolution(),
+		solveRunInformation.Solver().BestSolution(),
+	) == 0 {
 		d.lastImprovement = solveRunInformation.Iteration()
 	}
 	if solveRunInformation.Iteration()-d.lastImprovement >
@@ -18,6 +18,15 @@ type SolveParameter interface {
 	Value() int
 }
 
+// RestorableSolveParameter is a solve parameter whose value can be restored,
+// for example when a solve is resumed from a [Checkpoint].
+type RestorableSolveParameter interface {
+	SolveParameter
+	// Restore sets the current value of the parameter. The value is limited
+	// to the range of the parameter.
+	Restore(value int)
+}
+
 // SolveParameters is a slice of solve parameters.
 type SolveParameters []SolveParameter
 
@@ -108,6 +117,11 @@ func (i *intParameterImpl) Value() int {
 	return i.value
 }
 
+func (i *intParameterImpl) Restore(value int) {
+	i.value = min(max(value, i.minValue), i.maxValue)
+	i.iterations = 0
+}
+
 func (i *intParameterImpl) Update(solveInformation SolveInformation) {
 	if solveInformation.DeltaScore() < 0.0 {
 		i.iterations = 0
@@ -0,0 +1,186 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ProgressOptions configure streaming an event of each new best solution
+// found by the parallel solver while it is solving.
+type ProgressOptions struct {
+	Path string `json:"path" usage:"file to stream an event of each new best solution to as newline delimited JSON, - streams to stderr, empty disables progress events" default:""`
+}
+
+// ProgressEvent describes a new best solution found by the parallel solver.
+// The events are written as newline delimited JSON while the parallel solver
+// is solving, see [ProgressOptions].
+type ProgressEvent struct {
+	// ElapsedSeconds is the duration of the solve when the solution was
+	// found in seconds.
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	// Iterations is the total number of iterations of all runs when the
+	// solution was found.
+	Iterations int `json:"iterations"`
+	// Value is the score of the solution.
+	Value float64 `json:"value"`
+	// Terms are the values of the terms of the objective.
+	Terms []ProgressTerm `json:"terms"`
+	// PlannedStops is the number of stops planned on the vehicles, excluding
+	// the first and last stop of the vehicles.
+	PlannedStops int `json:"planned_stops"`
+	// PlannedPlanUnits is the number of planned plan units.
+	PlannedPlanUnits int `json:"planned_plan_units"`
+	// UnplannedPlanUnits is the number of unplanned plan units.
+	UnplannedPlanUnits int `json:"unplanned_plan_units"`
+	// Operators are the names of the solve operators executed in the
+	// iteration which found the solution in the order of execution, see
+	// [SolveOperatorName], empty for the start solution.
+	Operators []string `json:"operators,omitempty"`
+	// Run is the run of the parallel solver which found the solution, zero
+	// for the start solution.
+	Run int `json:"run"`
+	// Cycle is the cycle of the run, zero for the start solution.
+	Cycle int `json:"cycle"`
+}
+
+// ProgressTerm is the value of a term of the objective in a progress event.
+type ProgressTerm struct {
+	// Name is the name of the objective of the term.
+	Name string `json:"name"`
+	// Factor is the factor of the term.
+	Factor float64 `json:"factor"`
+	// Value is the value of the term after the factor is applied.
+	Value float64 `json:"value"`
+}
+
+// NewProgressEvent returns the progress event of the given solution after the
+// given number of iterations and elapsed duration of the solve. The operators,
+// run and cycle are not set.
+func NewProgressEvent(
+	solution Solution,
+	iterations int,
+	elapsed time.Duration,
+) ProgressEvent {
+	terms := solution.Model().Objective().Terms()
+	event := ProgressEvent{
+		ElapsedSeconds:     elapsed.Seconds(),
+		Iterations:         iterations,
+		Value:              solution.Score(),
+		Terms:              make([]ProgressTerm, len(terms)),
+		PlannedPlanUnits:   solution.PlannedPlanUnits().Size(),
+		UnplannedPlanUnits: solution.UnPlannedPlanUnits().Size(),
+	}
+	for idx, term := range terms {
+		event.Terms[idx] = ProgressTerm{
+			Name:   fmt.Sprintf("%v", term.Objective()),
+			Factor: term.Factor(),
+			Value:  solution.ObjectiveValue(term.Objective()),
+		}
+	}
+	for _, vehicle := range solution.Vehicles() {
+		event.PlannedStops += vehicle.NumberOfStops()
+	}
+	return event
+}
+
+// progressWriter writes a progress event of each new best solution of the
+// parallel solver. The solvers of the runs report their improving solutions
+// concurrently, only the solutions improving on the best solution written so
+// far are written.
+type progressWriter struct {
+	writer     io.Writer
+	closer     io.Closer
+	best       Solution
+	start      time.Time
+	iterations func() int
+	err        error
+	mutex      sync.Mutex
+	closed     bool
+}
+
+func newProgressWriter(
+	options ProgressOptions,
+	start time.Time,
+	iterations func() int,
+) (*progressWriter, error) {
+	w := &progressWriter{
+		start:      start,
+		iterations: iterations,
+	}
+	switch options.Path {
+	case "":
+	case "-":
+		w.writer = os.Stderr
+	default:
+		file, err := os.Create(options.Path)
+		if err != nil {
+			return nil, fmt.Errorf("writing progress: %w", err)
+		}
+		w.writer = file
+		w.closer = file
+	}
+	return w, nil
+}
+
+// enabled returns true if progress events are written.
+func (w *progressWriter) enabled() bool {
+	return w.writer != nil
+}
+
+// improved writes the progress event of the solution if it improves on the
+// best solution written so far. The information is nil for the start
+// solution.
+func (w *progressWriter) improved(
+	solution Solution,
+	information ParallelSolveInformation,
+	solveOperators SolveOperators,
+) {
+	if !w.enabled() {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed || w.err != nil ||
+		w.best != nil && compareSolutions(solution, w.best) >= 0 {
+		return
+	}
+	w.best = solution
+
+	event := NewProgressEvent(solution, w.iterations(), time.Since(w.start))
+	for _, solveOperator := range solveOperators {
+		event.Operators = append(event.Operators, SolveOperatorName(solveOperator))
+	}
+	if information != nil {
+		event.Run = information.Run()
+		event.Cycle = information.Cycle()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		w.err = err
+		return
+	}
+	if _, err := w.writer.Write(append(data, '\n')); err != nil {
+		w.err = fmt.Errorf("writing progress: %w", err)
+	}
+}
+
+// close stops writing progress events. Returns the first error that
+// occurred writing an event.
+func (w *progressWriter) close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closer != nil {
+		if err := w.closer.Close(); err != nil && w.err == nil {
+			w.err = fmt.Errorf("writing progress: %w", err)
+		}
+		w.closer = nil
+	}
+	w.closed = true
+	return w.err
+}
@@ -0,0 +1,144 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/sdk/run"
+)
+
+func TestProgress(t *testing.T) {
+	input := singleVehiclePlanSingleStopsModel()
+	input.Vehicles = append(input.Vehicles, vehicles("truck", depot(), 1)...)
+	model, err := createModel(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = model.Objective().NewTerm(
+		1.0,
+		nextroute.NewUnPlannedObjective(
+			nextroute.NewStopExpression("unplanned", 1000.0),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solver, err := nextroute.NewParallelSolver(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "progress.ndjson")
+	ctx := context.WithValue(context.Background(), run.Start, time.Now())
+	solutions, err := solver.Solve(ctx, nextroute.ParallelSolveOptions{
+		Iterations:     100,
+		Duration:       time.Minute,
+		ParallelRuns:   2,
+		StartSolutions: 0,
+		Progress:       nextroute.ProgressOptions{Path: path},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := solutions.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	events := make([]nextroute.ProgressEvent, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event nextroute.ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) < 2 {
+		t.Fatalf("expected at least 2 progress events, got %v", len(events))
+	}
+	if events[0].Run != 0 || len(events[0].Operators) != 0 {
+		t.Errorf("expected the start solution as first event, got %+v", events[0])
+	}
+	for idx, event := range events[1:] {
+		if event.Value >= events[idx].Value {
+			t.Errorf(
+				"expected event %v to improve on %v, got %v",
+				idx+1,
+				events[idx].Value,
+				event.Value,
+			)
+		}
+		if event.Run < 1 || event.Cycle < 1 || len(event.Operators) == 0 {
+			t.Errorf("expected run, cycle and operators, got %+v", event)
+		}
+		for _, operator := range event.Operators {
+			if !slices.Contains([]string{"unplan", "plan", "restart"}, operator) {
+				t.Errorf("expected the name of an operator of the default solver, got %v", operator)
+			}
+		}
+		if len(event.Terms) != len(model.Objective().Terms()) {
+			t.Errorf("expected %v terms, got %+v", len(model.Objective().Terms()), event)
+		}
+	}
+	final := events[len(events)-1]
+	if final.Value != last.Score() {
+		t.Errorf("expected last event value %v, got %v", last.Score(), final.Value)
+	}
+	if final.PlannedStops+final.UnplannedPlanUnits != len(input.PlanSingleStops) {
+		t.Errorf(
+			"expected planned and unplanned stops to add up to %v, got %+v",
+			len(input.PlanSingleStops),
+			final,
+		)
+	}
+
+	// The progression records the value of each term with each improvement
+	// and reports it as a series of each term.
+	for _, entry := range solver.Progression() {
+		if len(entry.Terms) != len(model.Objective().Terms()) {
+			t.Fatalf("expected a value of each term, got %+v", entry)
+		}
+		sum := 0.0
+		for _, value := range entry.Terms {
+			sum += value
+		}
+		if math.Abs(sum-entry.Value) > 1e-6 {
+			t.Errorf("expected terms to add up to %v, got %v", entry.Value, sum)
+		}
+	}
+	output := nextroute.Format(ctx, nil, solver, func(s nextroute.Solution) any {
+		return s.Score()
+	}, last)
+	names := map[string]bool{}
+	for _, series := range output.Statistics.SeriesData.Custom {
+		names[series.Name] = true
+	}
+	for _, term := range model.Objective().Terms() {
+		if name := fmt.Sprintf("%v", term.Objective()); !names[name] {
+			t.Errorf("expected series of term %v, got %v", name, names)
+		}
+	}
+}
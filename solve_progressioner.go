@@ -14,4 +14,17 @@ type ProgressionEntry struct {
 	ElapsedSeconds float64 `json:"elapsed_seconds"`
 	Value          float64 `json:"value"`
 	Iterations     int     `json:"iterations"`
+	// Terms are the values of the terms of the objective, after the factor
+	// is applied, in the order of the terms of the objective.
+	Terms []float64 `json:"terms,omitempty"`
+}
+
+// termValues returns the value of each term of the objective of the solution.
+func termValues(solution Solution) []float64 {
+	terms := solution.Model().Objective().Terms()
+	values := make([]float64, len(terms))
+	for idx, term := range terms {
+		values[idx] = solution.ObjectiveValue(term.Objective())
+	}
+	return values
 }
@@ -0,0 +1,167 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// SolutionPoolOptions configure the pool of diverse solutions maintained by
+// the parallel solver.
+type SolutionPoolOptions struct {
+	Size            int     `json:"size" usage:"maximum number of diverse solutions to keep in the pool and report, values smaller than 2 only keep the best solution" default:"1" minimum:"0"`
+	MinimumDistance float64 `json:"minimum_distance" usage:"minimum assignment distance (fraction of plan units assigned to a different vehicle) between the solutions in the pool" default:"0.1" minimum:"0" maximum:"1"`
+}
+
+// SolutionPool is a pool of the best solutions found that differ from each
+// other by at least a minimum assignment distance, see [AssignmentDistance].
+type SolutionPool interface {
+	// Add offers a copy of the solution to the pool. The solution is added if
+	// it is sufficiently different from all solutions in the pool and the
+	// pool is not full or the solution is better than the worst solution in
+	// the pool. If the solution is too similar to solutions in the pool, it
+	// replaces them if it is better than all of them. Returns true if the
+	// solution has been added.
+	Add(solution Solution) bool
+	// Accepts returns false if the pool is full and the solution is not
+	// better than the worst solution in the pool. Accepts neither locks the
+	// pool nor computes distances, a solution it accepts can still be
+	// rejected by Add.
+	Accepts(solution Solution) bool
+	// MaximumSize returns the maximum number of solutions in the pool.
+	MaximumSize() int
+	// MinimumDistance returns the minimum assignment distance between any
+	// two solutions in the pool.
+	MinimumDistance() float64
+	// Solutions returns the solutions in the pool ranked by score, the best
+	// solution first.
+	Solutions() Solutions
+}
+
+// NewSolutionPool returns a new empty solution pool holding at most
+// maximumSize solutions that differ by at least minimumDistance.
+func NewSolutionPool(maximumSize int, minimumDistance float64) SolutionPool {
+	if maximumSize < 1 {
+		maximumSize = 1
+	}
+	return &solutionPoolImpl{
+		maximumSize:     maximumSize,
+		minimumDistance: minimumDistance,
+		solutions:       make(Solutions, 0, maximumSize),
+	}
+}
+
+// AssignmentDistance returns the fraction of plan stops units of the model
+// which are assigned differently in the two solutions. A plan stops unit is
+// assigned differently if it is planned on a different vehicle in both
+// solutions or if it is planned in one solution and unplanned in the other.
+// The distance is 0 if all plan stops units are assigned the same way and 1 if
+// all of them are assigned differently. Both solutions must be solutions of
+// the same model.
+func AssignmentDistance(a, b Solution) float64 {
+	planStopsUnits := a.Model().PlanStopsUnits()
+	if len(planStopsUnits) == 0 {
+		return 0
+	}
+	different := 0
+	for _, planStopsUnit := range planStopsUnits {
+		if assignment(a, planStopsUnit) != assignment(b, planStopsUnit) {
+			different++
+		}
+	}
+	return float64(different) / float64(len(planStopsUnits))
+}
+
+// assignment returns the index of the vehicle the plan stops unit is planned
+// on or -1 if the plan stops unit is unplanned.
+func assignment(solution Solution, planStopsUnit ModelPlanStopsUnit) int {
+	solutionPlanStopsUnit := solution.SolutionPlanStopsUnit(planStopsUnit)
+	if !solutionPlanStopsUnit.IsPlanned() {
+		return -1
+	}
+	return solutionPlanStopsUnit.SolutionStops()[0].VehicleIndex()
+}
+
+type solutionPoolImpl struct {
+	solutions       Solutions
+	maximumSize     int
+	minimumDistance float64
+	mutex           sync.Mutex
+	// worst is the worst solution of a full pool, nil if the pool is not
+	// full.
+	worst atomic.Pointer[Solution]
+}
+
+func (p *solutionPoolImpl) MaximumSize() int {
+	return p.maximumSize
+}
+
+func (p *solutionPoolImpl) MinimumDistance() float64 {
+	return p.minimumDistance
+}
+
+func (p *solutionPoolImpl) Solutions() Solutions {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return slices.Clone(p.solutions)
+}
+
+func (p *solutionPoolImpl) Accepts(solution Solution) bool {
+	worst := p.worst.Load()
+	return worst == nil || compareSolutions(*worst, solution) > 0
+}
+
+func (p *solutionPoolImpl) Add(solution Solution) bool {
+	if !p.Accepts(solution) {
+		return false
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// A full pool only accepts solutions better than the worst solution in
+	// the pool, any similar solution it could replace is better as well.
+	if len(p.solutions) >= p.maximumSize &&
+		compareSolutions(p.solutions[len(p.solutions)-1], solution) <= 0 {
+		return false
+	}
+
+	similar := make([]int, 0, len(p.solutions))
+	for idx, member := range p.solutions {
+		distance := AssignmentDistance(solution, member)
+		if distance == 0 || distance < p.minimumDistance {
+			if compareSolutions(member, solution) <= 0 {
+				return false
+			}
+			similar = append(similar, idx)
+		}
+	}
+
+	switch {
+	case len(similar) > 0:
+		for i := len(similar) - 1; i >= 0; i-- {
+			p.solutions = slices.Delete(p.solutions, similar[i], similar[i]+1)
+		}
+	case len(p.solutions) >= p.maximumSize:
+		p.solutions = p.solutions[:len(p.solutions)-1]
+	}
+
+	position, _ := slices.BinarySearchFunc(
+		p.solutions,
+		solution,
+		func(member Solution, solution Solution) int {
+			if compareSolutions(member, solution) <= 0 {
+				return -1
+			}
+			return 1
+		},
+	)
+	p.solutions = slices.Insert(p.solutions, position, solution.Copy())
+	if len(p.solutions) >= p.maximumSize {
+		worst := p.solutions[len(p.solutions)-1]
+		p.worst.Store(&worst)
+	}
+	return true
+}
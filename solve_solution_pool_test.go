@@ -0,0 +1,85 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nextmv-io/nextroute"
+)
+
+func TestSolutionPool(t *testing.T) {
+	input := singleVehiclePlanSingleStopsModel()
+	input.Vehicles = append(input.Vehicles, vehicles("truck", depot(), 1)...)
+	model, err := createModel(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = model.Objective().NewTerm(
+		1.0,
+		nextroute.NewUnPlannedObjective(
+			nextroute.NewStopExpression("unplanned", 1000.0),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	empty, err := nextroute.NewSolution(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	planned, err := nextroute.NewSavingsSolution(context.Background(), model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if distance := nextroute.AssignmentDistance(empty, empty.Copy()); distance != 0 {
+		t.Errorf("expected distance 0 between copies, got %v", distance)
+	}
+
+	if distance := nextroute.AssignmentDistance(empty, planned); distance != 1 {
+		t.Errorf(
+			"expected distance 1 between empty and planned solution, got %v",
+			distance,
+		)
+	}
+
+	pool := nextroute.NewSolutionPool(2, 0.5)
+
+	if !pool.Accepts(empty) {
+		t.Error("expected empty pool to accept any solution")
+	}
+	if !pool.Add(empty) {
+		t.Error("expected empty solution to be added to empty pool")
+	}
+	if pool.Add(empty.Copy()) {
+		t.Error("expected copy of empty solution to be rejected")
+	}
+	if !pool.Add(planned) {
+		t.Error("expected planned solution to be added to pool")
+	}
+
+	// The pool is full, the worst solution in the pool is the empty one.
+	if pool.Accepts(empty.Copy()) {
+		t.Error("expected full pool to reject a solution as good as its worst")
+	}
+	if !pool.Accepts(planned) {
+		t.Error("expected full pool to accept a solution better than its worst")
+	}
+
+	solutions := pool.Solutions()
+	if len(solutions) != 2 {
+		t.Fatalf("expected 2 solutions in pool, got %v", len(solutions))
+	}
+	if solutions[0].Score() > solutions[1].Score() {
+		t.Errorf(
+			"expected solutions ranked by score, got %v before %v",
+			solutions[0].Score(),
+			solutions[1].Score(),
+		)
+	}
+}
@@ -96,6 +96,7 @@ func (s *solveImpl) OnImprovement(solveInformation SolveInformation) {
 	s.progression = append(s.progression, ProgressionEntry{
 		ElapsedSeconds: time.Since(solveInformation.Start()).Seconds(),
 		Value:          solveInformation.Solver().BestSolution().Score(),
+		Terms:          termValues(solveInformation.Solver().BestSolution()),
 	})
 }
 
@@ -145,9 +146,9 @@ func (s *solveImpl) SolveOperators() SolveOperators {
 func (s *solveImpl) Reset(solution Solution, solveInformation SolveInformation) {
 	s.solveEvents.Reset.Trigger(solution, solveInformation)
 	s.workSolution = solution.Copy()
-	if s.workSolution.Score() < s.bestSolution.Score() {
+	if compareSolutions(s.workSolution, s.bestSolution) < 0 {
 		solveInfoImpl := solveInformation.(*solveInformationImpl)
-		solveInfoImpl.deltaScore = s.workSolution.Score() - s.bestSolution.Score()
+		solveInfoImpl.deltaScore = deltaScore(s.workSolution, s.bestSolution)
 		s.newBestSolution(s.workSolution, solveInfoImpl)
 	}
 }
@@ -196,10 +197,10 @@ func (s *solveImpl) invoke(
 		return false, nil
 	}
 
-	delta := s.workSolution.Score() - s.bestSolution.Score()
-	if delta >= 0.0 {
+	if compareSolutions(s.workSolution, s.bestSolution) >= 0 {
 		return false, nil
 	}
+	delta := deltaScore(s.workSolution, s.bestSolution)
 
 	solveInformation.deltaScore += delta
 
@@ -19,13 +19,22 @@ import (
 // Iterations is the key for the iterations performed.
 const Iterations string = "iterations"
 
+// poolSampleIterations is the number of iterations between the work solutions
+// of a solver offered to the solution pool.
+const poolSampleIterations = 100
+
 // ParallelSolveOptions holds the options for the parallel solver.
 type ParallelSolveOptions struct {
-	Iterations           int           `json:"iterations"  usage:"maximum number of iterations, -1 assumes no limit; iterations are counted after start solutions are generated" default:"-1"`
-	Duration             time.Duration `json:"duration" usage:"maximum duration of the solver" default:"5s"`
-	ParallelRuns         int           `json:"parallel_runs" usage:"maximum number of parallel runs, -1 results in using all available resources" default:"-1"`
-	StartSolutions       int           `json:"start_solutions" usage:"number of solutions to generate on top of those passed in; one solution generated with sweep algorithm, the rest generated randomly" default:"-1"`
-	RunDeterministically bool          `json:"run_deterministically"  usage:"run the parallel solver deterministically"`
+	Iterations           int                 `json:"iterations"  usage:"maximum number of iterations, -1 assumes no limit; iterations are counted after start solutions are generated" default:"-1"`
+	Duration             time.Duration       `json:"duration" usage:"maximum duration of the solver" default:"5s"`
+	ParallelRuns         int                 `json:"parallel_runs" usage:"maximum number of parallel runs, -1 results in using all available resources" default:"-1"`
+	StartSolutions       int                 `json:"start_solutions" usage:"number of solutions to generate on top of those passed in; one solution generated with the start solution strategy, the rest generated randomly" default:"-1"`
+	StartSolution        string              `json:"start_solution" usage:"strategy to generate the first start solution: random, sweep or savings" default:"random"`
+	RunDeterministically bool                `json:"run_deterministically"  usage:"run the parallel solver deterministically"`
+	Pool                 SolutionPoolOptions `json:"pool"`
+	Stop                 StopOptions         `json:"stop"`
+	Checkpoint           CheckpointOptions   `json:"checkpoint"`
+	Progress             ProgressOptions     `json:"progress"`
 }
 
 // ParallelSolver is the interface for parallel solver. The parallel solver will
@@ -48,6 +57,10 @@ type ParallelSolver interface {
 	SolveEvents() SolveEvents
 	// ParallelSolveEvents returns the solve-events used by the parallel solver.
 	ParallelSolveEvents() ParallelSolveEvents
+	// SolutionPool returns the pool of diverse solutions of the last solve,
+	// see [ParallelSolveOptions].Pool. Returns nil if Solve has not been
+	// invoked yet.
+	SolutionPool() SolutionPool
 }
 
 // SolveOptionsFactory is a factory type for creating new solve options.
@@ -136,6 +149,7 @@ type parallelSolverImpl struct {
 	parallelSolveEvents ParallelSolveEvents
 	solveOptionsFactory SolveOptionsFactory
 	solverFactory       SolverFactory
+	solutionPool        SolutionPool
 }
 
 func (s *parallelSolverImpl) ParallelSolveEvents() ParallelSolveEvents {
@@ -150,6 +164,10 @@ func (s *parallelSolverImpl) Model() Model {
 	return s.model
 }
 
+func (s *parallelSolverImpl) SolutionPool() SolutionPool {
+	return s.solutionPool
+}
+
 func (s *parallelSolverImpl) Progression() []ProgressionEntry {
 	return slices.Clone(s.progression)
 }
@@ -198,7 +216,12 @@ func (s *parallelSolverImpl) Solve(
 		Duration:             options.Duration,
 		ParallelRuns:         options.ParallelRuns,
 		StartSolutions:       options.StartSolutions,
+		StartSolution:        options.StartSolution,
 		RunDeterministically: options.RunDeterministically,
+		Pool:                 options.Pool,
+		Stop:                 options.Stop,
+		Checkpoint:           options.Checkpoint,
+		Progress:             options.Progress,
 	}
 
 	if interpretedParallelSolveOptions.ParallelRuns == -1 {
@@ -213,6 +236,33 @@ func (s *parallelSolverImpl) Solve(
 		interpretedParallelSolveOptions.Iterations = math.MaxInt
 	}
 
+	// The duration and iterations of a resumed solve count towards the
+	// limits of the solve.
+	var resumed Checkpoint
+	if interpretedParallelSolveOptions.Checkpoint.Resume != "" {
+		checkpoint, err := ReadCheckpoint(interpretedParallelSolveOptions.Checkpoint.Resume)
+		if err != nil {
+			return nil, err
+		}
+		solution, err := checkpoint.Solution(s.model)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"resuming from checkpoint %v: %w",
+				interpretedParallelSolveOptions.Checkpoint.Resume,
+				err,
+			)
+		}
+		startSolutions = append(startSolutions, solution)
+		resumed = checkpoint
+		interpretedParallelSolveOptions.Duration -= checkpoint.Elapsed()
+		if interpretedParallelSolveOptions.Iterations != math.MaxInt {
+			interpretedParallelSolveOptions.Iterations = max(
+				interpretedParallelSolveOptions.Iterations-checkpoint.Iterations,
+				0,
+			)
+		}
+	}
+
 	if len(startSolutions) == 0 {
 		solution, err := NewSolution(s.model)
 		if err != nil {
@@ -259,20 +309,68 @@ func (s *parallelSolverImpl) Solve(
 	bestSolution := solutions[0]
 
 	for _, solution := range solutions {
-		if solution.Score() < bestSolution.Score() {
+		if compareSolutions(solution, bestSolution) < 0 {
 			bestSolution = solution
 		}
 	}
 
 	bestSolution = bestSolution.Copy()
 
+	s.solutionPool = NewSolutionPool(
+		interpretedParallelSolveOptions.Pool.Size,
+		interpretedParallelSolveOptions.Pool.MinimumDistance,
+	)
+	s.solutionPool.Add(bestSolution)
+
+	totalIterations := atomic.Int64{}
+
+	checkpointWriter := newCheckpointWriter(
+		interpretedParallelSolveOptions.Checkpoint,
+		resumed,
+		start,
+		func() int {
+			return int(totalIterations.Load())
+		},
+	)
+	checkpointWriter.update(bestSolution)
+	if err := checkpointWriter.flush(); err != nil {
+		cancel()
+		return nil, err
+	}
+	checkpointWriter.run(ctx)
+
+	progressWriter, err := newProgressWriter(
+		interpretedParallelSolveOptions.Progress,
+		start,
+		func() int {
+			return int(totalIterations.Load())
+		},
+	)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	progressWriter.improved(bestSolution, nil, nil)
+
+	// A resumed solve that exhausted its iterations does not iterate.
+	if interpretedParallelSolveOptions.Checkpoint.Resume != "" &&
+		interpretedParallelSolveOptions.Iterations == 0 {
+		cancel()
+	}
+
+	stopMonitor := newStopMonitor(
+		interpretedParallelSolveOptions.Stop,
+		bestSolution.Score(),
+	)
+	if stopMonitor.targetReached(bestSolution.Score()) {
+		cancel()
+	}
+
 	parallelCount := make(chan struct{}, parallelRuns)
 
 	syncResultChannel := make(chan solutionContainer)
 	resultChannel := make(chan SolutionInfo, 1)
 
-	totalIterations := atomic.Int64{}
-
 	reportBestSolution := func(solutionContainer solutionContainer) {
 		resultChannel <- SolutionInfo{
 			Solution: solutionContainer.Solution,
@@ -283,6 +381,7 @@ func (s *parallelSolverImpl) Solve(
 				ElapsedSeconds: time.Since(start).Seconds(),
 				Value:          solutionContainer.Solution.Score(),
 				Iterations:     solutionContainer.Iterations,
+				Terms:          termValues(solutionContainer.Solution),
 			})
 		}
 	}
@@ -347,12 +446,64 @@ func (s *parallelSolverImpl) Solve(
 
 						s.RegisterEvents(solver.SolveEvents())
 
+						// The first cycle of a resumed solve continues
+						// with the parameter values of the checkpoint.
+						if cycle == 1 {
+							resumed.RestoreOperators(solver.SolveOperators())
+						}
+
 						solver.SolveEvents().Iterated.Register(func(_ SolveInformation) {
 							if totalIterations.Add(1) >= int64(interpretedParallelSolveOptions.Iterations) {
 								cancel()
 							}
 						})
 
+						if checkpointWriter.enabled() {
+							solver.SolveEvents().NewBestSolution.Register(func(info SolveInformation) {
+								checkpointWriter.setOperators(info.Solver().SolveOperators())
+							})
+						}
+
+						if progressWriter.enabled() {
+							solver.SolveEvents().NewBestSolution.Register(func(info SolveInformation) {
+								progressWriter.improved(
+									info.Solver().BestSolution(),
+									metaSolveInformation,
+									info.SolveOperators(),
+								)
+							})
+						}
+
+						if stopMonitor.enabled() {
+							solver.SolveEvents().Iterated.Register(func(_ SolveInformation) {
+								if stopMonitor.iterated() {
+									cancel()
+								}
+							})
+						}
+
+						// The work solutions of the solver are candidates
+						// for the pool as they can be diverse from the
+						// improving solutions reported by the solver. They
+						// are sampled, and the work solution is offered
+						// before it is reset, to not serialize the solvers
+						// on the pool.
+						if s.solutionPool.MaximumSize() > 1 {
+							offer := func(solution Solution) {
+								if s.solutionPool.Accepts(solution) {
+									s.solutionPool.Add(solution)
+								}
+							}
+							solver.SolveEvents().Iterated.Register(func(info SolveInformation) {
+								if info.Iteration()%poolSampleIterations == 0 {
+									offer(info.Solver().WorkSolution())
+								}
+							})
+							solver.SolveEvents().Reset.Register(func(_ Solution, info SolveInformation) {
+								offer(info.Solver().WorkSolution())
+							})
+						}
+
 						opt, err := s.solveOptionsFactory(
 							metaSolveInformation,
 						)
@@ -414,6 +565,18 @@ func (s *parallelSolverImpl) Solve(
 				converted := iterations
 				dataMap.Store(Iterations, converted)
 			}
+			if err := checkpointWriter.close(); err != nil {
+				resultChannel <- SolutionInfo{
+					Solution: nil,
+					Error:    err,
+				}
+			}
+			if err := progressWriter.close(); err != nil {
+				resultChannel <- SolutionInfo{
+					Solution: nil,
+					Error:    err,
+				}
+			}
 			close(resultChannel)
 
 			s.ParallelSolveEvents().End.Trigger(s, iterations, bestSolution)
@@ -429,11 +592,18 @@ func (s *parallelSolverImpl) Solve(
 				continue
 			}
 
-			if solverResult.Solution.Score() >= bestSolution.Score() {
+			s.solutionPool.Add(solverResult.Solution)
+
+			if compareSolutions(solverResult.Solution, bestSolution) >= 0 {
 				continue
 			}
 
 			bestSolution = solverResult.Solution.Copy()
+			checkpointWriter.update(bestSolution)
+
+			if stopMonitor.improved(bestSolution.Score()) {
+				cancel()
+			}
 
 			reportBestSolution(solutionContainer{
 				Solution:   solverResult.Solution.Copy(),
@@ -0,0 +1,104 @@
+// © 2019-present nextmv.io inc
+
+package nextroute
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// StopOptions configure criteria to stop the parallel solver before the
+// maximum number of iterations or the maximum duration is reached. All
+// criteria are disabled by default. The solver stops as soon as one of the
+// enabled criteria is met.
+type StopOptions struct {
+	NoImprovementIterations int           `json:"no_improvement_iterations" usage:"stop if the best solution has not improved for the given number of iterations, 0 disables the criterion" default:"0" minimum:"0"`
+	NoImprovementDuration   time.Duration `json:"no_improvement_duration" usage:"stop if the best solution has not improved for the given duration, 0 disables the criterion" default:"0s"`
+	TargetValue             float64       `json:"target_value" usage:"stop when the score of the best solution is less than or equal to the target value, requires target_value_enabled" default:"0"`
+	TargetValueEnabled      bool          `json:"target_value_enabled" usage:"enable the target value criterion"`
+	Window                  int           `json:"window" usage:"number of iterations in the sliding window of the relative improvement criterion, 0 disables the criterion" default:"0" minimum:"0"`
+	Epsilon                 float64       `json:"epsilon" usage:"stop if the relative improvement of the best score over the sliding window is less than epsilon" default:"0" minimum:"0"`
+}
+
+// stopMonitor tracks the progress of the parallel solver and decides if one
+// of the criteria of the stop options is met. The score of a solution is its
+// Solution.Score, also if the objective is lexicographic.
+type stopMonitor struct {
+	lastImprovement          time.Time
+	window                   []float64
+	options                  StopOptions
+	bestScore                float64
+	iterations               int
+	lastImprovementIteration int
+	mutex                    sync.Mutex
+}
+
+func newStopMonitor(options StopOptions, bestScore float64) *stopMonitor {
+	monitor := &stopMonitor{
+		options:         options,
+		bestScore:       bestScore,
+		lastImprovement: time.Now(),
+	}
+	if options.Window > 0 && options.Epsilon > 0 {
+		monitor.window = make([]float64, options.Window+1)
+	}
+	return monitor
+}
+
+// enabled returns true if at least one criterion depends on the iterations.
+func (m *stopMonitor) enabled() bool {
+	return m.options.NoImprovementIterations > 0 ||
+		m.options.NoImprovementDuration > 0 ||
+		m.window != nil
+}
+
+// targetReached returns true if the target value criterion is enabled and the
+// score is less than or equal to the target value.
+func (m *stopMonitor) targetReached(score float64) bool {
+	return m.options.TargetValueEnabled && score <= m.options.TargetValue
+}
+
+// improved registers a new best score. Returns true if the solver should
+// stop.
+func (m *stopMonitor) improved(score float64) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.bestScore = score
+	m.lastImprovement = time.Now()
+	m.lastImprovementIteration = m.iterations
+	return m.targetReached(score)
+}
+
+// iterated registers an iteration of any of the solvers. Returns true if the
+// solver should stop.
+func (m *stopMonitor) iterated() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.iterations++
+
+	if m.options.NoImprovementIterations > 0 &&
+		m.iterations-m.lastImprovementIteration >= m.options.NoImprovementIterations {
+		return true
+	}
+
+	if m.options.NoImprovementDuration > 0 &&
+		time.Since(m.lastImprovement) >= m.options.NoImprovementDuration {
+		return true
+	}
+
+	if m.window != nil {
+		size := len(m.window)
+		m.window[m.iterations%size] = m.bestScore
+		if m.iterations >= size {
+			previous := m.window[(m.iterations+1)%size]
+			improvement := (previous - m.bestScore) /
+				math.Max(math.Abs(previous), math.SmallestNonzeroFloat64)
+			if improvement < m.options.Epsilon {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+// © 2019-present nextmv.io inc
+
+package nextroute_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nextmv-io/nextroute"
+	"github.com/nextmv-io/sdk/run"
+)
+
+func TestStopOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		stop nextroute.StopOptions
+	}{
+		{
+			name: "no improvement iterations",
+			stop: nextroute.StopOptions{NoImprovementIterations: 100},
+		},
+		{
+			name: "no improvement duration",
+			stop: nextroute.StopOptions{NoImprovementDuration: 100 * time.Millisecond},
+		},
+		{
+			name: "target value",
+			stop: nextroute.StopOptions{TargetValueEnabled: true, TargetValue: 1_000_000},
+		},
+		{
+			name: "relative improvement",
+			stop: nextroute.StopOptions{Window: 100, Epsilon: 0.01},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model, err := createModel(singleVehiclePlanSingleStopsModel())
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = model.Objective().NewTerm(
+				1.0,
+				nextroute.NewUnPlannedObjective(
+					nextroute.NewStopExpression("unplanned", 1000.0),
+				),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			solver, err := nextroute.NewParallelSolver(model)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.WithValue(context.Background(), run.Start, time.Now())
+			solutions, err := solver.Solve(ctx, nextroute.ParallelSolveOptions{
+				Iterations:     -1,
+				Duration:       time.Minute,
+				ParallelRuns:   1,
+				StartSolutions: 1,
+				Stop:           test.stop,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			last, err := solutions.Last()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if last == nil {
+				t.Fatal("expected a solution")
+			}
+			if elapsed := time.Since(start); elapsed > 30*time.Second {
+				t.Errorf("expected solver to stop early, ran for %v", elapsed)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package nextroute
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"runtime"
 	"sync"
@@ -65,6 +66,10 @@ func (p *parallelSolverWrapperImpl) SolveEvents() SolveEvents {
 	return p.solver.SolveEvents()
 }
 
+func (p *parallelSolverWrapperImpl) SolutionPool() SolutionPool {
+	return p.solver.SolutionPool()
+}
+
 func (p *parallelSolverWrapperImpl) Progression() []ProgressionEntry {
 	return p.solver.Progression()
 }
@@ -85,7 +90,12 @@ func (p *parallelSolverWrapperImpl) Solve(
 		Duration:             solveOptions.Duration,
 		ParallelRuns:         solveOptions.ParallelRuns,
 		StartSolutions:       solveOptions.StartSolutions,
+		StartSolution:        solveOptions.StartSolution,
 		RunDeterministically: solveOptions.RunDeterministically,
+		Pool:                 solveOptions.Pool,
+		Stop:                 solveOptions.Stop,
+		Checkpoint:           solveOptions.Checkpoint,
+		Progress:             solveOptions.Progress,
 	}
 
 	if interpretedParallelSolveOptions.ParallelRuns == -1 {
@@ -104,6 +114,13 @@ func (p *parallelSolverWrapperImpl) Solve(
 		interpretedParallelSolveOptions.StartSolutions = runtime.NumCPU()
 	}
 
+	startSolutionConstruction, err := newStartSolutionConstruction(
+		interpretedParallelSolveOptions.StartSolution,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	initialSolutions := make(Solutions, interpretedParallelSolveOptions.StartSolutions)
 	if interpretedParallelSolveOptions.StartSolutions > 0 {
 		var wg sync.WaitGroup
@@ -112,17 +129,23 @@ func (p *parallelSolverWrapperImpl) Solve(
 		if err != nil {
 			return nil, err
 		}
+		errs := make([]error, interpretedParallelSolveOptions.StartSolutions)
 		for idx := 0; idx < interpretedParallelSolveOptions.StartSolutions; idx++ {
+			construction := RandomSolutionConstruction
+			if idx == 0 {
+				construction = startSolutionConstruction
+			}
 			go func(idx int, sol Solution) {
 				defer wg.Done()
-				randomSolution, err := RandomSolutionConstruction(ctx, sol)
-				if err != nil {
-					panic(err)
-				}
-				initialSolutions[idx] = randomSolution
+				initialSolutions[idx], errs[idx] = construction(ctx, sol)
 			}(idx, solution.Copy())
 		}
 		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
 		startSolutions = append(startSolutions, initialSolutions...)
 	}
 
@@ -140,3 +163,38 @@ func (p *parallelSolverWrapperImpl) Solve(
 		startSolutions...,
 	)
 }
+
+// Start solution strategies supported by [ParallelSolveOptions].StartSolution.
+const (
+	// StartSolutionRandom constructs a start solution using
+	// [RandomSolutionConstruction].
+	StartSolutionRandom = "random"
+	// StartSolutionSweep constructs a start solution using
+	// [SweepSolutionConstruction].
+	StartSolutionSweep = "sweep"
+	// StartSolutionSavings constructs a start solution using
+	// [SavingsSolutionConstruction].
+	StartSolutionSavings = "savings"
+)
+
+// newStartSolutionConstruction returns the construction heuristic for the
+// given start solution strategy. An empty strategy defaults to random.
+func newStartSolutionConstruction(
+	strategy string,
+) (func(context.Context, Solution) (Solution, error), error) {
+	switch strategy {
+	case "", StartSolutionRandom:
+		return RandomSolutionConstruction, nil
+	case StartSolutionSweep:
+		return SweepSolutionConstruction, nil
+	case StartSolutionSavings:
+		return SavingsSolutionConstruction, nil
+	}
+	return nil, fmt.Errorf(
+		"unknown start solution strategy %q, expected one of %q, %q or %q",
+		strategy,
+		StartSolutionRandom,
+		StartSolutionSweep,
+		StartSolutionSavings,
+	)
+}